@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/jordiprats/kubectl-eks/pkg/eks"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// clusterConfigs builds rest configs for clusters without switching the
+// current context of the user's kubeconfig. Every cluster gets a kubeconfig
+// file of its own in a private directory, written once by
+// "aws eks update-kubeconfig"; the exec credential plugin in it keeps the
+// tokens fresh.
+type clusterConfigs struct {
+	dir string
+
+	mu      sync.Mutex
+	configs map[string]*rest.Config
+}
+
+func newClusterConfigs() (*clusterConfigs, error) {
+	dir, err := os.MkdirTemp("", "kubectl-eks-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubeconfig directory: %w", err)
+	}
+	return &clusterConfigs{dir: dir, configs: make(map[string]*rest.Config)}, nil
+}
+
+// restConfig returns the rest config of a cluster. It is safe to call
+// concurrently for different clusters.
+func (c *clusterConfigs) restConfig(clusterInfo data.ClusterInfo) (*rest.Config, error) {
+	key := clusterInfo.AWSProfile + "/" + clusterInfo.Region + "/" + clusterInfo.ClusterName
+
+	c.mu.Lock()
+	restConfig, ok := c.configs[key]
+	c.mu.Unlock()
+	if ok {
+		return restConfig, nil
+	}
+
+	path := c.kubeConfigPath(clusterInfo)
+	err := eks.UpdateKubeConfig(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update kubeconfig: %w", err)
+	}
+
+	// The file only has this cluster and update-kubeconfig made it the
+	// current context
+	restConfig, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: path},
+		&clientcmd.ConfigOverrides{},
+	).ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.configs[key] = restConfig
+	c.mu.Unlock()
+
+	return restConfig, nil
+}
+
+func (c *clusterConfigs) kubeConfigPath(clusterInfo data.ClusterInfo) string {
+	name := strings.Join([]string{clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName}, "_")
+	return filepath.Join(c.dir, strings.ReplaceAll(name, string(filepath.Separator), "_")+".kubeconfig")
+}
+
+// cleanup removes the private kubeconfig files
+func (c *clusterConfigs) cleanup() {
+	os.RemoveAll(c.dir)
+}
+
+// forEachCluster calls fn for every cluster from up to parallelism
+// goroutines and waits for all of them. fn gets the index of the cluster, so
+// it can store its result without locking.
+func forEachCluster(clusterList []data.ClusterInfo, parallelism int, fn func(i int, clusterInfo data.ClusterInfo)) {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(parallelism, len(clusterList)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i, clusterList[i])
+			}
+		}()
+	}
+
+	for i := range clusterList {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
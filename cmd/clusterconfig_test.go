@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForEachCluster(t *testing.T) {
+	clusterList := []data.ClusterInfo{}
+	for i := 0; i < 10; i++ {
+		clusterList = append(clusterList, data.ClusterInfo{ClusterName: fmt.Sprintf("cluster-%d", i)})
+	}
+
+	var running, maxRunning int32
+	var mu sync.Mutex
+	seen := make([]string, len(clusterList))
+	forEachCluster(clusterList, 3, func(i int, clusterInfo data.ClusterInfo) {
+		current := atomic.AddInt32(&running, 1)
+		mu.Lock()
+		maxRunning = max(maxRunning, current)
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+		seen[i] = clusterInfo.ClusterName
+		atomic.AddInt32(&running, -1)
+	})
+
+	for i, clusterInfo := range clusterList {
+		assert.Equal(t, clusterInfo.ClusterName, seen[i])
+	}
+	assert.LessOrEqual(t, maxRunning, int32(3))
+	assert.Greater(t, maxRunning, int32(1), "clusters are collected concurrently")
+
+	forEachCluster(nil, 3, func(i int, clusterInfo data.ClusterInfo) {
+		t.Fatalf("called for an empty cluster list")
+	})
+}
+
+func TestClusterConfigs_KubeConfigPath(t *testing.T) {
+	configs := &clusterConfigs{dir: t.TempDir()}
+
+	a := configs.kubeConfigPath(data.ClusterInfo{AWSProfile: "prod", Region: "us-east-1", ClusterName: "main"})
+	b := configs.kubeConfigPath(data.ClusterInfo{AWSProfile: "prod", Region: "eu-west-1", ClusterName: "main"})
+
+	assert.NotEqual(t, a, b, "clusters with the same name get different files")
+	assert.Equal(t, configs.dir, filepath.Dir(a))
+	assert.Equal(t, "prod_us-east-1_main.kubeconfig", filepath.Base(a))
+}
@@ -59,82 +59,91 @@ func LoadClusterList(args []string, profile, profile_contains, name_contains, na
 
 		clusterList = append(clusterList, *clusterInfo)
 
-	} else {
-		loadCacheFromDisk()
-		if CachedData == nil {
-			CachedData = &data.KubeCtlEksCache{
-				ClusterByARN: make(map[string]data.ClusterInfo),
-				ClusterList:  make(map[string]map[string][]data.ClusterInfo),
-			}
-		}
+		return clusterList, nil
+	}
+
+	return loadCachedClusterList(profile, profile_contains, name_contains, name_not_contains, region, version, doRefresh)
+}
+
+// loadCachedClusterList returns the clusters of every configured AWS profile
+// and region that match the filters. Empty filters match every cluster.
+func loadCachedClusterList(profile, profile_contains, name_contains, name_not_contains, region, version string, doRefresh bool) ([]data.ClusterInfo, error) {
+	clusterList := []data.ClusterInfo{}
 
-		if doRefresh {
-			CachedData.ClusterList = make(map[string]map[string][]data.ClusterInfo)
+	loadCacheFromDisk()
+	if CachedData == nil {
+		CachedData = &data.KubeCtlEksCache{
+			ClusterByARN: make(map[string]data.ClusterInfo),
+			ClusterList:  make(map[string]map[string][]data.ClusterInfo),
 		}
+	}
 
-		// Keep default output quiet; only show per-profile load failures in verbose mode.
-		showLoadFailure := verbose
+	if doRefresh {
+		CachedData.ClusterList = make(map[string]map[string][]data.ClusterInfo)
+	}
 
-		awsProfiles := awsconfig.GetAWSProfilesWithEKSHints()
-		for _, profileDetails := range awsProfiles {
-			if profile != "" && profile != profileDetails.Name {
-				continue
-			}
-			if profile_contains != "" && !strings.Contains(profileDetails.Name, profile_contains) {
+	// Keep default output quiet; only show per-profile load failures in verbose mode.
+	showLoadFailure := verbose
+
+	awsProfiles := awsconfig.GetAWSProfilesWithEKSHints()
+	for _, profileDetails := range awsProfiles {
+		if profile != "" && profile != profileDetails.Name {
+			continue
+		}
+		if profile_contains != "" && !strings.Contains(profileDetails.Name, profile_contains) {
+			continue
+		}
+		for _, hintRegion := range profileDetails.HintEKSRegions {
+			if region != "" && region != hintRegion {
 				continue
 			}
-			for _, hintRegion := range profileDetails.HintEKSRegions {
-				if region != "" && region != hintRegion {
-					continue
-				}
 
-				cachedRegions, exists := CachedData.ClusterList[profileDetails.Name]
+			cachedRegions, exists := CachedData.ClusterList[profileDetails.Name]
+			if !exists {
+				loadClusters(profileDetails.Name, hintRegion)
+			} else {
+				_, exists := cachedRegions[hintRegion]
 				if !exists {
 					loadClusters(profileDetails.Name, hintRegion)
-				} else {
-					_, exists := cachedRegions[hintRegion]
-					if !exists {
-						loadClusters(profileDetails.Name, hintRegion)
-					}
 				}
+			}
 
-				currentClusterList, exists := CachedData.ClusterList[profileDetails.Name][hintRegion]
-				if !exists {
-					if showLoadFailure {
-						fmt.Fprintf(os.Stderr, "Unable to load clusters using profile: %s region: %s (LoadClusterList)\n", profileDetails.Name, hintRegion)
-					}
+			currentClusterList, exists := CachedData.ClusterList[profileDetails.Name][hintRegion]
+			if !exists {
+				if showLoadFailure {
+					fmt.Fprintf(os.Stderr, "Unable to load clusters using profile: %s region: %s (LoadClusterList)\n", profileDetails.Name, hintRegion)
+				}
+			} else {
+				if version == "" && name_contains == "" && name_not_contains == "" {
+					clusterList = append(clusterList, currentClusterList...)
 				} else {
-					if version == "" && name_contains == "" && name_not_contains == "" {
-						clusterList = append(clusterList, currentClusterList...)
-					} else {
-						for _, cluster := range currentClusterList {
-							// checking filter criteria
-							shouldAdd := true
-
-							// Check version filter
-							if version != "" && cluster.Version != version {
-								shouldAdd = false
-							}
-
-							// Check name_contains filter
-							if name_contains != "" && !strings.Contains(cluster.ClusterName, name_contains) {
-								shouldAdd = false
-							}
-
-							// Check name_not_contains filter
-							if name_not_contains != "" && strings.Contains(cluster.ClusterName, name_not_contains) {
-								shouldAdd = false
-							}
-
-							// only add the cluster if it meets the criteria
-							if shouldAdd {
-								clusterList = append(clusterList, cluster)
-							}
+					for _, cluster := range currentClusterList {
+						// checking filter criteria
+						shouldAdd := true
+
+						// Check version filter
+						if version != "" && cluster.Version != version {
+							shouldAdd = false
+						}
+
+						// Check name_contains filter
+						if name_contains != "" && !strings.Contains(cluster.ClusterName, name_contains) {
+							shouldAdd = false
+						}
+
+						// Check name_not_contains filter
+						if name_not_contains != "" && strings.Contains(cluster.ClusterName, name_not_contains) {
+							shouldAdd = false
+						}
+
+						// only add the cluster if it meets the criteria
+						if shouldAdd {
+							clusterList = append(clusterList, cluster)
 						}
 					}
 				}
-
 			}
+
 		}
 	}
 
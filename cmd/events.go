@@ -4,11 +4,14 @@ import (
 	"context"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/jordiprats/kubectl-eks/pkg/data"
 	"github.com/jordiprats/kubectl-eks/pkg/k8s"
 	"github.com/jordiprats/kubectl-eks/pkg/printutils"
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 var eventsCmd = &cobra.Command{
@@ -19,8 +22,17 @@ var eventsCmd = &cobra.Command{
 Events provide insights into cluster activities such as pod scheduling,
 image pulls, volume mounts, configuration changes, and errors.
 
-By default shows all event types (Normal and Warning). Use --warnings-only
-to filter for just Warning events. Events are sorted with most recent first.`,
+By default shows all event types (Normal and Warning) of the current cluster.
+Use the cluster filters (--profile, --name-contains, --region...) to collect
+the events of several clusters at once. Events are sorted with most recent
+first.
+
+Events are read through the events.k8s.io/v1 API (core/v1 on clusters that
+do not serve it). Repeated occurrences of the same event, split across
+several event objects or series, are merged into a single row whose COUNT
+adds them up.
+
+Use --watch to stream new and updated events until interrupted.`,
 	Example: `  # Show all events across all namespaces
   kubectl eks events
 
@@ -30,86 +42,93 @@ to filter for just Warning events. Events are sorted with most recent first.`,
   # Show events for specific namespace
   kubectl eks events -n kube-system
 
-  # Show all events (explicit)
-  kubectl eks events --all`,
+  # Warning events of the last 30 minutes in every production cluster
+  kubectl eks events --warnings-only --since 30m --name-contains prod
+
+  # Stream scheduling failures and back-offs of pods
+  kubectl eks events --watch --reason FailedScheduling,BackOff --involved-kind Pod`,
 	Run: func(cmd *cobra.Command, args []string) {
-		clusterInfo, err := GetCurrentClusterInfo()
-		if err != nil {
-			log.Fatalf("Error getting current cluster info: %v", err)
-		}
+		refresh, _ := cmd.Flags().GetBool("refresh")
+		profile, _ := cmd.Flags().GetString("profile")
+		profileContains, _ := cmd.Flags().GetString("profile-contains")
+		nameContains, _ := cmd.Flags().GetString("name-contains")
+		nameNotContains, _ := cmd.Flags().GetString("name-not-contains")
+		region, _ := cmd.Flags().GetString("region")
+		version, _ := cmd.Flags().GetString("version")
 
 		namespace, _ := cmd.Flags().GetString("namespace")
 		allNamespaces, _ := cmd.Flags().GetBool("all-namespaces")
 		warningsOnly, _ := cmd.Flags().GetBool("warnings-only")
-		allEvents, _ := cmd.Flags().GetBool("all")
+		since, _ := cmd.Flags().GetDuration("since")
+		reasons, _ := cmd.Flags().GetStringSlice("reason")
+		involvedKinds, _ := cmd.Flags().GetStringSlice("involved-kind")
+		watchMode, _ := cmd.Flags().GetBool("watch")
 		noHeaders, _ := cmd.Flags().GetBool("no-headers")
 
 		// Default to all namespaces unless specific namespace is provided
-		if !allNamespaces && namespace == "" {
-			allNamespaces = true
-		}
-
 		if allNamespaces {
 			namespace = ""
 		}
 
-		// Show all events by default (both Normal and Warning)
-		if !warningsOnly && !allEvents {
-			allEvents = true
+		if since < 0 {
+			log.Fatalf("--since must not be negative")
 		}
 
-		// If --warnings-only is explicitly set, only show warnings
-		if warningsOnly {
-			allEvents = false
+		filter := eventFilter{
+			warningsOnly:  warningsOnly,
+			since:         since,
+			reasons:       reasons,
+			involvedKinds: involvedKinds,
 		}
 
-		events, err := k8s.GetEvents(context.Background(), namespace)
+		clusterList, err := LoadClusterList([]string{}, profile, profileContains, nameContains, nameNotContains, region, version, refresh)
 		if err != nil {
-			log.Fatalf("Error getting events: %v", err)
+			log.Fatalf("Error loading cluster list: %v", err)
 		}
 
-		if len(events) == 0 {
-			if namespace == "" {
-				log.Println("No events found in any namespace")
-			} else {
-				log.Printf("No events found in namespace: %s\n", namespace)
-			}
+		if watchMode {
+			runEventsWatch(clusterList, namespace, filter, noHeaders)
+			saveCacheToDisk()
 			return
 		}
 
-		eventInfos := make([]data.EventInfo, 0)
-		for _, event := range events {
-			// Filter by type if warnings-only is set
-			if warningsOnly && !strings.EqualFold(event.Type, "Warning") {
+		loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+		config, err := loadingRules.Load()
+		if err != nil {
+			log.Fatalf("Error loading kubeconfig: %v", err)
+		}
+		previousContext := config.CurrentContext
+
+		now := time.Now()
+		eventInfos := []data.EventInfo{}
+		for _, clusterInfo := range clusterList {
+			events, err := collectClusterEvents(clusterInfo, namespace)
+			if err != nil {
+				log.Printf("Warning: Unable to get events for cluster %s: %v", clusterInfo.ClusterName, err)
 				continue
 			}
+			eventInfos = append(eventInfos, filter.apply(events, now)...)
+		}
 
-			// Use EventTime if LastTimestamp is not set
-			lastSeen := event.LastTimestamp.Time
-			if lastSeen.IsZero() && !event.EventTime.Time.IsZero() {
-				lastSeen = event.EventTime.Time
-			}
-
-			info := data.EventInfo{
-				Profile:     clusterInfo.AWSProfile,
-				Region:      clusterInfo.Region,
-				ClusterName: clusterInfo.ClusterName,
-				Namespace:   event.Namespace,
-				LastSeen:    lastSeen,
-				Type:        event.Type,
-				Reason:      event.Reason,
-				Object:      event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name,
-				Message:     event.Message,
-				Count:       event.Count,
-			}
-			eventInfos = append(eventInfos, info)
+		// Restore the previous context
+		loadingRules = clientcmd.NewDefaultClientConfigLoadingRules()
+		config, err = loadingRules.Load()
+		if err != nil {
+			log.Fatalf("Error loading kubeconfig: %v", err)
 		}
+		config.CurrentContext = previousContext
+		if err := clientcmd.ModifyConfig(loadingRules, *config, true); err != nil {
+			log.Fatalf("Error updating kubeconfig: %v", err)
+		}
+
+		saveCacheToDisk()
 
+		eventInfos = k8s.DedupeEvents(eventInfos)
 		if len(eventInfos) == 0 {
-			if warningsOnly {
-				log.Println("No warning events found. Try --all to see all event types.")
-			} else {
+			if namespace == "" {
 				log.Println("No events match the specified criteria")
+			} else {
+				log.Printf("No events match the specified criteria in namespace: %s\n", namespace)
 			}
 			return
 		}
@@ -118,10 +137,96 @@ to filter for just Warning events. Events are sorted with most recent first.`,
 	},
 }
 
+// eventFilter selects the events to show
+type eventFilter struct {
+	warningsOnly  bool
+	since         time.Duration
+	reasons       []string
+	involvedKinds []string
+}
+
+// matches reports whether the event passes the filter. Reasons and kinds are
+// compared case-insensitively; since is measured from the last occurrence.
+func (f eventFilter) matches(event data.EventInfo, now time.Time) bool {
+	if f.warningsOnly && !strings.EqualFold(event.Type, "Warning") {
+		return false
+	}
+	if f.since > 0 && event.LastSeen.Before(now.Add(-f.since)) {
+		return false
+	}
+	if len(f.reasons) > 0 && !containsFold(f.reasons, event.Reason) {
+		return false
+	}
+	if len(f.involvedKinds) > 0 && !containsFold(f.involvedKinds, event.InvolvedKind) {
+		return false
+	}
+	return true
+}
+
+func (f eventFilter) apply(events []data.EventInfo, now time.Time) []data.EventInfo {
+	filtered := []data.EventInfo{}
+	for _, event := range events {
+		if f.matches(event, now) {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectClusterEvents switches the kubeconfig to the cluster and lists its
+// events, tagged with the cluster they come from
+func collectClusterEvents(clusterInfo data.ClusterInfo, namespace string) ([]data.EventInfo, error) {
+	restConfig, err := clusterRestConfig(clusterInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	events, inaccessible, err := k8s.ListEvents(context.Background(), clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if len(inaccessible) > 0 {
+		log.Printf("Warning: Unable to list events of cluster %s in namespaces: %s", clusterInfo.ClusterName, strings.Join(inaccessible, ", "))
+	}
+
+	for i := range events {
+		events[i].Profile = clusterInfo.AWSProfile
+		events[i].Region = clusterInfo.Region
+		events[i].ClusterName = clusterInfo.ClusterName
+	}
+	return events, nil
+}
+
 func init() {
+	eventsCmd.Flags().BoolP("refresh", "u", false, "Do not use cached data, refresh from AWS")
+	eventsCmd.Flags().StringP("profile", "p", "", "AWS profile to use")
+	eventsCmd.Flags().StringP("profile-contains", "q", "", "AWS profile contains string")
+	eventsCmd.Flags().StringP("name-contains", "c", "", "Cluster name contains string")
+	eventsCmd.Flags().StringP("name-not-contains", "x", "", "Cluster name does not contain string")
+	eventsCmd.Flags().StringP("region", "r", "", "AWS region to use")
+	eventsCmd.Flags().StringP("version", "v", "", "Filter by EKS version")
 	eventsCmd.Flags().StringP("namespace", "n", "", "Namespace to show events for")
 	eventsCmd.Flags().BoolP("all-namespaces", "A", false, "Show events across all namespaces (default)")
 	eventsCmd.Flags().Bool("warnings-only", false, "Show only warning events")
 	eventsCmd.Flags().Bool("all", false, "Show all events (default behavior)")
+	eventsCmd.Flags().MarkDeprecated("all", "all events are shown by default, the flag has no effect")
+	eventsCmd.Flags().Duration("since", 0, "Only show events seen within this duration, e.g. 30m (default: no limit)")
+	eventsCmd.Flags().StringSlice("reason", []string{}, "Only show events with these reasons, e.g. BackOff,FailedScheduling")
+	eventsCmd.Flags().StringSlice("involved-kind", []string{}, "Only show events about these kinds of objects, e.g. Pod,Node")
+	eventsCmd.Flags().Bool("watch", false, "Stream new and updated events until interrupted")
 	rootCmd.AddCommand(eventsCmd)
 }
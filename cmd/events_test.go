@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventFilter(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	events := []data.EventInfo{
+		{Type: "Warning", Reason: "BackOff", InvolvedKind: "Pod", LastSeen: now.Add(-5 * time.Minute)},
+		{Type: "Warning", Reason: "FailedScheduling", InvolvedKind: "Pod", LastSeen: now.Add(-2 * time.Hour)},
+		{Type: "Normal", Reason: "Scheduled", InvolvedKind: "Pod", LastSeen: now},
+		{Type: "Warning", Reason: "NodeNotReady", InvolvedKind: "Node", LastSeen: now},
+	}
+
+	reasons := func(events []data.EventInfo) []string {
+		out := []string{}
+		for _, e := range events {
+			out = append(out, e.Reason)
+		}
+		return out
+	}
+
+	assert.Len(t, eventFilter{}.apply(events, now), 4)
+	assert.Equal(t, []string{"BackOff", "FailedScheduling", "NodeNotReady"}, reasons(eventFilter{warningsOnly: true}.apply(events, now)))
+	assert.Equal(t, []string{"BackOff", "Scheduled", "NodeNotReady"}, reasons(eventFilter{since: 30 * time.Minute}.apply(events, now)))
+	assert.Equal(t, []string{"BackOff", "FailedScheduling"}, reasons(eventFilter{reasons: []string{"backoff", "FailedScheduling"}}.apply(events, now)))
+	assert.Equal(t, []string{"NodeNotReady"}, reasons(eventFilter{involvedKinds: []string{"node"}}.apply(events, now)))
+}
+
+func TestEventAggregator(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	backOff := data.EventInfo{ClusterName: "a", Type: "Warning", Reason: "BackOff", Object: "Pod/api", Count: 3, LastSeen: now}
+	aggregator := newEventAggregator()
+
+	merged, changed := aggregator.observe("uid-1", backOff)
+	assert.True(t, changed)
+	assert.Equal(t, int32(3), merged.Count)
+
+	_, changed = aggregator.observe("uid-1", backOff)
+	assert.False(t, changed, "an update that adds no occurrence is not printed again")
+
+	backOff.Count, backOff.LastSeen = 4, now.Add(time.Minute)
+	merged, changed = aggregator.observe("uid-1", backOff)
+	assert.True(t, changed)
+	assert.Equal(t, int32(4), merged.Count)
+
+	// A new series of the same event adds up with the previous one
+	newSeries := backOff
+	newSeries.Count, newSeries.LastSeen = 1, now.Add(time.Hour)
+	merged, changed = aggregator.observe("uid-2", newSeries)
+	assert.True(t, changed)
+	assert.Equal(t, int32(5), merged.Count)
+	assert.Equal(t, now.Add(time.Hour), merged.LastSeen)
+}
+
+func TestEventAggregator_Forget(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	backOff := data.EventInfo{ClusterName: "a", Type: "Warning", Reason: "BackOff", Object: "Pod/api", Count: 3, LastSeen: now}
+	aggregator := newEventAggregator()
+
+	aggregator.observe("uid-1", backOff)
+	aggregator.observe("uid-2", backOff)
+
+	aggregator.forget("uid-1", backOff)
+	assert.Len(t, aggregator.objects, 1)
+	merged, _ := aggregator.observe("uid-2", backOff)
+	assert.Equal(t, int32(3), merged.Count, "deleted objects no longer add up")
+
+	aggregator.forget("uid-2", backOff)
+	assert.Empty(t, aggregator.objects, "keys without objects are dropped")
+
+	aggregator.forget("uid-3", backOff)
+	assert.Empty(t, aggregator.objects)
+}
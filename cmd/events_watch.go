@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/jordiprats/kubectl-eks/pkg/k8s"
+	"github.com/jordiprats/kubectl-eks/pkg/printutils"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// streamedEvent is the latest state of a single event object of a cluster
+type streamedEvent struct {
+	uid     string
+	event   data.EventInfo
+	deleted bool
+}
+
+func runEventsWatch(clusterList []data.ClusterInfo, namespace string, filter eventFilter, noHeaders bool) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	config, err := loadingRules.Load()
+	if err != nil {
+		log.Fatalf("Error loading kubeconfig: %v", err)
+	}
+	previousContext := config.CurrentContext
+
+	// Clients are built one cluster at a time since updating the kubeconfig
+	// switches the current context; the watches then run concurrently.
+	targets := []clusterWatchTarget{}
+	gvrs := []schema.GroupVersionResource{}
+	for _, clusterInfo := range clusterList {
+		restConfig, err := clusterRestConfig(clusterInfo)
+		if err != nil {
+			log.Printf("Warning: Unable to connect to cluster %s: %v", clusterInfo.ClusterName, err)
+			continue
+		}
+
+		dynamicClient, err := dynamic.NewForConfig(restConfig)
+		if err != nil {
+			log.Printf("Warning: Unable to connect to cluster %s: %v", clusterInfo.ClusterName, err)
+			continue
+		}
+
+		discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+		if err != nil {
+			log.Printf("Warning: Unable to connect to cluster %s: %v", clusterInfo.ClusterName, err)
+			continue
+		}
+
+		gvr := k8s.EventsV1GVR
+		if _, err := discoveryClient.ServerResourcesForGroupVersion(gvr.GroupVersion().String()); err != nil {
+			gvr = k8s.CoreEventsGVR
+		}
+
+		targets = append(targets, clusterWatchTarget{
+			cluster:   clusterInfo,
+			resource:  dynamicClient.Resource(gvr).Namespace(namespace),
+			namespace: namespace,
+		})
+		gvrs = append(gvrs, gvr)
+	}
+
+	config, err = loadingRules.Load()
+	if err != nil {
+		log.Fatalf("Error loading kubeconfig: %v", err)
+	}
+	config.CurrentContext = previousContext
+	if err := clientcmd.ModifyConfig(loadingRules, *config, true); err != nil {
+		log.Fatalf("Error updating kubeconfig: %v", err)
+	}
+
+	if len(targets) == 0 {
+		log.Fatalf("No cluster could be watched")
+	}
+
+	events := make(chan streamedEvent)
+	var wg sync.WaitGroup
+
+	for i, target := range targets {
+		wg.Add(1)
+		go func(target clusterWatchTarget, gvr schema.GroupVersionResource) {
+			defer wg.Done()
+
+			onEvent := func(eventType watch.EventType, obj *unstructured.Unstructured) {
+				info, err := eventInfoFromUnstructured(gvr, obj)
+				if err != nil {
+					log.Printf("Warning: Unable to decode event %s/%s of cluster %s: %v", obj.GetNamespace(), obj.GetName(), target.cluster.ClusterName, err)
+					return
+				}
+				info.Profile = target.cluster.AWSProfile
+				info.Region = target.cluster.Region
+				info.ClusterName = target.cluster.ClusterName
+
+				select {
+				case events <- streamedEvent{uid: string(obj.GetUID()), event: info, deleted: eventType == watch.Deleted}:
+				case <-ctx.Done():
+				}
+			}
+
+			onError := func(err error) {
+				log.Printf("Warning: Watching events of cluster %s: %v", target.cluster.ClusterName, err)
+			}
+
+			watchResources(ctx, target.resource, metav1.ListOptions{}, onEvent, onError)
+		}(target, gvrs[i])
+	}
+
+	go func() {
+		wg.Wait()
+		close(events)
+	}()
+
+	printer := printutils.NewEventStreamPrinter(os.Stdout, noHeaders)
+	aggregator := newEventAggregator()
+	for streamed := range events {
+		// Expired events are garbage collected, that is not news
+		if streamed.deleted {
+			aggregator.forget(streamed.uid, streamed.event)
+			continue
+		}
+		merged, changed := aggregator.observe(streamed.uid, streamed.event)
+		if changed && filter.matches(merged, time.Now()) {
+			printer.Print(merged)
+		}
+	}
+}
+
+func eventInfoFromUnstructured(gvr schema.GroupVersionResource, obj *unstructured.Unstructured) (data.EventInfo, error) {
+	if gvr == k8s.EventsV1GVR {
+		event := &eventsv1.Event{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, event); err != nil {
+			return data.EventInfo{}, err
+		}
+		return k8s.EventInfoFromV1(event), nil
+	}
+
+	event := &corev1.Event{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, event); err != nil {
+		return data.EventInfo{}, err
+	}
+	return k8s.EventInfoFromCore(event), nil
+}
+
+// eventAggregator merges the streamed event objects that share a key, the
+// same way k8s.DedupeEvents does for a listing
+type eventAggregator struct {
+	objects map[k8s.EventKey]map[string]data.EventInfo
+}
+
+func newEventAggregator() *eventAggregator {
+	return &eventAggregator{objects: make(map[k8s.EventKey]map[string]data.EventInfo)}
+}
+
+// observe records the latest state of an event object and returns the merged
+// event of its key, along with whether the object brought anything new (a
+// new occurrence, a higher count or a later last seen time)
+func (a *eventAggregator) observe(uid string, event data.EventInfo) (data.EventInfo, bool) {
+	key := k8s.KeyOf(event)
+	objects, exists := a.objects[key]
+	if !exists {
+		objects = make(map[string]data.EventInfo)
+		a.objects[key] = objects
+	}
+
+	previous, seen := objects[uid]
+	changed := !seen || event.Count != previous.Count || !event.LastSeen.Equal(previous.LastSeen)
+	objects[uid] = event
+
+	list := make([]data.EventInfo, 0, len(objects))
+	for _, object := range objects {
+		list = append(list, object)
+	}
+	return k8s.DedupeEvents(list)[0], changed
+}
+
+// forget drops a deleted event object, and its key once no object is left,
+// so long watches do not keep every expired event in memory
+func (a *eventAggregator) forget(uid string, event data.EventInfo) {
+	key := k8s.KeyOf(event)
+	objects, exists := a.objects[key]
+	if !exists {
+		return
+	}
+	delete(objects, uid)
+	if len(objects) == 0 {
+		delete(a.objects, key)
+	}
+}
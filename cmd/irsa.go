@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"log"
+	"strings"
 
 	"github.com/jordiprats/kubectl-eks/pkg/data"
 	"github.com/jordiprats/kubectl-eks/pkg/k8s"
@@ -44,10 +45,13 @@ from the eks.amazonaws.com/role-arn annotation.`,
 			namespace = ""
 		}
 
-		serviceAccounts, err := k8s.GetServiceAccountsWithIRSA(context.Background(), namespace)
+		serviceAccounts, inaccessible, err := k8s.GetServiceAccountsWithIRSA(context.Background(), namespace)
 		if err != nil {
 			log.Fatalf("Error getting service accounts: %v", err)
 		}
+		if len(inaccessible) > 0 {
+			log.Printf("Warning: Unable to list service accounts in namespaces: %s", strings.Join(inaccessible, ", "))
+		}
 
 		if len(serviceAccounts) == 0 {
 			if namespace == "" {
@@ -10,11 +10,17 @@ var karpenterCmd = &cobra.Command{
 	Short:   "Karpenter resource management commands",
 	Long: `Manage and inspect Karpenter resources across EKS clusters.
 
-Provides commands to list and inspect Karpenter NodePools, NodeClaims,
-AMI usage, and drift status.`,
+Provides commands to list and inspect Karpenter NodePools, EC2NodeClasses,
+NodeClaims, AMI usage, drift status and disruption budgets, and explain
+which NodePool a pod would be scheduled on. Clusters still running the
+v1beta1 API or v1alpha5 Provisioners, Machines and AWSNodeTemplates are
+supported as well.`,
 	Example: `  # List Karpenter NodePools across clusters
   kubectl eks karpenter nodepools
   
+  # List EC2NodeClasses with their resolved AMIs, subnets and security groups
+  kubectl eks karpenter nodeclasses -o wide
+  
   # List active NodeClaims
   kubectl eks karpenter nodeclaims
   
@@ -22,7 +28,16 @@ AMI usage, and drift status.`,
   kubectl eks karpenter ami
   
   # List drifted nodes/nodeclaims
-  kubectl eks karpenter drift`,
+  kubectl eks karpenter drift
+  
+  # Check disruption budgets and what blocks consolidation
+  kubectl eks karpenter disruption
+  
+  # Explain which NodePool a pod would be launched on
+  kubectl eks karpenter explain-pod deployment.yaml
+  
+  # Show which Karpenter API each cluster serves
+  kubectl eks karpenter version`,
 }
 
 func init() {
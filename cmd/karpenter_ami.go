@@ -17,12 +17,20 @@ var karpenterAMICmd = &cobra.Command{
 	Long: `Show current AMIs in use per NodePool across clusters.
 
 This helps identify which AMIs are being used by each NodePool for
-inventory and tracking purposes.`,
+inventory and tracking purposes.
+
+Use -o wide to describe all the AMIs in use with a single EC2 call and show
+their name, architecture, creation date, deprecation time and age in days,
+whether they are the newest AMI the EC2NodeClass selects for the cluster
+version and architecture, and which nodes run AMIs older than --max-age days.`,
 	Example: `  # Show AMI usage for current cluster
   kubectl eks karpenter ami
 
   # Show AMI usage across clusters matching filter
-  kubectl eks karpenter ami --name-contains prod`,
+  kubectl eks karpenter ami --name-contains prod
+
+  # Show AMI details and flag nodes running AMIs older than 60 days
+  kubectl eks karpenter ami -o wide --max-age 60`,
 	Run: func(cmd *cobra.Command, args []string) {
 		refresh, _ := cmd.Flags().GetBool("refresh")
 		profile, _ := cmd.Flags().GetString("profile")
@@ -32,6 +40,9 @@ inventory and tracking purposes.`,
 		region, _ := cmd.Flags().GetString("region")
 		version, _ := cmd.Flags().GetString("version")
 		noHeaders, _ := cmd.Flags().GetBool("no-headers")
+		output, _ := cmd.Flags().GetString("output")
+		maxAge, _ := cmd.Flags().GetInt("max-age")
+		wide := output == "wide"
 
 		hasFilters := profile != "" || profileContains != "" || nameContains != "" ||
 			nameNotContains != "" || region != "" || version != ""
@@ -80,7 +91,7 @@ inventory and tracking purposes.`,
 				continue
 			}
 
-			amiUsage, err := karpenter.GetAMIUsage(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName, clusterInfo.Version)
+			amiUsage, err := karpenter.GetAMIUsage(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName, clusterInfo.Version, wide, maxAge)
 			if err != nil {
 				log.Printf("Warning: Failed to get AMI usage from cluster %s: %v", clusterInfo.ClusterName, err)
 				continue
@@ -89,7 +100,7 @@ inventory and tracking purposes.`,
 			allAMIUsage = append(allAMIUsage, amiUsage...)
 		}
 
-		printutils.PrintKarpenterAMIUsage(noHeaders, allAMIUsage...)
+		printutils.PrintKarpenterAMIUsage(noHeaders, wide, allAMIUsage...)
 
 		saveCacheToDisk()
 	},
@@ -103,6 +114,8 @@ func init() {
 	karpenterAMICmd.Flags().StringP("name-not-contains", "x", "", "Cluster name does not contain string")
 	karpenterAMICmd.Flags().StringP("region", "r", "", "AWS region to use")
 	karpenterAMICmd.Flags().StringP("version", "v", "", "Filter by EKS version")
+	karpenterAMICmd.Flags().StringP("output", "o", "", "Output format: wide")
+	karpenterAMICmd.Flags().Int("max-age", 90, "With -o wide, flag nodes running AMIs older than this many days (0 to disable)")
 
 	karpenterCmd.AddCommand(karpenterAMICmd)
 }
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/jordiprats/kubectl-eks/pkg/eks"
+	"github.com/jordiprats/kubectl-eks/pkg/karpenter"
+	"github.com/jordiprats/kubectl-eks/pkg/printutils"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var karpenterDisruptionCmd = &cobra.Command{
+	Use:   "disruption",
+	Short: "Show Karpenter disruption budgets, consolidation and blocked nodes",
+	Long: `Show why Karpenter is or is not disrupting nodes, per NodePool, across
+clusters.
+
+For every NodePool shows:
+  - consolidationPolicy and consolidateAfter
+  - the disruption budgets; scheduled budgets are evaluated (in UTC, as
+    Karpenter does) to tell whether they are active now
+  - how many nodes the active budgets allow to disrupt now, per reason when
+    budgets are limited to some reasons
+  - NodeClaims being disrupted (deleting or tainted karpenter.sh/disrupted),
+    consolidation candidates and NodeClaims that cannot be drained
+
+A NodeClaim cannot be drained when it, its node or one of its pods has the
+karpenter.sh/do-not-disrupt annotation, or when one of its pods is covered
+by a PodDisruptionBudget that allows no disruptions.
+
+Use --nodes to list those NodeClaims with what is blocking them.`,
+	Example: `  # Disruption settings of the current cluster
+  kubectl eks karpenter disruption
+
+  # NodeClaims being disrupted, consolidatable or blocked in production
+  kubectl eks karpenter disruption --nodes --name-contains prod`,
+	Run: func(cmd *cobra.Command, args []string) {
+		refresh, _ := cmd.Flags().GetBool("refresh")
+		profile, _ := cmd.Flags().GetString("profile")
+		profileContains, _ := cmd.Flags().GetString("profile-contains")
+		nameContains, _ := cmd.Flags().GetString("name-contains")
+		nameNotContains, _ := cmd.Flags().GetString("name-not-contains")
+		region, _ := cmd.Flags().GetString("region")
+		version, _ := cmd.Flags().GetString("version")
+		noHeaders, _ := cmd.Flags().GetBool("no-headers")
+		showNodes, _ := cmd.Flags().GetBool("nodes")
+
+		hasFilters := profile != "" || profileContains != "" || nameContains != "" ||
+			nameNotContains != "" || region != "" || version != ""
+
+		var clusterList []data.ClusterInfo
+		var err error
+
+		if hasFilters {
+			loadCacheFromDisk()
+			if CachedData == nil {
+				CachedData = &data.KubeCtlEksCache{
+					ClusterByARN: make(map[string]data.ClusterInfo),
+					ClusterList:  make(map[string]map[string][]data.ClusterInfo),
+				}
+			}
+			clusterList, err = LoadClusterList([]string{}, profile, profileContains, nameContains, nameNotContains, region, version, refresh)
+			if err != nil {
+				log.Fatalf("Error loading cluster list: %v", err)
+			}
+		} else {
+			clusterInfo, err := GetCurrentClusterInfo()
+			if err != nil {
+				log.Fatalf("Error getting current cluster info: %v", err)
+			}
+			clusterList = []data.ClusterInfo{clusterInfo}
+		}
+
+		// Save and restore context
+		loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+		config, err := loadingRules.Load()
+		if err != nil {
+			log.Fatalf("Error loading kubeconfig: %v", err)
+		}
+		previousContext := config.CurrentContext
+		defer func() {
+			config.CurrentContext = previousContext
+			clientcmd.ModifyConfig(loadingRules, *config, true)
+		}()
+
+		allPools := []data.KarpenterDisruptionInfo{}
+		allNodes := []data.KarpenterDisruptionNodeInfo{}
+
+		for _, clusterInfo := range clusterList {
+			err := eks.UpdateKubeConfig(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName, "")
+			if err != nil {
+				log.Printf("Warning: Failed to update kubeconfig for cluster %s: %v", clusterInfo.ClusterName, err)
+				continue
+			}
+
+			pools, nodes, err := karpenter.GetDisruption(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName)
+			if err != nil {
+				log.Printf("Warning: Failed to get disruption status from cluster %s: %v", clusterInfo.ClusterName, err)
+				continue
+			}
+
+			allPools = append(allPools, pools...)
+			allNodes = append(allNodes, nodes...)
+		}
+
+		if showNodes {
+			printutils.PrintKarpenterDisruptionNodes(noHeaders, allNodes...)
+		} else {
+			printutils.PrintKarpenterDisruption(noHeaders, allPools...)
+		}
+
+		saveCacheToDisk()
+	},
+}
+
+func init() {
+	karpenterDisruptionCmd.Flags().BoolP("refresh", "u", false, "Do not use cached data, refresh from AWS")
+	karpenterDisruptionCmd.Flags().StringP("profile", "p", "", "AWS profile to use")
+	karpenterDisruptionCmd.Flags().StringP("profile-contains", "q", "", "AWS profile contains string")
+	karpenterDisruptionCmd.Flags().StringP("name-contains", "c", "", "Cluster name contains string")
+	karpenterDisruptionCmd.Flags().StringP("name-not-contains", "x", "", "Cluster name does not contain string")
+	karpenterDisruptionCmd.Flags().StringP("region", "r", "", "AWS region to use")
+	karpenterDisruptionCmd.Flags().StringP("version", "v", "", "Filter by EKS version")
+	karpenterDisruptionCmd.Flags().Bool("nodes", false, "List the NodeClaims being disrupted, consolidatable or blocked")
+
+	karpenterCmd.AddCommand(karpenterDisruptionCmd)
+}
@@ -17,12 +17,24 @@ var karpenterDriftCmd = &cobra.Command{
 	Long: `List nodes and NodeClaims currently in drifted state across clusters.
 
 Drift occurs when NodeClaims no longer match their NodePool requirements
-due to configuration changes, AMI updates, or other factors.`,
+due to configuration changes, AMI updates, or other factors.
+
+For every drifted NodeClaim shows when it drifted and the reason Karpenter
+gives, the change in the NodePool or EC2NodeClass behind it (template or
+spec hash, AMI, subnets, security groups or requirements) and what blocks
+its replacement: karpenter.sh/do-not-disrupt annotations on the NodeClaim,
+node or pods, and PodDisruptionBudgets that allow no disruptions.
+
+Use --summary to count, per NodePool, the NodeClaims that drifted and how
+many of them are blocked.`,
 	Example: `  # List drifted resources for current cluster
   kubectl eks karpenter drift
 
   # List drifted resources across clusters matching filter
-  kubectl eks karpenter drift --name-contains prod`,
+  kubectl eks karpenter drift --name-contains prod
+
+  # Drifted and blocked NodeClaims per NodePool
+  kubectl eks karpenter drift --summary`,
 	Run: func(cmd *cobra.Command, args []string) {
 		refresh, _ := cmd.Flags().GetBool("refresh")
 		profile, _ := cmd.Flags().GetString("profile")
@@ -32,6 +44,8 @@ due to configuration changes, AMI updates, or other factors.`,
 		region, _ := cmd.Flags().GetString("region")
 		version, _ := cmd.Flags().GetString("version")
 		noHeaders, _ := cmd.Flags().GetBool("no-headers")
+		output, _ := cmd.Flags().GetString("output")
+		summary, _ := cmd.Flags().GetBool("summary")
 
 		hasFilters := profile != "" || profileContains != "" || nameContains != "" ||
 			nameNotContains != "" || region != "" || version != ""
@@ -72,6 +86,7 @@ due to configuration changes, AMI updates, or other factors.`,
 		}()
 
 		allDriftedResources := []data.KarpenterDriftInfo{}
+		allSummaries := []data.KarpenterDriftSummary{}
 
 		for _, clusterInfo := range clusterList {
 			err := eks.UpdateKubeConfig(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName, "")
@@ -80,16 +95,21 @@ due to configuration changes, AMI updates, or other factors.`,
 				continue
 			}
 
-			driftedResources, err := karpenter.GetDriftedResources(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName)
+			driftedResources, summaries, err := karpenter.GetDriftedResources(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName)
 			if err != nil {
 				log.Printf("Warning: Failed to get drifted resources from cluster %s: %v", clusterInfo.ClusterName, err)
 				continue
 			}
 
 			allDriftedResources = append(allDriftedResources, driftedResources...)
+			allSummaries = append(allSummaries, summaries...)
 		}
 
-		printutils.PrintKarpenterDrift(noHeaders, allDriftedResources...)
+		if summary {
+			printutils.PrintKarpenterDriftSummary(noHeaders, allSummaries...)
+		} else {
+			printutils.PrintKarpenterDrift(noHeaders, output == "wide", allDriftedResources...)
+		}
 
 		saveCacheToDisk()
 	},
@@ -103,6 +123,8 @@ func init() {
 	karpenterDriftCmd.Flags().StringP("name-not-contains", "x", "", "Cluster name does not contain string")
 	karpenterDriftCmd.Flags().StringP("region", "r", "", "AWS region to use")
 	karpenterDriftCmd.Flags().StringP("version", "v", "", "Filter by EKS version")
+	karpenterDriftCmd.Flags().StringP("output", "o", "", "Output format: wide")
+	karpenterDriftCmd.Flags().Bool("summary", false, "Show the number of drifted and blocked NodeClaims per NodePool")
 
 	karpenterCmd.AddCommand(karpenterDriftCmd)
 }
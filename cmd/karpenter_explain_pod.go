@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/jordiprats/kubectl-eks/pkg/eks"
+	"github.com/jordiprats/kubectl-eks/pkg/karpenter"
+	"github.com/jordiprats/kubectl-eks/pkg/printutils"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var karpenterExplainPodCmd = &cobra.Command{
+	Use:     "explain-pod <pod|manifest.yaml>",
+	Aliases: []string{"explain"},
+	Short:   "Explain which NodePool Karpenter would choose for a pod",
+	Long: `Explain which NodePool Karpenter would launch a node from for a pod.
+
+The pod is either a pod in the cluster, looked up by name in --namespace, or
+a YAML or JSON manifest of a Pod or of a workload with a pod template
+(Deployment, StatefulSet, DaemonSet, ReplicaSet, Job or CronJob).
+
+Its node selector, required node affinity, tolerations and resource requests
+are evaluated against the requirements, labels, taints, limits and weight of
+every NodePool. NodePools that cannot be used are Excluded with the reasons
+why, the remaining ones are Compatible and the one with the highest weight
+is Selected. Preferred node affinity is honoured while some NodePool
+satisfies it, as Karpenter does.
+
+The evaluation runs locally on the NodePools fetched from each cluster.
+Instance type availability and DaemonSet overhead are not considered.`,
+	Example: `  # Explain where a pending pod would land
+  kubectl eks karpenter explain-pod my-pod -n shop
+
+  # Explain a manifest against the NodePools of several clusters
+  kubectl eks karpenter explain-pod deployment.yaml --name-contains prod`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		refresh, _ := cmd.Flags().GetBool("refresh")
+		profile, _ := cmd.Flags().GetString("profile")
+		profileContains, _ := cmd.Flags().GetString("profile-contains")
+		nameContains, _ := cmd.Flags().GetString("name-contains")
+		nameNotContains, _ := cmd.Flags().GetString("name-not-contains")
+		region, _ := cmd.Flags().GetString("region")
+		version, _ := cmd.Flags().GetString("version")
+		noHeaders, _ := cmd.Flags().GetBool("no-headers")
+		namespace, _ := cmd.Flags().GetString("namespace")
+
+		// A manifest is read once and evaluated against every cluster
+		var manifestPod *corev1.Pod
+		if _, err := os.Stat(args[0]); err == nil {
+			manifestPod, err = karpenter.LoadPodManifest(args[0])
+			if err != nil {
+				log.Fatalf("Error loading manifest: %v", err)
+			}
+		}
+
+		hasFilters := profile != "" || profileContains != "" || nameContains != "" ||
+			nameNotContains != "" || region != "" || version != ""
+
+		var clusterList []data.ClusterInfo
+		var err error
+
+		if hasFilters {
+			loadCacheFromDisk()
+			if CachedData == nil {
+				CachedData = &data.KubeCtlEksCache{
+					ClusterByARN: make(map[string]data.ClusterInfo),
+					ClusterList:  make(map[string]map[string][]data.ClusterInfo),
+				}
+			}
+			clusterList, err = LoadClusterList([]string{}, profile, profileContains, nameContains, nameNotContains, region, version, refresh)
+			if err != nil {
+				log.Fatalf("Error loading cluster list: %v", err)
+			}
+		} else {
+			clusterInfo, err := GetCurrentClusterInfo()
+			if err != nil {
+				log.Fatalf("Error getting current cluster info: %v", err)
+			}
+			clusterList = []data.ClusterInfo{clusterInfo}
+		}
+
+		// Save and restore context
+		loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+		config, err := loadingRules.Load()
+		if err != nil {
+			log.Fatalf("Error loading kubeconfig: %v", err)
+		}
+		previousContext := config.CurrentContext
+		defer func() {
+			config.CurrentContext = previousContext
+			clientcmd.ModifyConfig(loadingRules, *config, true)
+		}()
+
+		allPlacements := []data.KarpenterPodPlacementInfo{}
+
+		for _, clusterInfo := range clusterList {
+			err := eks.UpdateKubeConfig(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName, "")
+			if err != nil {
+				log.Printf("Warning: Failed to update kubeconfig for cluster %s: %v", clusterInfo.ClusterName, err)
+				continue
+			}
+
+			pod := manifestPod
+			if pod == nil {
+				pod, err = karpenter.GetPod(namespace, args[0])
+				if err != nil {
+					log.Printf("Warning: Failed to get pod from cluster %s: %v", clusterInfo.ClusterName, err)
+					continue
+				}
+				if pod.Spec.NodeName != "" {
+					log.Printf("Warning: pod %s/%s already runs on node %s in cluster %s", pod.Namespace, pod.Name, pod.Spec.NodeName, clusterInfo.ClusterName)
+				}
+			}
+
+			nodePools, err := karpenter.GetNodePools(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName)
+			if err != nil {
+				log.Printf("Warning: Failed to get NodePools from cluster %s: %v", clusterInfo.ClusterName, err)
+				continue
+			}
+
+			allPlacements = append(allPlacements, karpenter.ExplainPod(pod, nodePools)...)
+		}
+
+		printutils.PrintKarpenterPodPlacement(noHeaders, allPlacements...)
+
+		saveCacheToDisk()
+	},
+}
+
+func init() {
+	karpenterExplainPodCmd.Flags().BoolP("refresh", "u", false, "Do not use cached data, refresh from AWS")
+	karpenterExplainPodCmd.Flags().StringP("profile", "p", "", "AWS profile to use")
+	karpenterExplainPodCmd.Flags().StringP("profile-contains", "q", "", "AWS profile contains string")
+	karpenterExplainPodCmd.Flags().StringP("name-contains", "c", "", "Cluster name contains string")
+	karpenterExplainPodCmd.Flags().StringP("name-not-contains", "x", "", "Cluster name does not contain string")
+	karpenterExplainPodCmd.Flags().StringP("region", "r", "", "AWS region to use")
+	karpenterExplainPodCmd.Flags().StringP("version", "v", "", "Filter by EKS version")
+	karpenterExplainPodCmd.Flags().StringP("namespace", "n", "default", "Namespace of the pod")
+
+	karpenterCmd.AddCommand(karpenterExplainPodCmd)
+}
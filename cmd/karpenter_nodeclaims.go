@@ -12,13 +12,24 @@ import (
 )
 
 var karpenterNodeClaimsCmd = &cobra.Command{
-	Use:     "nodeclaims",
+	Use:     "nodeclaims [name]",
 	Aliases: []string{"nc", "nodeclaim"},
 	Short:   "List Karpenter NodeClaims across clusters",
 	Long: `List active Karpenter NodeClaims across all clusters that match a filter.
 
 Shows provisioning status, instance type, AMI, capacity type, zone,
-and associated NodePool for each NodeClaim.`,
+and associated NodePool for each NodeClaim.
+
+With a NodeClaim name and --timeline, reconstructs its lifecycle from its
+conditions (Launched, Registered, Initialized, Ready, Drifted, ...) and the
+events of the NodeClaim and its node, showing the time elapsed since creation
+and between steps. Events are only kept for a limited time (one hour by
+default), so older NodeClaims show their conditions alone.
+
+With --stats, shows the p50, p95 and maximum time from launch (the Launched
+condition) to Ready per NodePool and instance type. NodeClaims whose Ready
+condition changed again more than a minute after Initialized, such as nodes
+that went NotReady and recovered, are left out.`,
 	Example: `  # List NodeClaims for current cluster
   kubectl eks karpenter nodeclaims
 
@@ -26,7 +37,14 @@ and associated NodePool for each NodeClaim.`,
   kubectl eks karpenter nodeclaims --name-contains prod
 
   # List NodeClaims with wide output
-  kubectl eks karpenter nodeclaims -o wide`,
+  kubectl eks karpenter nodeclaims -o wide
+
+  # Show how a NodeClaim was provisioned
+  kubectl eks karpenter nodeclaims default-x7k2p --timeline
+
+  # Show launch-to-ready percentiles per NodePool and instance type
+  kubectl eks karpenter nodeclaims --stats`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		refresh, _ := cmd.Flags().GetBool("refresh")
 		profile, _ := cmd.Flags().GetString("profile")
@@ -37,6 +55,19 @@ and associated NodePool for each NodeClaim.`,
 		version, _ := cmd.Flags().GetString("version")
 		noHeaders, _ := cmd.Flags().GetBool("no-headers")
 		output, _ := cmd.Flags().GetString("output")
+		timeline, _ := cmd.Flags().GetBool("timeline")
+		stats, _ := cmd.Flags().GetBool("stats")
+
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+		}
+		if timeline && name == "" {
+			log.Fatalf("--timeline requires a NodeClaim name")
+		}
+		if stats && name != "" {
+			log.Fatalf("--stats aggregates every NodeClaim and does not take a name")
+		}
 
 		hasFilters := profile != "" || profileContains != "" || nameContains != "" ||
 			nameNotContains != "" || region != "" || version != ""
@@ -77,6 +108,8 @@ and associated NodePool for each NodeClaim.`,
 		}()
 
 		allNodeClaims := []data.KarpenterNodeClaimInfo{}
+		allTimeline := []data.KarpenterNodeClaimTimelineEntry{}
+		allStats := []data.KarpenterLaunchStats{}
 
 		for _, clusterInfo := range clusterList {
 			err := eks.UpdateKubeConfig(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName, "")
@@ -85,16 +118,47 @@ and associated NodePool for each NodeClaim.`,
 				continue
 			}
 
+			if timeline {
+				entries, err := karpenter.GetNodeClaimTimeline(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName, name)
+				if err != nil {
+					log.Printf("Warning: Failed to get the NodeClaim timeline from cluster %s: %v", clusterInfo.ClusterName, err)
+					continue
+				}
+				allTimeline = append(allTimeline, entries...)
+				continue
+			}
+
+			if stats {
+				launchStats, err := karpenter.GetLaunchStats(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName)
+				if err != nil {
+					log.Printf("Warning: Failed to get NodeClaim launch stats from cluster %s: %v", clusterInfo.ClusterName, err)
+					continue
+				}
+				allStats = append(allStats, launchStats...)
+				continue
+			}
+
 			nodeClaims, err := karpenter.GetNodeClaims(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName)
 			if err != nil {
 				log.Printf("Warning: Failed to get NodeClaims from cluster %s: %v", clusterInfo.ClusterName, err)
 				continue
 			}
 
-			allNodeClaims = append(allNodeClaims, nodeClaims...)
+			for _, nc := range nodeClaims {
+				if name == "" || nc.Name == name {
+					allNodeClaims = append(allNodeClaims, nc)
+				}
+			}
 		}
 
-		printutils.PrintKarpenterNodeClaims(noHeaders, output == "wide", allNodeClaims...)
+		switch {
+		case timeline:
+			printutils.PrintKarpenterNodeClaimTimeline(noHeaders, allTimeline...)
+		case stats:
+			printutils.PrintKarpenterLaunchStats(noHeaders, allStats...)
+		default:
+			printutils.PrintKarpenterNodeClaims(noHeaders, output == "wide", allNodeClaims...)
+		}
 
 		saveCacheToDisk()
 	},
@@ -109,6 +173,8 @@ func init() {
 	karpenterNodeClaimsCmd.Flags().StringP("region", "r", "", "AWS region to use")
 	karpenterNodeClaimsCmd.Flags().StringP("version", "v", "", "Filter by EKS version")
 	karpenterNodeClaimsCmd.Flags().StringP("output", "o", "", "Output format: wide")
+	karpenterNodeClaimsCmd.Flags().Bool("timeline", false, "Show the lifecycle of the named NodeClaim")
+	karpenterNodeClaimsCmd.Flags().Bool("stats", false, "Show launch-to-ready percentiles per NodePool and instance type")
 
 	karpenterCmd.AddCommand(karpenterNodeClaimsCmd)
 }
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/jordiprats/kubectl-eks/pkg/eks"
+	"github.com/jordiprats/kubectl-eks/pkg/karpenter"
+	"github.com/jordiprats/kubectl-eks/pkg/printutils"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var karpenterNodeClassesCmd = &cobra.Command{
+	Use:     "nodeclasses",
+	Aliases: []string{"nc", "nodeclass", "ec2nodeclasses"},
+	Short:   "List Karpenter EC2NodeClasses across clusters",
+	Long: `List Karpenter EC2NodeClasses across all clusters that match a filter.
+
+Shows the AMI selector terms and the number of AMIs, subnets and security
+groups they resolved to, the node IAM role and whether the EC2NodeClass is
+Ready.
+
+Use -o wide to also show the subnet and security group selector terms, the
+resolved AMI, subnet and security group IDs, the instance profile, the block
+device mappings and the conditions that are not True.`,
+	Example: `  # List EC2NodeClasses for current cluster
+  kubectl eks karpenter nodeclasses
+
+  # List EC2NodeClasses across clusters matching filter
+  kubectl eks karpenter nodeclasses --name-contains prod
+
+  # Show resolved AMIs, subnets and security groups
+  kubectl eks karpenter nodeclasses -o wide`,
+	Run: func(cmd *cobra.Command, args []string) {
+		refresh, _ := cmd.Flags().GetBool("refresh")
+		profile, _ := cmd.Flags().GetString("profile")
+		profileContains, _ := cmd.Flags().GetString("profile-contains")
+		nameContains, _ := cmd.Flags().GetString("name-contains")
+		nameNotContains, _ := cmd.Flags().GetString("name-not-contains")
+		region, _ := cmd.Flags().GetString("region")
+		version, _ := cmd.Flags().GetString("version")
+		noHeaders, _ := cmd.Flags().GetBool("no-headers")
+		output, _ := cmd.Flags().GetString("output")
+
+		hasFilters := profile != "" || profileContains != "" || nameContains != "" ||
+			nameNotContains != "" || region != "" || version != ""
+
+		var clusterList []data.ClusterInfo
+		var err error
+
+		if hasFilters {
+			loadCacheFromDisk()
+			if CachedData == nil {
+				CachedData = &data.KubeCtlEksCache{
+					ClusterByARN: make(map[string]data.ClusterInfo),
+					ClusterList:  make(map[string]map[string][]data.ClusterInfo),
+				}
+			}
+			clusterList, err = LoadClusterList([]string{}, profile, profileContains, nameContains, nameNotContains, region, version, refresh)
+			if err != nil {
+				log.Fatalf("Error loading cluster list: %v", err)
+			}
+		} else {
+			clusterInfo, err := GetCurrentClusterInfo()
+			if err != nil {
+				log.Fatalf("Error getting current cluster info: %v", err)
+			}
+			clusterList = []data.ClusterInfo{clusterInfo}
+		}
+
+		// Save and restore context
+		loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+		config, err := loadingRules.Load()
+		if err != nil {
+			log.Fatalf("Error loading kubeconfig: %v", err)
+		}
+		previousContext := config.CurrentContext
+		defer func() {
+			config.CurrentContext = previousContext
+			clientcmd.ModifyConfig(loadingRules, *config, true)
+		}()
+
+		allNodeClasses := []data.KarpenterNodeClassInfo{}
+
+		for _, clusterInfo := range clusterList {
+			err := eks.UpdateKubeConfig(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName, "")
+			if err != nil {
+				log.Printf("Warning: Failed to update kubeconfig for cluster %s: %v", clusterInfo.ClusterName, err)
+				continue
+			}
+
+			nodeClasses, err := karpenter.GetNodeClasses(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName)
+			if err != nil {
+				log.Printf("Warning: Failed to get EC2NodeClasses from cluster %s: %v", clusterInfo.ClusterName, err)
+				continue
+			}
+
+			allNodeClasses = append(allNodeClasses, nodeClasses...)
+		}
+
+		printutils.PrintKarpenterNodeClasses(noHeaders, output == "wide", allNodeClasses...)
+
+		saveCacheToDisk()
+	},
+}
+
+func init() {
+	karpenterNodeClassesCmd.Flags().BoolP("refresh", "u", false, "Do not use cached data, refresh from AWS")
+	karpenterNodeClassesCmd.Flags().StringP("profile", "p", "", "AWS profile to use")
+	karpenterNodeClassesCmd.Flags().StringP("profile-contains", "q", "", "AWS profile contains string")
+	karpenterNodeClassesCmd.Flags().StringP("name-contains", "c", "", "Cluster name contains string")
+	karpenterNodeClassesCmd.Flags().StringP("name-not-contains", "x", "", "Cluster name does not contain string")
+	karpenterNodeClassesCmd.Flags().StringP("region", "r", "", "AWS region to use")
+	karpenterNodeClassesCmd.Flags().StringP("version", "v", "", "Filter by EKS version")
+	karpenterNodeClassesCmd.Flags().StringP("output", "o", "", "Output format: wide")
+
+	karpenterCmd.AddCommand(karpenterNodeClassesCmd)
+}
@@ -18,7 +18,11 @@ var karpenterNodePoolsCmd = &cobra.Command{
 	Long: `List Karpenter NodePools across all clusters that match a filter.
 
 Shows instance type constraints, resource limits, disruption settings,
-and associated NodeClass information.`,
+and associated NodeClass information.
+
+With -o wide the CPU and memory provisioned by each NodePool are shown
+against its spec.limits along with the percentage consumed, as well as the
+number of NodeClaims it owns by capacity type (on-demand, spot, ...).`,
 	Example: `  # List NodePools for current cluster
   kubectl eks karpenter nodepools
 
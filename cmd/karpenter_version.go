@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/jordiprats/kubectl-eks/pkg/eks"
+	"github.com/jordiprats/kubectl-eks/pkg/karpenter"
+	"github.com/jordiprats/kubectl-eks/pkg/printutils"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var karpenterVersionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show which Karpenter API each cluster serves",
+	Long: `Show the Karpenter API version served by each cluster and the version of
+the Karpenter controller running in it.
+
+Karpenter v1 serves karpenter.sh/v1 NodePools and NodeClaims, v0.32 to v0.37
+serve karpenter.sh/v1beta1 and older releases serve karpenter.sh/v1alpha5
+Provisioners and Machines with AWSNodeTemplates. The other karpenter commands
+discover the API of each cluster and read any of them. Clusters in the middle
+of an upgrade serve more than one API.`,
+	Example: `  # Show the Karpenter API of the current cluster
+  kubectl eks karpenter version
+
+  # Find clusters still running legacy Karpenter APIs
+  kubectl eks karpenter version --name-contains prod`,
+	Run: func(cmd *cobra.Command, args []string) {
+		refresh, _ := cmd.Flags().GetBool("refresh")
+		profile, _ := cmd.Flags().GetString("profile")
+		profileContains, _ := cmd.Flags().GetString("profile-contains")
+		nameContains, _ := cmd.Flags().GetString("name-contains")
+		nameNotContains, _ := cmd.Flags().GetString("name-not-contains")
+		region, _ := cmd.Flags().GetString("region")
+		version, _ := cmd.Flags().GetString("version")
+		noHeaders, _ := cmd.Flags().GetBool("no-headers")
+
+		hasFilters := profile != "" || profileContains != "" || nameContains != "" ||
+			nameNotContains != "" || region != "" || version != ""
+
+		var clusterList []data.ClusterInfo
+		var err error
+
+		if hasFilters {
+			loadCacheFromDisk()
+			if CachedData == nil {
+				CachedData = &data.KubeCtlEksCache{
+					ClusterByARN: make(map[string]data.ClusterInfo),
+					ClusterList:  make(map[string]map[string][]data.ClusterInfo),
+				}
+			}
+			clusterList, err = LoadClusterList([]string{}, profile, profileContains, nameContains, nameNotContains, region, version, refresh)
+			if err != nil {
+				log.Fatalf("Error loading cluster list: %v", err)
+			}
+		} else {
+			clusterInfo, err := GetCurrentClusterInfo()
+			if err != nil {
+				log.Fatalf("Error getting current cluster info: %v", err)
+			}
+			clusterList = []data.ClusterInfo{clusterInfo}
+		}
+
+		// Save and restore context
+		loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+		config, err := loadingRules.Load()
+		if err != nil {
+			log.Fatalf("Error loading kubeconfig: %v", err)
+		}
+		previousContext := config.CurrentContext
+		defer func() {
+			config.CurrentContext = previousContext
+			clientcmd.ModifyConfig(loadingRules, *config, true)
+		}()
+
+		allVersions := []data.KarpenterVersionInfo{}
+
+		for _, clusterInfo := range clusterList {
+			err := eks.UpdateKubeConfig(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName, "")
+			if err != nil {
+				log.Printf("Warning: Failed to update kubeconfig for cluster %s: %v", clusterInfo.ClusterName, err)
+				continue
+			}
+
+			version, err := karpenter.GetVersion(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName)
+			if err != nil {
+				log.Printf("Warning: Failed to get Karpenter version from cluster %s: %v", clusterInfo.ClusterName, err)
+				continue
+			}
+
+			allVersions = append(allVersions, version)
+		}
+
+		printutils.PrintKarpenterVersions(noHeaders, allVersions...)
+
+		saveCacheToDisk()
+	},
+}
+
+func init() {
+	karpenterVersionCmd.Flags().BoolP("refresh", "u", false, "Do not use cached data, refresh from AWS")
+	karpenterVersionCmd.Flags().StringP("profile", "p", "", "AWS profile to use")
+	karpenterVersionCmd.Flags().StringP("profile-contains", "q", "", "AWS profile contains string")
+	karpenterVersionCmd.Flags().StringP("name-contains", "c", "", "Cluster name contains string")
+	karpenterVersionCmd.Flags().StringP("name-not-contains", "x", "", "Cluster name does not contain string")
+	karpenterVersionCmd.Flags().StringP("region", "r", "", "AWS region to use")
+	karpenterVersionCmd.Flags().StringP("version", "v", "", "Filter by EKS version")
+
+	karpenterCmd.AddCommand(karpenterVersionCmd)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/jordiprats/kubectl-eks/pkg/sts"
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
@@ -287,59 +288,67 @@ func enrichClusterNodeStats(clusterList []data.ClusterInfo) []data.ClusterInfo {
 			continue
 		}
 
-		nodes, err := k8s.GetNodesWithConfig(restConfig)
-		if err != nil {
-			continue
-		}
+		_ = addClusterNodeStats(cluster, restConfig)
+	}
 
-		cluster.NodeCount = len(nodes)
-		totalCPUUsedMilli := int64(0)
-		totalCPUCapacityMilli := int64(0)
-		totalCPUAllocMilli := int64(0)
-		totalMemUsedBytes := int64(0)
-		totalMemCapacityBytes := int64(0)
-		totalMemAllocBytes := int64(0)
-
-		for _, node := range nodes {
-			if strings.HasPrefix(node.Status, "Ready") {
-				cluster.NodeReady++
-			} else {
-				cluster.NodeNotReady++
-			}
+	return clusterList
+}
 
-			if strings.Contains(node.Status, "SchedulingDisabled") {
-				cluster.NodeSchedDisabled++
-			}
+// addClusterNodeStats fills in the node counts and the CPU and memory totals
+// of a cluster from its nodes
+func addClusterNodeStats(cluster *data.ClusterInfo, restConfig *rest.Config) error {
+	nodes, err := k8s.GetNodesWithConfig(restConfig)
+	if err != nil {
+		return err
+	}
 
-			if q, err := resource.ParseQuantity(node.CPUUsed); err == nil {
-				totalCPUUsedMilli += q.MilliValue()
-			}
-			if q, err := resource.ParseQuantity(node.CPUCapacity); err == nil {
-				totalCPUCapacityMilli += q.MilliValue()
-			}
-			if q, err := resource.ParseQuantity(node.CPUAllocatable); err == nil {
-				totalCPUAllocMilli += q.MilliValue()
-			}
+	cluster.NodeCount = len(nodes)
+	totalCPUUsedMilli := int64(0)
+	totalCPUCapacityMilli := int64(0)
+	totalCPUAllocMilli := int64(0)
+	totalMemUsedBytes := int64(0)
+	totalMemCapacityBytes := int64(0)
+	totalMemAllocBytes := int64(0)
+
+	for _, node := range nodes {
+		if strings.HasPrefix(node.Status, "Ready") {
+			cluster.NodeReady++
+		} else {
+			cluster.NodeNotReady++
+		}
 
-			if q, err := resource.ParseQuantity(node.MemoryUsed); err == nil {
-				totalMemUsedBytes += q.Value()
-			}
-			if q, err := resource.ParseQuantity(node.MemoryCapacity); err == nil {
-				totalMemCapacityBytes += q.Value()
-			}
-			if q, err := resource.ParseQuantity(node.MemoryAllocatable); err == nil {
-				totalMemAllocBytes += q.Value()
-			}
+		if strings.Contains(node.Status, "SchedulingDisabled") {
+			cluster.NodeSchedDisabled++
 		}
 
-		cluster.CPUUsedTotal = resource.NewMilliQuantity(totalCPUUsedMilli, resource.DecimalSI).String()
-		cluster.CPUCapacityTotal = resource.NewMilliQuantity(totalCPUCapacityMilli, resource.DecimalSI).String()
-		cluster.CPUAllocatableTotal = resource.NewMilliQuantity(totalCPUAllocMilli, resource.DecimalSI).String()
+		if q, err := resource.ParseQuantity(node.CPUUsed); err == nil {
+			totalCPUUsedMilli += q.MilliValue()
+		}
+		if q, err := resource.ParseQuantity(node.CPUCapacity); err == nil {
+			totalCPUCapacityMilli += q.MilliValue()
+		}
+		if q, err := resource.ParseQuantity(node.CPUAllocatable); err == nil {
+			totalCPUAllocMilli += q.MilliValue()
+		}
 
-		cluster.MemoryUsedTotal = resource.NewQuantity(totalMemUsedBytes, resource.BinarySI).String()
-		cluster.MemoryCapacityTotal = resource.NewQuantity(totalMemCapacityBytes, resource.BinarySI).String()
-		cluster.MemoryAllocatableTotal = resource.NewQuantity(totalMemAllocBytes, resource.BinarySI).String()
+		if q, err := resource.ParseQuantity(node.MemoryUsed); err == nil {
+			totalMemUsedBytes += q.Value()
+		}
+		if q, err := resource.ParseQuantity(node.MemoryCapacity); err == nil {
+			totalMemCapacityBytes += q.Value()
+		}
+		if q, err := resource.ParseQuantity(node.MemoryAllocatable); err == nil {
+			totalMemAllocBytes += q.Value()
+		}
 	}
 
-	return clusterList
+	cluster.CPUUsedTotal = resource.NewMilliQuantity(totalCPUUsedMilli, resource.DecimalSI).String()
+	cluster.CPUCapacityTotal = resource.NewMilliQuantity(totalCPUCapacityMilli, resource.DecimalSI).String()
+	cluster.CPUAllocatableTotal = resource.NewMilliQuantity(totalCPUAllocMilli, resource.DecimalSI).String()
+
+	cluster.MemoryUsedTotal = resource.NewQuantity(totalMemUsedBytes, resource.BinarySI).String()
+	cluster.MemoryCapacityTotal = resource.NewQuantity(totalMemCapacityBytes, resource.BinarySI).String()
+	cluster.MemoryAllocatableTotal = resource.NewQuantity(totalMemAllocBytes, resource.BinarySI).String()
+
+	return nil
 }
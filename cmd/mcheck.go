@@ -4,15 +4,27 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/jordiprats/kubectl-eks/pkg/data"
 	"github.com/jordiprats/kubectl-eks/pkg/eks"
+	"github.com/jordiprats/kubectl-eks/pkg/healthhistory"
+	"github.com/jordiprats/kubectl-eks/pkg/healthpolicy"
+	"github.com/jordiprats/kubectl-eks/pkg/k8s"
 	"github.com/jordiprats/kubectl-eks/pkg/printutils"
+	"github.com/robfig/cron/v3"
 	"github.com/spf13/cobra"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
@@ -23,14 +35,95 @@ var mCheckCmd = &cobra.Command{
 	Long: `Check the readiness/health status of Kubernetes resources across all clusters that match a filter.
 
 Checks the following resources:
-  - Pods: Running or Completed status (excludes Completed from unhealthy)
+  - Pods: Running or Completed status (excludes Completed from unhealthy), no
+    init, regular or ephemeral container in CrashLoopBackOff or failing to
+    pull its image, and no container OOM killed or restarted more than
+    --max-restarts times in total with its last termination within
+    --restart-window (restart counts are cumulative, the window skips
+    containers that have been stable since)
   - Deployments: Ready replicas match desired replicas
   - StatefulSets: Ready replicas match desired replicas
   - DaemonSets: Ready nodes match desired nodes
   - ReplicaSets: Ready replicas match desired replicas
+  - Jobs: Not failed
+  - CronJobs: Succeeded within their schedule (no scheduled run missed since the last success)
+  - PersistentVolumeClaims: Bound (Pending and Lost are unhealthy)
+  - PodDisruptionBudgets: As many healthy pods as desired and at least one
+    disruption allowed
+  - HorizontalPodAutoscalers: Able to scale, metrics available and below max
+    replicas (unless min and max are the same)
+  - Services: LoadBalancer services have an ingress address
+
+Use --kinds to also check other resource types, such as cert-manager
+Certificates, ExternalSecrets, Argo Rollouts or Karpenter NodeClaims. They are
+listed with the dynamic client and evaluated by their status: resources being
+deleted, with a status.observedGeneration behind metadata.generation, or with
+Stalled/Reconciling conditions are unhealthy; otherwise the Ready, Available,
+Healthy or Established condition decides, falling back to status.phase.
+Clusters where the resource type is not installed are skipped. Giving only
+--kinds checks just those types. Their kind includes the API group, e.g.
+Certificate.cert-manager.io, also in policy kinds rules.
 
 By default, checks all namespaces and only shows unhealthy resources.
-Use -n to check a specific namespace, use --all to show healthy resources too.`,
+Use -n to check a specific namespace, use --all to show healthy resources too.
+
+Each resource type is fetched with a single cluster-wide request. If RBAC
+denies cluster-wide access, namespaces are checked one by one and the ones
+that cannot be read are reported as inaccessible.
+
+Exit codes:
+  0  everything checked is healthy (or below the --fail-on thresholds)
+  1  unhealthy resources exceed the --fail-on thresholds
+  2  no threshold exceeded, but some clusters, resource kinds or namespaces
+     could not be checked
+
+--fail-on takes comma separated <kind>><count> thresholds, summed across
+all clusters. Kinds are pods, deployments, statefulsets, daemonsets,
+replicasets, jobs, cronjobs, pvcs, pdbs, hpas, services, custom (all the
+--kinds resources) and any. The default, any>0, fails on any unhealthy resource.
+Quote the value, as > is a shell redirection: --fail-on 'pods>5'.
+
+Use --wait to keep checking every --interval until the thresholds are met
+and every cluster could be checked, or --timeout expires.
+
+Use --policy to load a YAML health policy, so teams can share the command
+with different standards:
+
+  ignore:
+    namespaces: ["^kube-", "-sandbox$"]   # namespace regexes
+    resources:                           # kind, namespace/name regexes, label selector
+      - kind: Pod
+        name: "^debug-"
+      - selector: "team=experiments"
+  pods:
+    maxRestarts: 5                       # replaces the --max-restarts default
+    maxPendingAge: 10m                   # younger unscheduled or creating pods are healthy
+  requirePDB:                            # workloads need a matching PodDisruptionBudget
+    kinds: [Deployment, StatefulSet]
+    minReplicas: 2
+  kinds:
+    Deployment:
+      minReadyPercent: 75                # enough ready replicas is healthy
+    ReplicaSet:
+      skip: true
+
+Ignores are applied first, then the rules that tolerate resources
+(maxPendingAge, minReadyPercent) and last the stricter ones (maxRestarts,
+requirePDB), so stricter expectations always win.
+
+Use --record to append each run to a local JSONL history file (the unhealthy
+resources, the names of the healthy ones and the per-cluster summaries) and
+--since-last to show only what broke or recovered since the previous
+recorded run of each cluster. Combine both when running mcheck on a
+schedule, and use "mcheck history" to read the recorded runs as a changelog.
+Runs are compared per cluster: unhealthy resources missing from the next run,
+deleted or left out by different namespace or resource kind flags, are
+reported as GONE. --since-last prints a table and cannot be combined with -o.
+Only the latest --history-keep runs are kept in the history file.
+
+Use -o junit for a JUnit XML report (a testsuite per cluster and a testcase
+per checked resource, healthy ones included) or -o sarif for a SARIF log
+with the unhealthy resources, so CI systems can render the results.`,
 	Example: `  # Check all resources across clusters (all namespaces, only unhealthy)
   kubectl eks mcheck
 
@@ -46,8 +139,26 @@ Use -n to check a specific namespace, use --all to show healthy resources too.`,
   # Check specific resource types
   kubectl eks mcheck --pods --deployments
 
+  # Check CRDs that follow the standard conditions
+  kubectl eks mcheck --kinds certificates,externalsecrets,rollouts,nodeclaims
+
   # Summary only (no individual resources)
-  kubectl eks mcheck --summary`,
+  kubectl eks mcheck --summary
+
+  # Post-deploy gate: wait up to 10 minutes for deployments to become ready
+  kubectl eks mcheck -n shop --deployments --wait --timeout 10m
+
+  # Apply a team health policy
+  kubectl eks mcheck --policy policy.yaml
+
+  # Record this run and show what changed since the previous one
+  kubectl eks mcheck --record --since-last
+
+  # JUnit report for CI
+  kubectl eks mcheck --name-contains prod -o junit > mcheck.xml
+
+  # Only fail on unhealthy deployments or more than 5 unhealthy pods
+  kubectl eks mcheck --fail-on 'deployments>0,pods>5'`,
 	Run: func(cmd *cobra.Command, args []string) {
 		refresh, _ := cmd.Flags().GetBool("refresh")
 		profile, _ := cmd.Flags().GetString("profile")
@@ -60,17 +171,83 @@ Use -n to check a specific namespace, use --all to show healthy resources too.`,
 		showAll, _ := cmd.Flags().GetBool("all")
 		summaryOnly, _ := cmd.Flags().GetBool("summary")
 		noHeaders, _ := cmd.Flags().GetBool("no-headers")
+		output, _ := cmd.Flags().GetString("output")
 
 		checkPods, _ := cmd.Flags().GetBool("pods")
 		checkDeploys, _ := cmd.Flags().GetBool("deployments")
 		checkSts, _ := cmd.Flags().GetBool("statefulsets")
 		checkDs, _ := cmd.Flags().GetBool("daemonsets")
 		checkRs, _ := cmd.Flags().GetBool("replicasets")
+		checkJobs, _ := cmd.Flags().GetBool("jobs")
+		checkCronJobs, _ := cmd.Flags().GetBool("cronjobs")
+		checkPVCs, _ := cmd.Flags().GetBool("pvcs")
+		checkPDBs, _ := cmd.Flags().GetBool("pdbs")
+		checkHPAs, _ := cmd.Flags().GetBool("hpas")
+		checkServices, _ := cmd.Flags().GetBool("services")
+		customKinds, _ := cmd.Flags().GetStringSlice("kinds")
+		maxRestarts, _ := cmd.Flags().GetInt32("max-restarts")
+		restartWindow, _ := cmd.Flags().GetDuration("restart-window")
+		restarts := restartThreshold{max: maxRestarts, window: restartWindow}
+
+		failOn, _ := cmd.Flags().GetString("fail-on")
+		wait, _ := cmd.Flags().GetBool("wait")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		policyFile, _ := cmd.Flags().GetString("policy")
+		record, _ := cmd.Flags().GetBool("record")
+		sinceLast, _ := cmd.Flags().GetBool("since-last")
+		historyFile, _ := cmd.Flags().GetString("history-file")
+		historyKeep, _ := cmd.Flags().GetInt("history-keep")
 
 		// If no specific types requested, check all
-		checkAllTypes := !checkPods && !checkDeploys && !checkSts && !checkDs && !checkRs
-		if checkAllTypes {
-			checkPods, checkDeploys, checkSts, checkDs, checkRs = true, true, true, true, true
+		checks := healthChecks{
+			pods:         checkPods,
+			deployments:  checkDeploys,
+			statefulsets: checkSts,
+			daemonsets:   checkDs,
+			replicasets:  checkRs,
+			jobs:         checkJobs,
+			cronjobs:     checkCronJobs,
+			pvcs:         checkPVCs,
+			pdbs:         checkPDBs,
+			hpas:         checkHPAs,
+			services:     checkServices,
+		}
+		if checks == (healthChecks{}) && len(customKinds) == 0 {
+			checks = allHealthChecks
+		}
+
+		if output != "" && output != "junit" && output != "sarif" {
+			log.Fatalf("Output format %q is not supported (use junit or sarif)", output)
+		}
+		if sinceLast && output != "" {
+			log.Fatalf("--since-last prints a table of changes and cannot be combined with -o %s", output)
+		}
+		if historyKeep < 0 {
+			log.Fatalf("--history-keep must be 0 or more, got %d", historyKeep)
+		}
+
+		thresholds, err := parseFailOnThresholds(failOn)
+		if err != nil {
+			log.Fatalf("Invalid --fail-on value: %v", err)
+		}
+
+		var policy *healthpolicy.Policy
+		if policyFile != "" {
+			policy, err = healthpolicy.Load(policyFile)
+			if err != nil {
+				log.Fatalf("Error loading policy %s: %v", policyFile, err)
+			}
+			restarts = policyRestartThreshold(restarts, policy, cmd.Flags().Changed("max-restarts"))
+		}
+
+		historyFile = healthHistoryPath(historyFile)
+		var history []data.HealthSnapshot
+		if sinceLast {
+			history, err = healthhistory.Load(historyFile)
+			if err != nil {
+				log.Fatalf("Error loading health history: %v", err)
+			}
 		}
 
 		clusterList, err := LoadClusterList([]string{}, profile, profileContains, nameContains, nameNotContains, region, version, refresh)
@@ -84,81 +261,55 @@ Use -n to check a specific namespace, use --all to show healthy resources too.`,
 			log.Fatalf("Error loading kubeconfig: %v", err)
 		}
 		previousContext := config.CurrentContext
-		defer func() {
+		restoreContext := func() {
 			config.CurrentContext = previousContext
 			clientcmd.ModifyConfig(loadingRules, *config, true)
-		}()
-
-		allResults := []data.HealthCheckResult{}
-		clusterSummaries := []data.ClusterHealthSummary{}
-
-		for _, clusterInfo := range clusterList {
-			err := eks.UpdateKubeConfig(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName, "")
-			if err != nil {
-				log.Printf("Warning: Failed to update kubeconfig for cluster %s: %v", clusterInfo.ClusterName, err)
-				continue
-			}
+		}
+		defer restoreContext()
 
-			clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-				clientcmd.NewDefaultClientConfigLoadingRules(),
-				&clientcmd.ConfigOverrides{},
-			)
+		deadline := time.Now().Add(timeout)
+		var allResults []data.HealthCheckResult
+		var clusterSummaries []data.ClusterHealthSummary
+		var violations []string
 
-			restConfig, err := clientConfig.ClientConfig()
-			if err != nil {
-				continue
-			}
+		for {
+			allResults, clusterSummaries = runHealthChecks(clusterList, namespace, checks, customKinds, restarts, policy)
+			violations = thresholdViolations(clusterSummaries, thresholds)
 
-			clientset, err := kubernetes.NewForConfig(restConfig)
-			if err != nil {
-				continue
+			if !wait || (len(violations) == 0 && !hasClusterErrors(clusterSummaries)) {
+				break
 			}
-
-			namespaces := []string{}
-			if namespace != "" {
-				// If specific namespace provided, use only that
-				namespaces = append(namespaces, namespace)
-			} else {
-				// Default: check all namespaces
-				nsList, err := clientset.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{})
-				if err == nil {
-					for _, ns := range nsList.Items {
-						namespaces = append(namespaces, ns.Name)
-					}
-				}
+			if !time.Now().Add(interval).Before(deadline) {
+				log.Printf("Warning: Timed out after %s waiting for resources to become healthy", timeout)
+				break
 			}
+			log.Printf("Waiting for resources to become healthy (%s), checking again in %s", strings.Join(append(violations, clusterErrorSummary(clusterSummaries)...), "; "), interval)
+			time.Sleep(interval)
+		}
 
-			clusterResults := []data.HealthCheckResult{}
-
-			for _, ns := range namespaces {
-				if checkPods {
-					results := checkPodsHealth(clientset, clusterInfo, ns)
-					clusterResults = append(clusterResults, results...)
-				}
-				if checkDeploys {
-					results := checkDeploymentsHealth(clientset, clusterInfo, ns)
-					clusterResults = append(clusterResults, results...)
-				}
-				if checkSts {
-					results := checkStatefulSetsHealth(clientset, clusterInfo, ns)
-					clusterResults = append(clusterResults, results...)
-				}
-				if checkDs {
-					results := checkDaemonSetsHealth(clientset, clusterInfo, ns)
-					clusterResults = append(clusterResults, results...)
-				}
-				if checkRs {
-					results := checkReplicaSetsHealth(clientset, clusterInfo, ns)
-					clusterResults = append(clusterResults, results...)
-				}
+		snapshot := healthhistory.NewSnapshot(time.Now(), allResults, clusterSummaries)
+		if record {
+			if err := healthhistory.Append(historyFile, snapshot, historyKeep); err != nil {
+				log.Printf("Warning: Unable to record health snapshot in %s: %v", historyFile, err)
 			}
-
-			summary := summarizeResults(clusterInfo, clusterResults)
-			clusterSummaries = append(clusterSummaries, summary)
-			allResults = append(allResults, clusterResults...)
 		}
 
-		if summaryOnly {
+		if output == "junit" {
+			if err := printutils.PrintHealthJUnit(os.Stdout, allResults, clusterSummaries); err != nil {
+				log.Fatalf("Error writing JUnit report: %v", err)
+			}
+		} else if output == "sarif" {
+			if err := printutils.PrintHealthSARIF(os.Stdout, allResults, clusterSummaries); err != nil {
+				log.Fatalf("Error writing SARIF report: %v", err)
+			}
+		} else if sinceLast {
+			changes := healthhistory.Compare(history, snapshot)
+			if len(changes) == 0 {
+				fmt.Fprintln(os.Stderr, "No resources broke or recovered since the previous run")
+			} else {
+				printutils.PrintHealthChanges(noHeaders, changes)
+			}
+		} else if summaryOnly {
 			printutils.PrintHealthSummary(noHeaders, clusterSummaries)
 		} else {
 			filteredResults := allResults
@@ -174,18 +325,212 @@ Use -n to check a specific namespace, use --all to show healthy resources too.`,
 		}
 
 		saveCacheToDisk()
+
+		exitCode := healthExitCode(violations, clusterSummaries)
+		if exitCode != exitCodeHealthy {
+			// os.Exit skips deferred calls
+			restoreContext()
+			os.Exit(exitCode)
+		}
 	},
 }
 
-func checkPodsHealth(clientset *kubernetes.Clientset, cluster data.ClusterInfo, namespace string) []data.HealthCheckResult {
+// healthChecks selects the resource kinds mcheck looks at
+type healthChecks struct {
+	pods         bool
+	deployments  bool
+	statefulsets bool
+	daemonsets   bool
+	replicasets  bool
+	jobs         bool
+	cronjobs     bool
+	pvcs         bool
+	pdbs         bool
+	hpas         bool
+	services     bool
+}
+
+// allHealthChecks is used when no kind is selected
+var allHealthChecks = healthChecks{
+	pods:         true,
+	deployments:  true,
+	statefulsets: true,
+	daemonsets:   true,
+	replicasets:  true,
+	jobs:         true,
+	cronjobs:     true,
+	pvcs:         true,
+	pdbs:         true,
+	hpas:         true,
+	services:     true,
+}
+
+// runHealthChecks checks every cluster once and returns the individual
+// results together with a summary per cluster. customKinds are resource types
+// checked with the generic readiness evaluator. Clusters that cannot be
+// reached, or kinds that cannot be listed, are recorded in the summary Errors.
+func runHealthChecks(clusterList []data.ClusterInfo, namespace string, checks healthChecks, customKinds []string, restarts restartThreshold, policy *healthpolicy.Policy) ([]data.HealthCheckResult, []data.ClusterHealthSummary) {
+	allResults := []data.HealthCheckResult{}
+	clusterSummaries := []data.ClusterHealthSummary{}
+
+	for _, clusterInfo := range clusterList {
+		restConfig, err := clusterRestConfig(clusterInfo)
+		if err != nil {
+			clusterSummaries = append(clusterSummaries, unreachableClusterSummary(clusterInfo, err))
+			continue
+		}
+
+		results, summary := checkClusterHealth(clusterInfo, restConfig, namespace, checks, customKinds, restarts, policy)
+		clusterSummaries = append(clusterSummaries, summary)
+		allResults = append(allResults, results...)
+	}
+
+	return allResults, clusterSummaries
+}
+
+// unreachableClusterSummary is the summary of a cluster that could not be checked at all
+func unreachableClusterSummary(clusterInfo data.ClusterInfo, err error) data.ClusterHealthSummary {
+	log.Printf("Warning: Unable to check cluster %s: %v", clusterInfo.ClusterName, err)
+	summary := summarizeResults(clusterInfo, nil)
+	summary.Errors = []string{err.Error()}
+	summary.OverallStatus = "Error"
+	return summary
+}
+
+// checkClusterHealth runs the health checks on a single cluster through
+// restConfig. It does not touch the kubeconfig, so clusters can be checked
+// concurrently.
+func checkClusterHealth(clusterInfo data.ClusterInfo, restConfig *rest.Config, namespace string, checks healthChecks, customKinds []string, restarts restartThreshold, policy *healthpolicy.Policy) ([]data.HealthCheckResult, data.ClusterHealthSummary) {
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, unreachableClusterSummary(clusterInfo, err)
+	}
+
+	clusterResults := []data.HealthCheckResult{}
+	inaccessible := []string{}
+	errs := []string{}
+
+	type checkFunc func(kubernetes.Interface, data.ClusterInfo, string) ([]data.HealthCheckResult, []string, error)
+	runCheck := func(kind string, check checkFunc) {
+		results, denied, err := check(clientset, clusterInfo, namespace)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", kind, err))
+		}
+		clusterResults = append(clusterResults, results...)
+		inaccessible = append(inaccessible, denied...)
+	}
+
+	if checks.pods {
+		runCheck("pods", func(clientset kubernetes.Interface, cluster data.ClusterInfo, namespace string) ([]data.HealthCheckResult, []string, error) {
+			return checkPodsHealth(clientset, cluster, namespace, restarts)
+		})
+	}
+	if checks.deployments {
+		runCheck("deployments", checkDeploymentsHealth)
+	}
+	if checks.statefulsets {
+		runCheck("statefulsets", checkStatefulSetsHealth)
+	}
+	if checks.daemonsets {
+		runCheck("daemonsets", checkDaemonSetsHealth)
+	}
+	if checks.replicasets {
+		runCheck("replicasets", checkReplicaSetsHealth)
+	}
+	if checks.jobs {
+		runCheck("jobs", checkJobsHealth)
+	}
+	if checks.cronjobs {
+		runCheck("cronjobs", checkCronJobsHealth)
+	}
+	if checks.pvcs {
+		runCheck("persistentvolumeclaims", checkPVCsHealth)
+	}
+	if checks.pdbs {
+		runCheck("poddisruptionbudgets", checkPDBsHealth)
+	}
+	if checks.hpas {
+		runCheck("horizontalpodautoscalers", checkHPAsHealth)
+	}
+	if checks.services {
+		runCheck("services", checkServicesHealth)
+	}
+	if len(customKinds) > 0 {
+		results, denied, customErrs := checkCustomKinds(restConfig, clientset, clusterInfo, namespace, customKinds)
+		clusterResults = append(clusterResults, results...)
+		inaccessible = append(inaccessible, denied...)
+		errs = append(errs, customErrs...)
+	}
+
+	if policy != nil {
+		pdbs := []policyv1.PodDisruptionBudget{}
+		if policy.RequiresPDBs() {
+			var denied []string
+			var err error
+			pdbs, denied, err = listPodDisruptionBudgets(clientset, namespace)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("poddisruptionbudgets: %v", err))
+			}
+			inaccessible = append(inaccessible, denied...)
+		}
+		clusterResults = policy.Evaluate(clusterResults, pdbs, time.Now())
+	}
+
+	summary := summarizeResults(clusterInfo, clusterResults)
+	summary.InaccessibleNamespaces = uniqueSortedStrings(inaccessible)
+	if len(summary.InaccessibleNamespaces) > 0 {
+		log.Printf("Warning: Unable to list resources in cluster %s for namespaces: %s", clusterInfo.ClusterName, strings.Join(summary.InaccessibleNamespaces, ", "))
+	}
+	if len(errs) > 0 {
+		log.Printf("Warning: Unable to check resources in cluster %s: %s", clusterInfo.ClusterName, strings.Join(errs, "; "))
+		summary.Errors = errs
+		summary.OverallStatus = fmt.Sprintf("%s (%d checks failed)", summary.OverallStatus, len(errs))
+	}
+	return clusterResults, summary
+}
+
+func listPodDisruptionBudgets(clientset kubernetes.Interface, namespace string) ([]policyv1.PodDisruptionBudget, []string, error) {
+	return k8s.ListNamespaced(context.Background(), clientset, namespace, func(ctx context.Context, ns string) ([]policyv1.PodDisruptionBudget, error) {
+		list, err := clientset.PolicyV1().PodDisruptionBudgets(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	})
+}
+
+// clusterRestConfig switches the kubeconfig to the cluster and returns a
+// rest config for it
+func clusterRestConfig(clusterInfo data.ClusterInfo) (*rest.Config, error) {
+	err := eks.UpdateKubeConfig(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to update kubeconfig: %w", err)
+	}
+
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{},
+	)
+
+	return clientConfig.ClientConfig()
+}
+
+func checkPodsHealth(clientset kubernetes.Interface, cluster data.ClusterInfo, namespace string, restarts restartThreshold) ([]data.HealthCheckResult, []string, error) {
 	results := []data.HealthCheckResult{}
 
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{})
+	pods, inaccessible, err := k8s.ListNamespaced(context.Background(), clientset, namespace, func(ctx context.Context, ns string) ([]corev1.Pod, error) {
+		list, err := clientset.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	})
 	if err != nil {
-		return results
+		return results, nil, err
 	}
 
-	for _, pod := range pods.Items {
+	now := time.Now()
+	for _, pod := range pods {
 		result := data.HealthCheckResult{
 			Profile:     cluster.AWSProfile,
 			Region:      cluster.Region,
@@ -193,40 +538,172 @@ func checkPodsHealth(clientset *kubernetes.Clientset, cluster data.ClusterInfo,
 			Namespace:   pod.Namespace,
 			Kind:        "Pod",
 			Name:        pod.Name,
+			Labels:      pod.Labels,
+			CreatedAt:   pod.CreationTimestamp.Time,
 		}
 
-		phase := string(pod.Status.Phase)
 		ready, total := countReadyContainers(pod)
 		result.Ready = fmt.Sprintf("%d/%d", ready, total)
-		result.Status = phase
+		result.RecentRestarts = recentRestarts(pod, now, restarts.window)
+		result.Status = string(pod.Status.Phase)
+		result.IsHealthy, result.Message = evaluatePodHealth(pod, now, restarts)
+		result.Starting = podStarting(pod, now, restarts)
 
-		// Completed/Succeeded pods are healthy
-		if phase == string(corev1.PodSucceeded) {
-			result.IsHealthy = true
-			result.Message = "Completed"
-		} else if phase == string(corev1.PodRunning) {
-			if ready == total && total > 0 {
-				result.IsHealthy = true
-				result.Message = "All containers ready"
-			} else {
-				result.IsHealthy = false
-				result.Message = fmt.Sprintf("Containers not ready: %d/%d", ready, total)
+		results = append(results, result)
+	}
+
+	return results, inaccessible, nil
+}
+
+// restartThreshold marks pods as restart-heavy: a container restarted more
+// than max times in total whose last termination finished within window.
+// Restart counts are cumulative, the window only keeps containers that have
+// since been stable from being flagged. The policy pods.maxRestarts uses the
+// same definition.
+type restartThreshold struct {
+	max    int32
+	window time.Duration
+}
+
+// policyRestartThreshold uses the policy pods.maxRestarts as the restart
+// threshold, so pods below it are not flagged, unless --max-restarts was
+// given explicitly
+func policyRestartThreshold(restarts restartThreshold, policy *healthpolicy.Policy, flagChanged bool) restartThreshold {
+	if policy != nil && policy.Pods.MaxRestarts != nil && !flagChanged {
+		restarts.max = *policy.Pods.MaxRestarts
+	}
+	return restarts
+}
+
+// defaultRestartThreshold matches the --max-restarts and --restart-window defaults
+var defaultRestartThreshold = restartThreshold{max: 5, window: time.Hour}
+
+// evaluatePodHealth decides whether a pod is healthy. Besides the pod phase
+// it looks at every init, regular and ephemeral container for crash loops,
+// image pull errors, recent OOM kills and restart-heavy containers.
+func evaluatePodHealth(pod corev1.Pod, now time.Time, restarts restartThreshold) (bool, string) {
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded:
+		return true, "Completed"
+	case corev1.PodFailed:
+		message := getPodFailedReason(pod)
+		for _, cs := range allContainerStatuses(pod) {
+			if cs.State.Terminated != nil && cs.State.Terminated.ExitCode != 0 {
+				message = fmt.Sprintf("%s (container %s exit code %d)", message, cs.Name, cs.State.Terminated.ExitCode)
+				break
 			}
-		} else if phase == string(corev1.PodPending) {
-			result.IsHealthy = false
-			result.Message = getPodPendingReason(pod)
-		} else if phase == string(corev1.PodFailed) {
-			result.IsHealthy = false
-			result.Message = getPodFailedReason(pod)
-		} else {
-			result.IsHealthy = false
-			result.Message = fmt.Sprintf("Unknown phase: %s", phase)
 		}
+		return false, message
+	}
 
-		results = append(results, result)
+	if problem := getContainerProblem(pod, now, restarts); problem != "" {
+		return false, problem
+	}
+
+	switch pod.Status.Phase {
+	case corev1.PodRunning:
+		ready, total := countReadyContainers(pod)
+		if ready == total && total > 0 {
+			return true, "All containers ready"
+		}
+		return false, fmt.Sprintf("Containers not ready: %d/%d", ready, total)
+	case corev1.PodPending:
+		return false, getPodPendingReason(pod)
+	default:
+		return false, fmt.Sprintf("Unknown phase: %s", pod.Status.Phase)
+	}
+}
+
+// startingReasons are the waiting reasons of containers that are still being
+// created
+var startingReasons = map[string]bool{
+	"":                  true,
+	"ContainerCreating": true,
+	"PodInitializing":   true,
+}
+
+// podStarting reports whether a Pending pod is only waiting to be scheduled
+// or for its containers to be created, the pods the policy maxPendingAge
+// tolerates. Pods with container errors, such as image pull errors or a
+// failed init container, are not starting.
+func podStarting(pod corev1.Pod, now time.Time, restarts restartThreshold) bool {
+	if pod.Status.Phase != corev1.PodPending || getContainerProblem(pod, now, restarts) != "" {
+		return false
+	}
+	for _, cs := range allContainerStatuses(pod) {
+		if cs.State.Waiting != nil && !startingReasons[cs.State.Waiting.Reason] {
+			return false
+		}
+		if cs.State.Terminated != nil && cs.State.Terminated.ExitCode != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// imagePullReasons are the waiting reasons of containers whose image cannot be pulled
+var imagePullReasons = map[string]bool{
+	"ImagePullBackOff":  true,
+	"ErrImagePull":      true,
+	"InvalidImageName":  true,
+	"ErrImageNeverPull": true,
+}
+
+// getContainerProblem returns the most relevant container problem of a pod, or
+// an empty string. Crash loops and image pull errors come first, then OOM
+// kills and restart-heavy containers within the restart window.
+func getContainerProblem(pod corev1.Pod, now time.Time, restarts restartThreshold) string {
+	statuses := allContainerStatuses(pod)
+
+	for _, cs := range statuses {
+		if cs.State.Waiting == nil {
+			continue
+		}
+		reason := cs.State.Waiting.Reason
+		if reason == "CrashLoopBackOff" {
+			return fmt.Sprintf("CrashLoopBackOff: container %s restarted %d times%s", cs.Name, cs.RestartCount, lastTermination(cs))
+		}
+		if imagePullReasons[reason] {
+			message := fmt.Sprintf("%s: container %s", reason, cs.Name)
+			if cs.State.Waiting.Message != "" {
+				message = fmt.Sprintf("%s: %s", message, cs.State.Waiting.Message)
+			}
+			return message
+		}
+	}
+
+	for _, cs := range statuses {
+		if !recentlyTerminated(cs, now, restarts.window) {
+			continue
+		}
+		terminated := cs.LastTerminationState.Terminated
+		if terminated.Reason == "OOMKilled" {
+			return fmt.Sprintf("OOMKilled: container %s was OOM killed %s ago%s", cs.Name, now.Sub(terminated.FinishedAt.Time).Truncate(time.Second), lastTermination(cs))
+		}
+		if cs.RestartCount > restarts.max {
+			return fmt.Sprintf("Restarting: container %s restarted %d times, last %s ago%s", cs.Name, cs.RestartCount, now.Sub(terminated.FinishedAt.Time).Truncate(time.Second), lastTermination(cs))
+		}
 	}
 
-	return results
+	return ""
+}
+
+// allContainerStatuses returns the init, regular and ephemeral container statuses
+func allContainerStatuses(pod corev1.Pod) []corev1.ContainerStatus {
+	statuses := []corev1.ContainerStatus{}
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+	statuses = append(statuses, pod.Status.EphemeralContainerStatuses...)
+	return statuses
+}
+
+// lastTermination describes the previous termination of a container
+func lastTermination(cs corev1.ContainerStatus) string {
+	terminated := cs.LastTerminationState.Terminated
+	if terminated == nil {
+		return ""
+	}
+	return fmt.Sprintf(" (last terminated: %s, exit code %d)", terminated.Reason, terminated.ExitCode)
 }
 
 func countReadyContainers(pod corev1.Pod) (int, int) {
@@ -240,6 +717,25 @@ func countReadyContainers(pod corev1.Pod) (int, int) {
 	return ready, total
 }
 
+// recentlyTerminated reports whether the last termination of a container
+// finished within window
+func recentlyTerminated(cs corev1.ContainerStatus, now time.Time, window time.Duration) bool {
+	terminated := cs.LastTerminationState.Terminated
+	return terminated != nil && !terminated.FinishedAt.IsZero() && now.Sub(terminated.FinishedAt.Time) <= window
+}
+
+// recentRestarts returns the highest restart count among the containers of
+// a pod last terminated within window, the value --max-restarts is compared to
+func recentRestarts(pod corev1.Pod, now time.Time, window time.Duration) int32 {
+	restarts := int32(0)
+	for _, cs := range allContainerStatuses(pod) {
+		if recentlyTerminated(cs, now, window) && cs.RestartCount > restarts {
+			restarts = cs.RestartCount
+		}
+	}
+	return restarts
+}
+
 func getPodPendingReason(pod corev1.Pod) string {
 	for _, cond := range pod.Status.Conditions {
 		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse {
@@ -266,22 +762,31 @@ func getPodFailedReason(pod corev1.Pod) string {
 	return "Failed"
 }
 
-func checkDeploymentsHealth(clientset *kubernetes.Clientset, cluster data.ClusterInfo, namespace string) []data.HealthCheckResult {
+func checkDeploymentsHealth(clientset kubernetes.Interface, cluster data.ClusterInfo, namespace string) ([]data.HealthCheckResult, []string, error) {
 	results := []data.HealthCheckResult{}
 
-	deploys, err := clientset.AppsV1().Deployments(namespace).List(context.Background(), metav1.ListOptions{})
+	deploys, inaccessible, err := k8s.ListNamespaced(context.Background(), clientset, namespace, func(ctx context.Context, ns string) ([]appsv1.Deployment, error) {
+		list, err := clientset.AppsV1().Deployments(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	})
 	if err != nil {
-		return results
+		return results, nil, err
 	}
 
-	for _, deploy := range deploys.Items {
+	for _, deploy := range deploys {
 		result := data.HealthCheckResult{
-			Profile:     cluster.AWSProfile,
-			Region:      cluster.Region,
-			ClusterName: cluster.ClusterName,
-			Namespace:   deploy.Namespace,
-			Kind:        "Deployment",
-			Name:        deploy.Name,
+			Profile:           cluster.AWSProfile,
+			Region:            cluster.Region,
+			ClusterName:       cluster.ClusterName,
+			Namespace:         deploy.Namespace,
+			Kind:              "Deployment",
+			Name:              deploy.Name,
+			Labels:            deploy.Labels,
+			PodTemplateLabels: deploy.Spec.Template.Labels,
+			CreatedAt:         deploy.CreationTimestamp.Time,
 		}
 
 		desired := int32(1)
@@ -293,6 +798,7 @@ func checkDeploymentsHealth(clientset *kubernetes.Clientset, cluster data.Cluste
 		upToDate := deploy.Status.UpdatedReplicas
 
 		result.Ready = fmt.Sprintf("%d/%d", ready, desired)
+		result.DesiredReplicas, result.ReadyReplicas = desired, ready
 		result.Status = fmt.Sprintf("Available:%d UpToDate:%d", available, upToDate)
 
 		if ready == desired && available == desired && upToDate == desired {
@@ -306,7 +812,7 @@ func checkDeploymentsHealth(clientset *kubernetes.Clientset, cluster data.Cluste
 		results = append(results, result)
 	}
 
-	return results
+	return results, inaccessible, nil
 }
 
 func getDeploymentConditionMessage(deploy appsv1.Deployment) string {
@@ -325,22 +831,31 @@ func getDeploymentConditionMessage(deploy appsv1.Deployment) string {
 	return fmt.Sprintf("Ready %d/%d", deploy.Status.ReadyReplicas, desired)
 }
 
-func checkStatefulSetsHealth(clientset *kubernetes.Clientset, cluster data.ClusterInfo, namespace string) []data.HealthCheckResult {
+func checkStatefulSetsHealth(clientset kubernetes.Interface, cluster data.ClusterInfo, namespace string) ([]data.HealthCheckResult, []string, error) {
 	results := []data.HealthCheckResult{}
 
-	stsList, err := clientset.AppsV1().StatefulSets(namespace).List(context.Background(), metav1.ListOptions{})
+	stsList, inaccessible, err := k8s.ListNamespaced(context.Background(), clientset, namespace, func(ctx context.Context, ns string) ([]appsv1.StatefulSet, error) {
+		list, err := clientset.AppsV1().StatefulSets(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	})
 	if err != nil {
-		return results
+		return results, nil, err
 	}
 
-	for _, sts := range stsList.Items {
+	for _, sts := range stsList {
 		result := data.HealthCheckResult{
-			Profile:     cluster.AWSProfile,
-			Region:      cluster.Region,
-			ClusterName: cluster.ClusterName,
-			Namespace:   sts.Namespace,
-			Kind:        "StatefulSet",
-			Name:        sts.Name,
+			Profile:           cluster.AWSProfile,
+			Region:            cluster.Region,
+			ClusterName:       cluster.ClusterName,
+			Namespace:         sts.Namespace,
+			Kind:              "StatefulSet",
+			Name:              sts.Name,
+			Labels:            sts.Labels,
+			PodTemplateLabels: sts.Spec.Template.Labels,
+			CreatedAt:         sts.CreationTimestamp.Time,
 		}
 
 		desired := int32(1)
@@ -350,6 +865,7 @@ func checkStatefulSetsHealth(clientset *kubernetes.Clientset, cluster data.Clust
 		ready := sts.Status.ReadyReplicas
 
 		result.Ready = fmt.Sprintf("%d/%d", ready, desired)
+		result.DesiredReplicas, result.ReadyReplicas = desired, ready
 		result.Status = fmt.Sprintf("CurrentRevision:%s", sts.Status.CurrentRevision)
 
 		if ready == desired {
@@ -363,25 +879,34 @@ func checkStatefulSetsHealth(clientset *kubernetes.Clientset, cluster data.Clust
 		results = append(results, result)
 	}
 
-	return results
+	return results, inaccessible, nil
 }
 
-func checkDaemonSetsHealth(clientset *kubernetes.Clientset, cluster data.ClusterInfo, namespace string) []data.HealthCheckResult {
+func checkDaemonSetsHealth(clientset kubernetes.Interface, cluster data.ClusterInfo, namespace string) ([]data.HealthCheckResult, []string, error) {
 	results := []data.HealthCheckResult{}
 
-	dsList, err := clientset.AppsV1().DaemonSets(namespace).List(context.Background(), metav1.ListOptions{})
+	dsList, inaccessible, err := k8s.ListNamespaced(context.Background(), clientset, namespace, func(ctx context.Context, ns string) ([]appsv1.DaemonSet, error) {
+		list, err := clientset.AppsV1().DaemonSets(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	})
 	if err != nil {
-		return results
+		return results, nil, err
 	}
 
-	for _, ds := range dsList.Items {
+	for _, ds := range dsList {
 		result := data.HealthCheckResult{
-			Profile:     cluster.AWSProfile,
-			Region:      cluster.Region,
-			ClusterName: cluster.ClusterName,
-			Namespace:   ds.Namespace,
-			Kind:        "DaemonSet",
-			Name:        ds.Name,
+			Profile:           cluster.AWSProfile,
+			Region:            cluster.Region,
+			ClusterName:       cluster.ClusterName,
+			Namespace:         ds.Namespace,
+			Kind:              "DaemonSet",
+			Name:              ds.Name,
+			Labels:            ds.Labels,
+			PodTemplateLabels: ds.Spec.Template.Labels,
+			CreatedAt:         ds.CreationTimestamp.Time,
 		}
 
 		desired := ds.Status.DesiredNumberScheduled
@@ -389,6 +914,7 @@ func checkDaemonSetsHealth(clientset *kubernetes.Clientset, cluster data.Cluster
 		available := ds.Status.NumberAvailable
 
 		result.Ready = fmt.Sprintf("%d/%d", ready, desired)
+		result.DesiredReplicas, result.ReadyReplicas = desired, ready
 		result.Status = fmt.Sprintf("Available:%d Unavailable:%d", available, ds.Status.NumberUnavailable)
 
 		if ready == desired && available == desired {
@@ -402,18 +928,24 @@ func checkDaemonSetsHealth(clientset *kubernetes.Clientset, cluster data.Cluster
 		results = append(results, result)
 	}
 
-	return results
+	return results, inaccessible, nil
 }
 
-func checkReplicaSetsHealth(clientset *kubernetes.Clientset, cluster data.ClusterInfo, namespace string) []data.HealthCheckResult {
+func checkReplicaSetsHealth(clientset kubernetes.Interface, cluster data.ClusterInfo, namespace string) ([]data.HealthCheckResult, []string, error) {
 	results := []data.HealthCheckResult{}
 
-	rsList, err := clientset.AppsV1().ReplicaSets(namespace).List(context.Background(), metav1.ListOptions{})
+	rsList, inaccessible, err := k8s.ListNamespaced(context.Background(), clientset, namespace, func(ctx context.Context, ns string) ([]appsv1.ReplicaSet, error) {
+		list, err := clientset.AppsV1().ReplicaSets(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	})
 	if err != nil {
-		return results
+		return results, nil, err
 	}
 
-	for _, rs := range rsList.Items {
+	for _, rs := range rsList {
 		// Skip ReplicaSets with 0 desired (old revisions from deployments)
 		desired := int32(0)
 		if rs.Spec.Replicas != nil {
@@ -424,17 +956,21 @@ func checkReplicaSetsHealth(clientset *kubernetes.Clientset, cluster data.Cluste
 		}
 
 		result := data.HealthCheckResult{
-			Profile:     cluster.AWSProfile,
-			Region:      cluster.Region,
-			ClusterName: cluster.ClusterName,
-			Namespace:   rs.Namespace,
-			Kind:        "ReplicaSet",
-			Name:        rs.Name,
+			Profile:           cluster.AWSProfile,
+			Region:            cluster.Region,
+			ClusterName:       cluster.ClusterName,
+			Namespace:         rs.Namespace,
+			Kind:              "ReplicaSet",
+			Name:              rs.Name,
+			Labels:            rs.Labels,
+			PodTemplateLabels: rs.Spec.Template.Labels,
+			CreatedAt:         rs.CreationTimestamp.Time,
 		}
 
 		ready := rs.Status.ReadyReplicas
 
 		result.Ready = fmt.Sprintf("%d/%d", ready, desired)
+		result.DesiredReplicas, result.ReadyReplicas = desired, ready
 		result.Status = fmt.Sprintf("Replicas:%d", rs.Status.Replicas)
 
 		if ready == desired {
@@ -448,7 +984,367 @@ func checkReplicaSetsHealth(clientset *kubernetes.Clientset, cluster data.Cluste
 		results = append(results, result)
 	}
 
-	return results
+	return results, inaccessible, nil
+}
+
+func checkJobsHealth(clientset kubernetes.Interface, cluster data.ClusterInfo, namespace string) ([]data.HealthCheckResult, []string, error) {
+	results := []data.HealthCheckResult{}
+
+	jobs, inaccessible, err := k8s.ListNamespaced(context.Background(), clientset, namespace, func(ctx context.Context, ns string) ([]batchv1.Job, error) {
+		list, err := clientset.BatchV1().Jobs(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	})
+	if err != nil {
+		return results, nil, err
+	}
+
+	for _, job := range jobs {
+		result := data.HealthCheckResult{
+			Profile:     cluster.AWSProfile,
+			Region:      cluster.Region,
+			ClusterName: cluster.ClusterName,
+			Namespace:   job.Namespace,
+			Kind:        "Job",
+			Name:        job.Name,
+			Labels:      job.Labels,
+			CreatedAt:   job.CreationTimestamp.Time,
+		}
+
+		completions := int32(1)
+		if job.Spec.Completions != nil {
+			completions = *job.Spec.Completions
+		}
+		result.Ready = fmt.Sprintf("%d/%d", job.Status.Succeeded, completions)
+		result.Status = fmt.Sprintf("Active:%d Failed:%d", job.Status.Active, job.Status.Failed)
+		result.IsHealthy = true
+		result.Message = "Running"
+
+		for _, cond := range job.Status.Conditions {
+			if cond.Status != corev1.ConditionTrue {
+				continue
+			}
+			if cond.Type == batchv1.JobFailed {
+				result.IsHealthy = false
+				result.Message = fmt.Sprintf("Failed: %s", cond.Reason)
+				if cond.Message != "" {
+					result.Message = fmt.Sprintf("Failed: %s: %s", cond.Reason, cond.Message)
+				}
+				break
+			}
+			if cond.Type == batchv1.JobComplete {
+				result.Message = "Completed"
+			}
+			if cond.Type == batchv1.JobSuspended {
+				result.Message = "Suspended"
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results, inaccessible, nil
+}
+
+func checkCronJobsHealth(clientset kubernetes.Interface, cluster data.ClusterInfo, namespace string) ([]data.HealthCheckResult, []string, error) {
+	results := []data.HealthCheckResult{}
+
+	cronJobs, inaccessible, err := k8s.ListNamespaced(context.Background(), clientset, namespace, func(ctx context.Context, ns string) ([]batchv1.CronJob, error) {
+		list, err := clientset.BatchV1().CronJobs(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	})
+	if err != nil {
+		return results, nil, err
+	}
+
+	now := time.Now()
+	for _, cronJob := range cronJobs {
+		result := data.HealthCheckResult{
+			Profile:     cluster.AWSProfile,
+			Region:      cluster.Region,
+			ClusterName: cluster.ClusterName,
+			Namespace:   cronJob.Namespace,
+			Kind:        "CronJob",
+			Name:        cronJob.Name,
+			Labels:      cronJob.Labels,
+			CreatedAt:   cronJob.CreationTimestamp.Time,
+		}
+
+		result.Ready = "-"
+		result.Status = fmt.Sprintf("Active:%d", len(cronJob.Status.Active))
+		if cronJob.Status.LastSuccessfulTime != nil {
+			result.Status = fmt.Sprintf("Active:%d LastSuccess:%s", len(cronJob.Status.Active), cronJob.Status.LastSuccessfulTime.UTC().Format(time.RFC3339))
+		}
+		result.IsHealthy, result.Message = evaluateCronJob(cronJob, now)
+
+		results = append(results, result)
+	}
+
+	return results, inaccessible, nil
+}
+
+// cronJobGracePeriod is how long after a scheduled time a CronJob gets to
+// start its Job before the run counts as missed
+const cronJobGracePeriod = time.Minute
+
+// evaluateCronJob checks that a CronJob succeeded within its schedule: since
+// its last success (or its creation) no scheduled run may have passed without
+// a Job still running for it.
+func evaluateCronJob(cronJob batchv1.CronJob, now time.Time) (bool, string) {
+	if cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend {
+		return true, "Suspended"
+	}
+	if len(cronJob.Status.Active) > 0 {
+		return true, "Running"
+	}
+
+	spec := cronJob.Spec.Schedule
+	if cronJob.Spec.TimeZone != nil && *cronJob.Spec.TimeZone != "" {
+		spec = fmt.Sprintf("CRON_TZ=%s %s", *cronJob.Spec.TimeZone, spec)
+	}
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return false, fmt.Sprintf("Invalid schedule %q: %v", cronJob.Spec.Schedule, err)
+	}
+
+	since := cronJob.CreationTimestamp.Time
+	if cronJob.Status.LastSuccessfulTime != nil && cronJob.Status.LastSuccessfulTime.After(since) {
+		since = cronJob.Status.LastSuccessfulTime.Time
+	}
+
+	grace := cronJobGracePeriod
+	if cronJob.Spec.StartingDeadlineSeconds != nil {
+		grace = time.Duration(*cronJob.Spec.StartingDeadlineSeconds) * time.Second
+	}
+
+	expected := schedule.Next(since)
+	if expected.Add(grace).Before(now) {
+		if cronJob.Status.LastSuccessfulTime == nil {
+			return false, fmt.Sprintf("No successful run, expected one at %s", expected.UTC().Format(time.RFC3339))
+		}
+		return false, fmt.Sprintf("No successful run since %s, expected one at %s", since.UTC().Format(time.RFC3339), expected.UTC().Format(time.RFC3339))
+	}
+
+	if cronJob.Status.LastSuccessfulTime == nil {
+		return true, "Not run yet"
+	}
+	return true, "Succeeded on schedule"
+}
+
+func checkPVCsHealth(clientset kubernetes.Interface, cluster data.ClusterInfo, namespace string) ([]data.HealthCheckResult, []string, error) {
+	results := []data.HealthCheckResult{}
+
+	pvcs, inaccessible, err := k8s.ListNamespaced(context.Background(), clientset, namespace, func(ctx context.Context, ns string) ([]corev1.PersistentVolumeClaim, error) {
+		list, err := clientset.CoreV1().PersistentVolumeClaims(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	})
+	if err != nil {
+		return results, nil, err
+	}
+
+	for _, pvc := range pvcs {
+		result := data.HealthCheckResult{
+			Profile:     cluster.AWSProfile,
+			Region:      cluster.Region,
+			ClusterName: cluster.ClusterName,
+			Namespace:   pvc.Namespace,
+			Kind:        "PersistentVolumeClaim",
+			Name:        pvc.Name,
+			Labels:      pvc.Labels,
+			CreatedAt:   pvc.CreationTimestamp.Time,
+		}
+
+		result.Ready = "-"
+		result.Status = string(pvc.Status.Phase)
+
+		switch pvc.Status.Phase {
+		case corev1.ClaimBound:
+			result.IsHealthy = true
+			result.Message = fmt.Sprintf("Bound to %s", pvc.Spec.VolumeName)
+		case corev1.ClaimPending:
+			result.IsHealthy = false
+			result.Message = "Waiting for a volume to be bound"
+		case corev1.ClaimLost:
+			result.IsHealthy = false
+			result.Message = fmt.Sprintf("Volume %s lost", pvc.Spec.VolumeName)
+		default:
+			result.IsHealthy = false
+			result.Message = fmt.Sprintf("Unknown phase: %s", pvc.Status.Phase)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, inaccessible, nil
+}
+
+func checkPDBsHealth(clientset kubernetes.Interface, cluster data.ClusterInfo, namespace string) ([]data.HealthCheckResult, []string, error) {
+	results := []data.HealthCheckResult{}
+
+	pdbs, inaccessible, err := listPodDisruptionBudgets(clientset, namespace)
+	if err != nil {
+		return results, nil, err
+	}
+
+	for _, pdb := range pdbs {
+		result := data.HealthCheckResult{
+			Profile:     cluster.AWSProfile,
+			Region:      cluster.Region,
+			ClusterName: cluster.ClusterName,
+			Namespace:   pdb.Namespace,
+			Kind:        "PodDisruptionBudget",
+			Name:        pdb.Name,
+			Labels:      pdb.Labels,
+			CreatedAt:   pdb.CreationTimestamp.Time,
+		}
+
+		result.Ready = fmt.Sprintf("%d/%d", pdb.Status.CurrentHealthy, pdb.Status.DesiredHealthy)
+		result.Status = fmt.Sprintf("AllowedDisruptions:%d", pdb.Status.DisruptionsAllowed)
+
+		if pdb.Status.ExpectedPods == 0 {
+			result.IsHealthy = true
+			result.Message = "No matching pods"
+		} else if pdb.Status.CurrentHealthy < pdb.Status.DesiredHealthy {
+			result.IsHealthy = false
+			result.Message = fmt.Sprintf("Not enough healthy pods (healthy %d, desired %d)", pdb.Status.CurrentHealthy, pdb.Status.DesiredHealthy)
+		} else if pdb.Status.DisruptionsAllowed == 0 {
+			// Blocks node drains and upgrades
+			result.IsHealthy = false
+			result.Message = fmt.Sprintf("No disruptions allowed (healthy %d, desired %d)", pdb.Status.CurrentHealthy, pdb.Status.DesiredHealthy)
+		} else {
+			result.IsHealthy = true
+			result.Message = fmt.Sprintf("%d disruptions allowed", pdb.Status.DisruptionsAllowed)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, inaccessible, nil
+}
+
+func checkHPAsHealth(clientset kubernetes.Interface, cluster data.ClusterInfo, namespace string) ([]data.HealthCheckResult, []string, error) {
+	results := []data.HealthCheckResult{}
+
+	hpas, inaccessible, err := k8s.ListNamespaced(context.Background(), clientset, namespace, func(ctx context.Context, ns string) ([]autoscalingv2.HorizontalPodAutoscaler, error) {
+		list, err := clientset.AutoscalingV2().HorizontalPodAutoscalers(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	})
+	if err != nil {
+		return results, nil, err
+	}
+
+	for _, hpa := range hpas {
+		result := data.HealthCheckResult{
+			Profile:     cluster.AWSProfile,
+			Region:      cluster.Region,
+			ClusterName: cluster.ClusterName,
+			Namespace:   hpa.Namespace,
+			Kind:        "HorizontalPodAutoscaler",
+			Name:        hpa.Name,
+			Labels:      hpa.Labels,
+			CreatedAt:   hpa.CreationTimestamp.Time,
+		}
+
+		result.Ready = fmt.Sprintf("%d/%d", hpa.Status.CurrentReplicas, hpa.Spec.MaxReplicas)
+		result.Status = fmt.Sprintf("Current:%d Desired:%d", hpa.Status.CurrentReplicas, hpa.Status.DesiredReplicas)
+		result.IsHealthy, result.Message = evaluateHPA(hpa)
+
+		results = append(results, result)
+	}
+
+	return results, inaccessible, nil
+}
+
+// evaluateHPA flags autoscalers that cannot scale, cannot read their
+// metrics or are already running at their maximum replicas. Autoscalers
+// with the same minimum and maximum are always at their maximum.
+func evaluateHPA(hpa autoscalingv2.HorizontalPodAutoscaler) (bool, string) {
+	for _, cond := range hpa.Status.Conditions {
+		if cond.Type == autoscalingv2.AbleToScale && cond.Status == corev1.ConditionFalse {
+			return false, fmt.Sprintf("Unable to scale: %s: %s", cond.Reason, cond.Message)
+		}
+		if cond.Type == autoscalingv2.ScalingActive && cond.Status == corev1.ConditionFalse {
+			return false, fmt.Sprintf("Unable to fetch metrics: %s: %s", cond.Reason, cond.Message)
+		}
+	}
+
+	minReplicas := int32(1)
+	if hpa.Spec.MinReplicas != nil {
+		minReplicas = *hpa.Spec.MinReplicas
+	}
+	if hpa.Status.CurrentReplicas >= hpa.Spec.MaxReplicas && minReplicas < hpa.Spec.MaxReplicas {
+		return false, fmt.Sprintf("At max replicas (%d)", hpa.Spec.MaxReplicas)
+	}
+
+	return true, "Scaling normally"
+}
+
+func checkServicesHealth(clientset kubernetes.Interface, cluster data.ClusterInfo, namespace string) ([]data.HealthCheckResult, []string, error) {
+	results := []data.HealthCheckResult{}
+
+	services, inaccessible, err := k8s.ListNamespaced(context.Background(), clientset, namespace, func(ctx context.Context, ns string) ([]corev1.Service, error) {
+		list, err := clientset.CoreV1().Services(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	})
+	if err != nil {
+		return results, nil, err
+	}
+
+	for _, svc := range services {
+		// Only LoadBalancer services have an externally provisioned status
+		if svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+			continue
+		}
+
+		result := data.HealthCheckResult{
+			Profile:     cluster.AWSProfile,
+			Region:      cluster.Region,
+			ClusterName: cluster.ClusterName,
+			Namespace:   svc.Namespace,
+			Kind:        "Service",
+			Name:        svc.Name,
+			Labels:      svc.Labels,
+			CreatedAt:   svc.CreationTimestamp.Time,
+		}
+
+		result.Ready = "-"
+		result.Status = string(svc.Spec.Type)
+
+		ingress := []string{}
+		for _, lb := range svc.Status.LoadBalancer.Ingress {
+			if lb.Hostname != "" {
+				ingress = append(ingress, lb.Hostname)
+			} else if lb.IP != "" {
+				ingress = append(ingress, lb.IP)
+			}
+		}
+
+		if len(ingress) == 0 {
+			result.IsHealthy = false
+			result.Message = "LoadBalancer has no ingress address"
+		} else {
+			result.IsHealthy = true
+			result.Message = strings.Join(ingress, ",")
+		}
+
+		results = append(results, result)
+	}
+
+	return results, inaccessible, nil
 }
 
 func summarizeResults(cluster data.ClusterInfo, results []data.HealthCheckResult) data.ClusterHealthSummary {
@@ -485,6 +1381,45 @@ func summarizeResults(cluster data.ClusterInfo, results []data.HealthCheckResult
 			if r.IsHealthy {
 				summary.HealthyReplicaSets++
 			}
+		case "Job":
+			summary.TotalJobs++
+			if r.IsHealthy {
+				summary.HealthyJobs++
+			}
+		case "CronJob":
+			summary.TotalCronJobs++
+			if r.IsHealthy {
+				summary.HealthyCronJobs++
+			}
+		case "PersistentVolumeClaim":
+			summary.TotalPVCs++
+			if r.IsHealthy {
+				summary.HealthyPVCs++
+			}
+		case "PodDisruptionBudget":
+			summary.TotalPDBs++
+			if r.IsHealthy {
+				summary.HealthyPDBs++
+			}
+		case "HorizontalPodAutoscaler":
+			summary.TotalHPAs++
+			if r.IsHealthy {
+				summary.HealthyHPAs++
+			}
+		case "Service":
+			summary.TotalServices++
+			if r.IsHealthy {
+				summary.HealthyServices++
+			}
+		default:
+			if summary.CustomTotals == nil {
+				summary.CustomTotals = make(map[string]int)
+				summary.CustomHealthy = make(map[string]int)
+			}
+			summary.CustomTotals[r.Kind]++
+			if r.IsHealthy {
+				summary.CustomHealthy[r.Kind]++
+			}
 		}
 	}
 
@@ -492,7 +1427,16 @@ func summarizeResults(cluster data.ClusterInfo, results []data.HealthCheckResult
 		(summary.TotalDeployments - summary.HealthyDeployments) +
 		(summary.TotalStatefulSets - summary.HealthyStatefulSets) +
 		(summary.TotalDaemonSets - summary.HealthyDaemonSets) +
-		(summary.TotalReplicaSets - summary.HealthyReplicaSets)
+		(summary.TotalReplicaSets - summary.HealthyReplicaSets) +
+		(summary.TotalJobs - summary.HealthyJobs) +
+		(summary.TotalCronJobs - summary.HealthyCronJobs) +
+		(summary.TotalPVCs - summary.HealthyPVCs) +
+		(summary.TotalPDBs - summary.HealthyPDBs) +
+		(summary.TotalHPAs - summary.HealthyHPAs) +
+		(summary.TotalServices - summary.HealthyServices)
+	for kind, total := range summary.CustomTotals {
+		unhealthy += total - summary.CustomHealthy[kind]
+	}
 
 	if unhealthy == 0 {
 		summary.OverallStatus = "Healthy"
@@ -503,6 +1447,28 @@ func summarizeResults(cluster data.ClusterInfo, results []data.HealthCheckResult
 	return summary
 }
 
+// healthHistoryPath returns the --history-file value or the default history file
+func healthHistoryPath(historyFile string) string {
+	if historyFile != "" {
+		return historyFile
+	}
+	return HomeDir + "/.kube/.kubectl-eks-health-history.jsonl"
+}
+
+// uniqueSortedStrings returns the distinct values of the input in sorted order
+func uniqueSortedStrings(values []string) []string {
+	seen := make(map[string]bool)
+	unique := []string{}
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
+
 func init() {
 	mCheckCmd.Flags().BoolP("refresh", "u", false, "Do not use cached data, refresh from AWS")
 	mCheckCmd.Flags().StringP("profile", "p", "", "AWS profile to use")
@@ -515,6 +1481,18 @@ func init() {
 	mCheckCmd.Flags().Bool("all", false, "Show all resources including healthy ones")
 	mCheckCmd.Flags().Bool("summary", false, "Show health summary")
 	mCheckCmd.Flags().Bool("no-headers", false, "Don't print headers")
+	mCheckCmd.Flags().StringP("output", "o", "", "Output format: junit|sarif")
+	mCheckCmd.Flags().String("fail-on", "any>0", "Unhealthy resource thresholds that make mcheck exit with 1, e.g. 'deployments>0,pods>5' (quoted)")
+	mCheckCmd.Flags().Bool("wait", false, "Keep checking until everything is healthy or --timeout expires")
+	mCheckCmd.Flags().Duration("timeout", 10*time.Minute, "Maximum time to wait with --wait")
+	mCheckCmd.Flags().Duration("interval", 15*time.Second, "Time between checks with --wait")
+	mCheckCmd.Flags().String("policy", "", "Health policy file (YAML) with ignores and extra expectations")
+	mCheckCmd.Flags().Bool("record", false, "Append this run to the health history file")
+	mCheckCmd.Flags().Bool("since-last", false, "Show what broke, recovered or is gone since the previous recorded run of each cluster")
+	mCheckCmd.Flags().String("history-file", "", "Health history file (default: ~/.kube/.kubectl-eks-health-history.jsonl)")
+	mCheckCmd.Flags().Int("history-keep", 100, "Recorded runs to keep in the history file, older ones are dropped by --record (0 keeps every run)")
+	mCheckCmd.Flags().Int32("max-restarts", defaultRestartThreshold.max, "Pods with a container restarted more times than this in total, and last terminated within --restart-window, are unhealthy (defaults to the policy pods.maxRestarts when set)")
+	mCheckCmd.Flags().Duration("restart-window", defaultRestartThreshold.window, "How recent the last container termination must be for --max-restarts and OOM kills to apply")
 
 	// Resource type filters
 	mCheckCmd.Flags().Bool("pods", false, "Check only pods")
@@ -522,6 +1500,13 @@ func init() {
 	mCheckCmd.Flags().Bool("statefulsets", false, "Check only statefulsets")
 	mCheckCmd.Flags().Bool("daemonsets", false, "Check only daemonsets")
 	mCheckCmd.Flags().Bool("replicasets", false, "Check only replicasets")
+	mCheckCmd.Flags().Bool("jobs", false, "Check only jobs")
+	mCheckCmd.Flags().Bool("cronjobs", false, "Check only cronjobs")
+	mCheckCmd.Flags().Bool("pvcs", false, "Check only persistentvolumeclaims")
+	mCheckCmd.Flags().Bool("pdbs", false, "Check only poddisruptionbudgets")
+	mCheckCmd.Flags().Bool("hpas", false, "Check only horizontalpodautoscalers")
+	mCheckCmd.Flags().Bool("services", false, "Check only LoadBalancer services")
+	mCheckCmd.Flags().StringSlice("kinds", []string{}, "Other resource types to check by their conditions, e.g. certificates,externalsecrets,nodeclaims")
 
 	rootCmd.AddCommand(mCheckCmd)
 }
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/jordiprats/kubectl-eks/pkg/k8s"
+	"github.com/jordiprats/kubectl-eks/pkg/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// checkCustomKinds checks the --kinds resource types of a cluster with the
+// generic readiness evaluator. Types the cluster does not serve (CRD not
+// installed) are skipped with a warning instead of being reported as errors.
+func checkCustomKinds(restConfig *rest.Config, clientset kubernetes.Interface, cluster data.ClusterInfo, namespace string, kinds []string) ([]data.HealthCheckResult, []string, []string) {
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, nil, []string{fmt.Sprintf("kinds: %v", err)}
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, nil, []string{fmt.Sprintf("kinds: %v", err)}
+	}
+
+	results := []data.HealthCheckResult{}
+	inaccessible := []string{}
+	errs := []string{}
+
+	for _, kind := range kinds {
+		gvr, namespaced, err := resolveResourceType(discoveryClient, kind)
+		if errors.Is(err, errResourceTypeNotFound) {
+			log.Printf("Warning: Resource type %s is not available in cluster %s, skipping", kind, cluster.ClusterName)
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", kind, err))
+			continue
+		}
+
+		kindResults, denied, err := checkCustomResourcesHealth(clientset, dynamicClient, gvr, namespaced, cluster, namespace)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", kind, err))
+		}
+		results = append(results, kindResults...)
+		inaccessible = append(inaccessible, denied...)
+	}
+
+	return results, inaccessible, errs
+}
+
+// checkCustomResourcesHealth lists a resource type with the dynamic client and
+// evaluates every object with status.EvaluateReadiness
+func checkCustomResourcesHealth(clientset kubernetes.Interface, dynamicClient dynamic.Interface, gvr schema.GroupVersionResource, namespaced bool, cluster data.ClusterInfo, namespace string) ([]data.HealthCheckResult, []string, error) {
+	results := []data.HealthCheckResult{}
+
+	var items []unstructured.Unstructured
+	var inaccessible []string
+	var err error
+	if namespaced {
+		items, inaccessible, err = k8s.ListNamespaced(context.Background(), clientset, namespace, func(ctx context.Context, ns string) ([]unstructured.Unstructured, error) {
+			list, err := dynamicClient.Resource(gvr).Namespace(ns).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			return list.Items, nil
+		})
+	} else {
+		var list *unstructured.UnstructuredList
+		list, err = dynamicClient.Resource(gvr).List(context.Background(), metav1.ListOptions{})
+		if err == nil {
+			items = list.Items
+		}
+	}
+	if err != nil {
+		return results, nil, err
+	}
+
+	for _, item := range items {
+		result := data.HealthCheckResult{
+			Profile:     cluster.AWSProfile,
+			Region:      cluster.Region,
+			ClusterName: cluster.ClusterName,
+			Namespace:   item.GetNamespace(),
+			Kind:        qualifiedKind(item.GetKind(), gvr.Group),
+			Name:        item.GetName(),
+			Labels:      item.GetLabels(),
+			CreatedAt:   item.GetCreationTimestamp().Time,
+		}
+
+		result.Ready = "-"
+		result.Status = status.ExtractStatus(item.Object, item.GetKind())
+		result.IsHealthy, result.Message = status.EvaluateReadiness(item.Object)
+
+		results = append(results, result)
+	}
+
+	return results, inaccessible, nil
+}
+
+// qualifiedKind names the kind of a --kinds resource with its API group, e.g.
+// Certificate.cert-manager.io, so a CRD sharing its kind with a built-in
+// resource, such as a Knative Service, is never counted, gated or matched by
+// policies as the built-in one
+func qualifiedKind(kind, group string) string {
+	if group == "" {
+		return kind
+	}
+	return kind + "." + group
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+)
+
+// mcheck exit codes
+const (
+	exitCodeHealthy       = 0
+	exitCodeUnhealthy     = 1
+	exitCodePartialFailed = 2
+)
+
+// failOnKinds are the keys accepted by --fail-on
+var failOnKinds = []string{"any", "pods", "deployments", "statefulsets", "daemonsets", "replicasets", "jobs", "cronjobs", "pvcs", "pdbs", "hpas", "services", "custom"}
+
+// parseFailOnThresholds parses a --fail-on value such as "deployments>0,pods>5"
+// into the maximum number of unhealthy resources allowed per kind. A kind
+// without a count means >0.
+func parseFailOnThresholds(spec string) (map[string]int, error) {
+	thresholds := make(map[string]int)
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		kind, count, found := strings.Cut(part, ">")
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		limit := 0
+		if found {
+			var err error
+			limit, err = strconv.Atoi(strings.TrimSpace(count))
+			if err != nil || limit < 0 {
+				return nil, fmt.Errorf("invalid count in %q", part)
+			}
+		}
+
+		valid := false
+		for _, k := range failOnKinds {
+			if kind == k {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("unknown kind %q (valid kinds: %s)", kind, strings.Join(failOnKinds, ", "))
+		}
+
+		thresholds[kind] = limit
+	}
+
+	return thresholds, nil
+}
+
+// unhealthyCounts returns the number of unhealthy resources per --fail-on
+// kind, summed across clusters
+func unhealthyCounts(summaries []data.ClusterHealthSummary) map[string]int {
+	counts := make(map[string]int)
+	for _, s := range summaries {
+		counts["pods"] += s.TotalPods - s.HealthyPods
+		counts["deployments"] += s.TotalDeployments - s.HealthyDeployments
+		counts["statefulsets"] += s.TotalStatefulSets - s.HealthyStatefulSets
+		counts["daemonsets"] += s.TotalDaemonSets - s.HealthyDaemonSets
+		counts["replicasets"] += s.TotalReplicaSets - s.HealthyReplicaSets
+		counts["jobs"] += s.TotalJobs - s.HealthyJobs
+		counts["cronjobs"] += s.TotalCronJobs - s.HealthyCronJobs
+		counts["pvcs"] += s.TotalPVCs - s.HealthyPVCs
+		counts["pdbs"] += s.TotalPDBs - s.HealthyPDBs
+		counts["hpas"] += s.TotalHPAs - s.HealthyHPAs
+		counts["services"] += s.TotalServices - s.HealthyServices
+		for kind, total := range s.CustomTotals {
+			counts["custom"] += total - s.CustomHealthy[kind]
+		}
+	}
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	counts["any"] = total
+	return counts
+}
+
+// thresholdViolations describes every threshold exceeded by the summaries
+func thresholdViolations(summaries []data.ClusterHealthSummary, thresholds map[string]int) []string {
+	counts := unhealthyCounts(summaries)
+
+	violations := []string{}
+	for kind, limit := range thresholds {
+		if counts[kind] > limit {
+			violations = append(violations, fmt.Sprintf("%d unhealthy %s (allowed %d)", counts[kind], kind, limit))
+		}
+	}
+	sort.Strings(violations)
+	return violations
+}
+
+func hasClusterErrors(summaries []data.ClusterHealthSummary) bool {
+	for _, s := range summaries {
+		if len(s.Errors) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func hasInaccessibleNamespaces(summaries []data.ClusterHealthSummary) bool {
+	for _, s := range summaries {
+		if len(s.InaccessibleNamespaces) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// clusterErrorSummary names the clusters that could not be fully checked
+func clusterErrorSummary(summaries []data.ClusterHealthSummary) []string {
+	messages := []string{}
+	for _, s := range summaries {
+		if len(s.Errors) > 0 {
+			messages = append(messages, fmt.Sprintf("cluster %s not fully checked", s.ClusterName))
+		}
+	}
+	return messages
+}
+
+// healthExitCode maps the outcome of the checks to the documented exit codes.
+// Exceeded thresholds take precedence over clusters that could not be checked.
+func healthExitCode(violations []string, summaries []data.ClusterHealthSummary) int {
+	if len(violations) > 0 {
+		return exitCodeUnhealthy
+	}
+	// RBAC denials are a partial failure too, but --wait does not wait on
+	// them as they will not resolve by themselves
+	if hasClusterErrors(summaries) || hasInaccessibleNamespaces(summaries) {
+		return exitCodePartialFailed
+	}
+	return exitCodeHealthy
+}
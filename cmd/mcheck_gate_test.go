@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFailOnThresholds(t *testing.T) {
+	thresholds, err := parseFailOnThresholds("deployments>0, Pods>5,statefulsets")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"deployments": 0, "pods": 5, "statefulsets": 0}, thresholds)
+
+	thresholds, err = parseFailOnThresholds("")
+	require.NoError(t, err)
+	assert.Empty(t, thresholds)
+
+	_, err = parseFailOnThresholds("ingresses>0")
+	assert.ErrorContains(t, err, "unknown kind")
+
+	_, err = parseFailOnThresholds("pods>many")
+	assert.ErrorContains(t, err, "invalid count")
+}
+
+func TestThresholdViolations(t *testing.T) {
+	summaries := []data.ClusterHealthSummary{
+		{ClusterName: "a", TotalPods: 10, HealthyPods: 7, TotalDeployments: 2, HealthyDeployments: 2},
+		{ClusterName: "b", TotalPods: 5, HealthyPods: 4, TotalDeployments: 3, HealthyDeployments: 2},
+	}
+
+	assert.Equal(t, []string{"5 unhealthy any (allowed 0)"}, thresholdViolations(summaries, map[string]int{"any": 0}))
+	assert.Empty(t, thresholdViolations(summaries, map[string]int{"pods": 4}), "thresholds are summed across clusters")
+	assert.Equal(t,
+		[]string{"1 unhealthy deployments (allowed 0)", "4 unhealthy pods (allowed 3)"},
+		thresholdViolations(summaries, map[string]int{"deployments": 0, "pods": 3}),
+	)
+}
+
+func TestHealthExitCode(t *testing.T) {
+	healthy := []data.ClusterHealthSummary{{ClusterName: "a"}}
+	partial := []data.ClusterHealthSummary{{ClusterName: "a"}, {ClusterName: "b", Errors: []string{"unreachable"}}}
+
+	assert.Equal(t, exitCodeHealthy, healthExitCode(nil, healthy))
+	assert.Equal(t, exitCodePartialFailed, healthExitCode(nil, partial))
+	assert.Equal(t, exitCodeUnhealthy, healthExitCode([]string{"1 unhealthy pods (allowed 0)"}, partial))
+
+	denied := []data.ClusterHealthSummary{{ClusterName: "a", InaccessibleNamespaces: []string{"payments"}}}
+	assert.Equal(t, exitCodePartialFailed, healthExitCode(nil, denied), "inaccessible namespaces were not checked")
+}
+
+func TestClusterErrors(t *testing.T) {
+	summaries := []data.ClusterHealthSummary{summarizeResults(data.ClusterInfo{ClusterName: "a"}, nil)}
+	assert.False(t, hasClusterErrors(summaries))
+
+	summaries[0].Errors = []string{"pods: connection refused"}
+	assert.True(t, hasClusterErrors(summaries))
+	assert.Equal(t, []string{"cluster a not fully checked"}, clusterErrorSummary(summaries))
+}
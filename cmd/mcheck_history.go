@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/jordiprats/kubectl-eks/pkg/healthhistory"
+	"github.com/jordiprats/kubectl-eks/pkg/printutils"
+	"github.com/spf13/cobra"
+)
+
+var mCheckHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show what broke, recovered or is gone across recorded mcheck runs",
+	Long: `Read the runs recorded with "mcheck --record" as a changelog: every
+resource that became unhealthy (BROKE), stopped being unhealthy (RECOVERED)
+or was not in the run at all (GONE), compared with the previous recorded run
+of the same cluster.
+
+Clusters that could not be fully checked in a run are not compared for that run.`,
+	Example: `  # Full changelog
+  kubectl eks mcheck history
+
+  # Changes in the last day for production clusters
+  kubectl eks mcheck history --since 24h --name-contains prod`,
+	Run: func(cmd *cobra.Command, args []string) {
+		historyFile, _ := cmd.Flags().GetString("history-file")
+		since, _ := cmd.Flags().GetDuration("since")
+		nameContains, _ := cmd.Flags().GetString("name-contains")
+		noHeaders, _ := cmd.Flags().GetBool("no-headers")
+
+		historyFile = healthHistoryPath(historyFile)
+		history, err := healthhistory.Load(historyFile)
+		if err != nil {
+			log.Fatalf("Error loading health history: %v", err)
+		}
+		if len(history) == 0 {
+			fmt.Printf("No recorded runs in %s, use mcheck --record\n", historyFile)
+			return
+		}
+
+		changes := filterHealthChanges(healthhistory.Changelog(history), since, nameContains, time.Now())
+		if len(changes) == 0 {
+			fmt.Println("No resources broke or recovered in the recorded runs")
+			return
+		}
+
+		printutils.PrintHealthChanges(noHeaders, changes)
+	},
+}
+
+// filterHealthChanges keeps the changes newer than since (when set) in the
+// clusters whose name contains nameContains
+func filterHealthChanges(changes []data.HealthChange, since time.Duration, nameContains string, now time.Time) []data.HealthChange {
+	filtered := []data.HealthChange{}
+	for _, c := range changes {
+		if since > 0 && c.Time.Before(now.Add(-since)) {
+			continue
+		}
+		if nameContains != "" && !strings.Contains(c.ClusterName, nameContains) {
+			continue
+		}
+		filtered = append(filtered, c)
+	}
+	return filtered
+}
+
+func init() {
+	mCheckHistoryCmd.Flags().String("history-file", "", "Health history file (default: ~/.kube/.kubectl-eks-health-history.jsonl)")
+	mCheckHistoryCmd.Flags().Duration("since", 0, "Only show changes newer than this, e.g. 24h")
+	mCheckHistoryCmd.Flags().StringP("name-contains", "c", "", "Cluster name contains string")
+	mCheckHistoryCmd.Flags().Bool("no-headers", false, "Don't print headers")
+
+	mCheckCmd.AddCommand(mCheckHistoryCmd)
+}
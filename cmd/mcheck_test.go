@@ -1,12 +1,27 @@
 package cmd
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/jordiprats/kubectl-eks/pkg/healthpolicy"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestCountReadyContainers(t *testing.T) {
@@ -310,3 +325,528 @@ func TestSummarizeResults_Counts(t *testing.T) {
 	assert.Equal(t, 0, summary.HealthyReplicaSets)
 	assert.Equal(t, "3 Unhealthy", summary.OverallStatus)
 }
+
+func TestCheckPodsHealth_ForbiddenClusterWideList(t *testing.T) {
+	clientset := fake.NewClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "api"},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		},
+	)
+	clientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == "" || action.GetNamespace() == "team-b" {
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", errors.New("denied"))
+		}
+		return false, nil, nil
+	})
+
+	results, inaccessible, err := checkPodsHealth(clientset, data.ClusterInfo{ClusterName: "test"}, "", defaultRestartThreshold)
+	require.NoError(t, err)
+
+	assert.Len(t, results, 1)
+	assert.Equal(t, "api", results[0].Name)
+	assert.Equal(t, []string{"team-b"}, inaccessible)
+}
+
+func TestCheckDeploymentsHealth_ListError(t *testing.T) {
+	clientset := fake.NewClientset()
+	clientset.PrependReactor("list", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+
+	results, _, err := checkDeploymentsHealth(clientset, data.ClusterInfo{ClusterName: "test"}, "")
+
+	assert.Error(t, err, "list failures are reported instead of looking healthy")
+	assert.Empty(t, results)
+}
+
+func TestUniqueSortedStrings(t *testing.T) {
+	assert.Equal(t, []string{"a", "b", "c"}, uniqueSortedStrings([]string{"c", "a", "b", "a"}))
+	assert.Equal(t, []string{}, uniqueSortedStrings(nil))
+}
+
+func TestEvaluateCronJob(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 30, 0, 0, time.UTC)
+	created := metav1.NewTime(now.Add(-48 * time.Hour))
+	suspend := true
+	timeZone := "Europe/Madrid"
+
+	tests := []struct {
+		name            string
+		cronJob         batchv1.CronJob
+		expectedHealthy bool
+		expectedMessage string
+	}{
+		{
+			name: "succeeded at the last scheduled run",
+			cronJob: batchv1.CronJob{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created},
+				Spec:       batchv1.CronJobSpec{Schedule: "0 * * * *"},
+				Status:     batchv1.CronJobStatus{LastSuccessfulTime: &metav1.Time{Time: now.Add(-29 * time.Minute)}},
+			},
+			expectedHealthy: true,
+			expectedMessage: "Succeeded on schedule",
+		},
+		{
+			name: "missed runs since the last success",
+			cronJob: batchv1.CronJob{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created},
+				Spec:       batchv1.CronJobSpec{Schedule: "0 * * * *"},
+				Status:     batchv1.CronJobStatus{LastSuccessfulTime: &metav1.Time{Time: now.Add(-3 * time.Hour)}},
+			},
+			expectedHealthy: false,
+			expectedMessage: "No successful run since 2024-06-01T09:30:00Z, expected one at 2024-06-01T10:00:00Z",
+		},
+		{
+			name: "never succeeded",
+			cronJob: batchv1.CronJob{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created},
+				Spec:       batchv1.CronJobSpec{Schedule: "@daily"},
+			},
+			expectedHealthy: false,
+			expectedMessage: "No successful run, expected one at 2024-05-31T00:00:00Z",
+		},
+		{
+			name: "not due yet",
+			cronJob: batchv1.CronJob{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(now.Add(-10 * time.Minute))},
+				Spec:       batchv1.CronJobSpec{Schedule: "0 * * * *"},
+			},
+			expectedHealthy: true,
+			expectedMessage: "Not run yet",
+		},
+		{
+			name: "running",
+			cronJob: batchv1.CronJob{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created},
+				Spec:       batchv1.CronJobSpec{Schedule: "0 * * * *"},
+				Status:     batchv1.CronJobStatus{Active: []corev1.ObjectReference{{Name: "job"}}},
+			},
+			expectedHealthy: true,
+			expectedMessage: "Running",
+		},
+		{
+			name: "suspended",
+			cronJob: batchv1.CronJob{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created},
+				Spec:       batchv1.CronJobSpec{Schedule: "0 * * * *", Suspend: &suspend},
+			},
+			expectedHealthy: true,
+			expectedMessage: "Suspended",
+		},
+		{
+			name: "time zone",
+			cronJob: batchv1.CronJob{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created},
+				// 15:00 in Madrid is 13:00 UTC, read as UTC the 15:00 run would be missed
+				Spec:   batchv1.CronJobSpec{Schedule: "0 15 * * *", TimeZone: &timeZone},
+				Status: batchv1.CronJobStatus{LastSuccessfulTime: &metav1.Time{Time: time.Date(2024, 5, 31, 13, 0, 5, 0, time.UTC)}},
+			},
+			expectedHealthy: true,
+			expectedMessage: "Succeeded on schedule",
+		},
+		{
+			name: "invalid schedule",
+			cronJob: batchv1.CronJob{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created},
+				Spec:       batchv1.CronJobSpec{Schedule: "every hour"},
+			},
+			expectedHealthy: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			healthy, message := evaluateCronJob(tt.cronJob, now)
+			assert.Equal(t, tt.expectedHealthy, healthy, message)
+			if tt.expectedMessage != "" {
+				assert.Equal(t, tt.expectedMessage, message)
+			}
+		})
+	}
+}
+
+func TestEvaluateHPA(t *testing.T) {
+	hpa := func(current, max int32, conditions ...autoscalingv2.HorizontalPodAutoscalerCondition) autoscalingv2.HorizontalPodAutoscaler {
+		return autoscalingv2.HorizontalPodAutoscaler{
+			Spec:   autoscalingv2.HorizontalPodAutoscalerSpec{MaxReplicas: max},
+			Status: autoscalingv2.HorizontalPodAutoscalerStatus{CurrentReplicas: current, Conditions: conditions},
+		}
+	}
+
+	healthy, message := evaluateHPA(hpa(3, 10))
+	assert.True(t, healthy)
+	assert.Equal(t, "Scaling normally", message)
+
+	healthy, message = evaluateHPA(hpa(10, 10))
+	assert.False(t, healthy)
+	assert.Equal(t, "At max replicas (10)", message)
+
+	fixed := hpa(4, 4)
+	minReplicas := int32(4)
+	fixed.Spec.MinReplicas = &minReplicas
+	healthy, message = evaluateHPA(fixed)
+	assert.True(t, healthy, "min and max are the same")
+	assert.Equal(t, "Scaling normally", message)
+
+	healthy, message = evaluateHPA(hpa(3, 10, autoscalingv2.HorizontalPodAutoscalerCondition{
+		Type:    autoscalingv2.ScalingActive,
+		Status:  corev1.ConditionFalse,
+		Reason:  "FailedGetResourceMetric",
+		Message: "missing request for cpu",
+	}))
+	assert.False(t, healthy)
+	assert.Equal(t, "Unable to fetch metrics: FailedGetResourceMetric: missing request for cpu", message)
+}
+
+func TestCheckJobsPVCsPDBsServicesHealth(t *testing.T) {
+	clientset := fake.NewClientset(
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "batch", Name: "failed"},
+			Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded", Message: "Job has reached the specified backoff limit"},
+			}},
+		},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "batch", Name: "done"},
+			Status: batchv1.JobStatus{Succeeded: 1, Conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+			}},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "data"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+		},
+		&policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "strict"},
+			Status:     policyv1.PodDisruptionBudgetStatus{ExpectedPods: 2, CurrentHealthy: 2, DesiredHealthy: 2},
+		},
+		&policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "degraded"},
+			Status:     policyv1.PodDisruptionBudgetStatus{ExpectedPods: 3, CurrentHealthy: 1, DesiredHealthy: 2},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "pending-lb"},
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "internal"},
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP},
+		},
+	)
+	cluster := data.ClusterInfo{ClusterName: "test"}
+
+	jobs, _, err := checkJobsHealth(clientset, cluster, "")
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	byName := map[string]data.HealthCheckResult{}
+	for _, r := range jobs {
+		byName[r.Name] = r
+	}
+	assert.False(t, byName["failed"].IsHealthy)
+	assert.Equal(t, "Failed: BackoffLimitExceeded: Job has reached the specified backoff limit", byName["failed"].Message)
+	assert.True(t, byName["done"].IsHealthy)
+	assert.Equal(t, "1/1", byName["done"].Ready)
+
+	pvcs, _, err := checkPVCsHealth(clientset, cluster, "")
+	require.NoError(t, err)
+	require.Len(t, pvcs, 1)
+	assert.False(t, pvcs[0].IsHealthy)
+
+	pdbs, _, err := checkPDBsHealth(clientset, cluster, "")
+	require.NoError(t, err)
+	require.Len(t, pdbs, 2)
+	pdbsByName := map[string]data.HealthCheckResult{}
+	for _, r := range pdbs {
+		pdbsByName[r.Name] = r
+	}
+	assert.False(t, pdbsByName["strict"].IsHealthy, "no disruptions allowed blocks drains")
+	assert.Equal(t, "No disruptions allowed (healthy 2, desired 2)", pdbsByName["strict"].Message)
+	assert.False(t, pdbsByName["degraded"].IsHealthy)
+	assert.Equal(t, "Not enough healthy pods (healthy 1, desired 2)", pdbsByName["degraded"].Message)
+
+	services, _, err := checkServicesHealth(clientset, cluster, "")
+	require.NoError(t, err)
+	require.Len(t, services, 1, "only LoadBalancer services are checked")
+	assert.Equal(t, "pending-lb", services[0].Name)
+	assert.False(t, services[0].IsHealthy)
+
+	summary := summarizeResults(cluster, append(append(append(jobs, pvcs...), pdbs...), services...))
+	assert.Equal(t, 2, summary.TotalJobs)
+	assert.Equal(t, 1, summary.HealthyJobs)
+	assert.Equal(t, 1, summary.TotalPVCs)
+	assert.Equal(t, 2, summary.TotalPDBs)
+	assert.Equal(t, 1, summary.TotalServices)
+	assert.Equal(t, "5 Unhealthy", summary.OverallStatus)
+}
+
+func TestCheckCustomResourcesHealth(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+	certificate := func(namespace, name, ready string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "cert-manager.io/v1",
+			"kind":       "Certificate",
+			"metadata":   map[string]interface{}{"namespace": namespace, "name": name},
+			"status": map[string]interface{}{
+				"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": ready, "reason": "Issuing"}},
+			},
+		}}
+	}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "CertificateList"},
+		certificate("web", "valid", "True"),
+		certificate("web", "issuing", "False"),
+	)
+	cluster := data.ClusterInfo{ClusterName: "test"}
+
+	results, _, err := checkCustomResourcesHealth(fake.NewClientset(), dynamicClient, gvr, true, cluster, "")
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	byName := map[string]data.HealthCheckResult{}
+	for _, r := range results {
+		byName[r.Name] = r
+	}
+	assert.Equal(t, "Certificate.cert-manager.io", byName["valid"].Kind)
+	assert.True(t, byName["valid"].IsHealthy)
+	assert.False(t, byName["issuing"].IsHealthy)
+	assert.Equal(t, "NotReady: Issuing", byName["issuing"].Message)
+
+	summary := summarizeResults(cluster, results)
+	assert.Equal(t, map[string]int{"Certificate.cert-manager.io": 2}, summary.CustomTotals)
+	assert.Equal(t, map[string]int{"Certificate.cert-manager.io": 1}, summary.CustomHealthy)
+	assert.Equal(t, "1 Unhealthy", summary.OverallStatus)
+	assert.Equal(t, 1, unhealthyCounts([]data.ClusterHealthSummary{summary})["custom"])
+}
+
+func TestCheckCustomResourcesHealth_BuiltinKindName(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "serving.knative.dev", Version: "v1", Resource: "services"}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "ServiceList"},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "serving.knative.dev/v1",
+			"kind":       "Service",
+			"metadata":   map[string]interface{}{"namespace": "web", "name": "hello"},
+			"status": map[string]interface{}{
+				"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "False", "reason": "RevisionMissing"}},
+			},
+		}},
+	)
+	cluster := data.ClusterInfo{ClusterName: "test"}
+
+	results, _, err := checkCustomResourcesHealth(fake.NewClientset(), dynamicClient, gvr, true, cluster, "")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Service.serving.knative.dev", results[0].Kind)
+
+	summary := summarizeResults(cluster, results)
+	assert.Equal(t, 0, summary.TotalServices, "not counted as a Kubernetes Service")
+	counts := unhealthyCounts([]data.ClusterHealthSummary{summary})
+	assert.Equal(t, 0, counts["services"])
+	assert.Equal(t, 1, counts["custom"])
+}
+
+func TestEvaluatePodHealth(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	terminated := func(reason string, exitCode int32, ago time.Duration) corev1.ContainerState {
+		return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+			Reason:     reason,
+			ExitCode:   exitCode,
+			FinishedAt: metav1.NewTime(now.Add(-ago)),
+		}}
+	}
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+
+	tests := []struct {
+		name            string
+		status          corev1.PodStatus
+		expectedHealthy bool
+		expectedMessage string
+	}{
+		{
+			name: "restarts long ago are tolerated",
+			status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "app", Ready: true, RestartCount: 300, State: running, LastTerminationState: terminated("Error", 1, 48*time.Hour)},
+			}},
+			expectedHealthy: true,
+			expectedMessage: "All containers ready",
+		},
+		{
+			name: "restart heavy within the window",
+			status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "app", Ready: true, RestartCount: 12, State: running, LastTerminationState: terminated("Error", 2, 10*time.Minute)},
+			}},
+			expectedHealthy: false,
+			expectedMessage: "Restarting: container app restarted 12 times, last 10m0s ago (last terminated: Error, exit code 2)",
+		},
+		{
+			name: "recent OOM kill",
+			status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "app", Ready: true, RestartCount: 1, State: running, LastTerminationState: terminated("OOMKilled", 137, 5*time.Minute)},
+			}},
+			expectedHealthy: false,
+			expectedMessage: "OOMKilled: container app was OOM killed 5m0s ago (last terminated: OOMKilled, exit code 137)",
+		},
+		{
+			name: "init container crash loop",
+			status: corev1.PodStatus{Phase: corev1.PodPending, InitContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:                 "migrate",
+					RestartCount:         4,
+					State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+					LastTerminationState: terminated("Error", 1, time.Minute),
+				},
+			}},
+			expectedHealthy: false,
+			expectedMessage: "CrashLoopBackOff: container migrate restarted 4 times (last terminated: Error, exit code 1)",
+		},
+		{
+			name: "ephemeral container image pull error",
+			status: corev1.PodStatus{
+				Phase:             corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "app", Ready: true, State: running}},
+				EphemeralContainerStatuses: []corev1.ContainerStatus{
+					{Name: "debugger", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image \"busybox:nope\""}}},
+				},
+			},
+			expectedHealthy: false,
+			expectedMessage: "ImagePullBackOff: container debugger: Back-off pulling image \"busybox:nope\"",
+		},
+		{
+			name: "failed pod includes the exit code",
+			status: corev1.PodStatus{Phase: corev1.PodFailed, ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "job", State: terminated("Error", 3, time.Minute)},
+			}},
+			expectedHealthy: false,
+			expectedMessage: "Error (container job exit code 3)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := corev1.Pod{
+				Spec:   corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+				Status: tt.status,
+			}
+			healthy, message := evaluatePodHealth(pod, now, defaultRestartThreshold)
+			assert.Equal(t, tt.expectedHealthy, healthy)
+			assert.Equal(t, tt.expectedMessage, message)
+		})
+	}
+}
+
+func TestPolicyRestartThreshold(t *testing.T) {
+	policy, err := healthpolicy.Parse([]byte("pods:\n  maxRestarts: 20\n"))
+	require.NoError(t, err)
+
+	restarts := policyRestartThreshold(defaultRestartThreshold, policy, false)
+	assert.Equal(t, int32(20), restarts.max, "the policy replaces the default")
+	assert.Equal(t, defaultRestartThreshold.window, restarts.window)
+
+	explicit := restartThreshold{max: 3, window: time.Hour}
+	assert.Equal(t, explicit, policyRestartThreshold(explicit, policy, true), "an explicit --max-restarts wins")
+
+	empty, err := healthpolicy.Parse([]byte(""))
+	require.NoError(t, err)
+	assert.Equal(t, defaultRestartThreshold, policyRestartThreshold(defaultRestartThreshold, empty, false))
+}
+
+func TestPodStarting(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	waiting := func(reason string) corev1.ContainerState {
+		return corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}}
+	}
+
+	tests := []struct {
+		name   string
+		status corev1.PodStatus
+		want   bool
+	}{
+		{
+			name: "unschedulable",
+			status: corev1.PodStatus{Phase: corev1.PodPending, Conditions: []corev1.PodCondition{
+				{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: "Unschedulable"},
+			}},
+			want: true,
+		},
+		{
+			name: "creating containers",
+			status: corev1.PodStatus{Phase: corev1.PodPending, ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "app", State: waiting("ContainerCreating")},
+			}},
+			want: true,
+		},
+		{
+			name: "image pull error",
+			status: corev1.PodStatus{Phase: corev1.PodPending, ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "app", State: waiting("ImagePullBackOff")},
+			}},
+			want: false,
+		},
+		{
+			name: "missing config",
+			status: corev1.PodStatus{Phase: corev1.PodPending, ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "app", State: waiting("CreateContainerConfigError")},
+			}},
+			want: false,
+		},
+		{
+			name: "failed init container",
+			status: corev1.PodStatus{Phase: corev1.PodPending, InitContainerStatuses: []corev1.ContainerStatus{
+				{Name: "migrate", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1}}},
+			}},
+			want: false,
+		},
+		{
+			name:   "running",
+			status: corev1.PodStatus{Phase: corev1.PodRunning},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := corev1.Pod{Status: tt.status}
+			assert.Equal(t, tt.want, podStarting(pod, now, defaultRestartThreshold))
+		})
+	}
+}
+
+func TestRecentRestarts(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	terminatedAgo := func(ago time.Duration) corev1.ContainerState {
+		return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", FinishedAt: metav1.NewTime(now.Add(-ago))}}
+	}
+
+	pod := corev1.Pod{Status: corev1.PodStatus{
+		InitContainerStatuses: []corev1.ContainerStatus{
+			{Name: "migrate", RestartCount: 300, LastTerminationState: terminatedAgo(48 * time.Hour)},
+		},
+		ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "app", RestartCount: 7, LastTerminationState: terminatedAgo(10 * time.Minute)},
+			{Name: "sidecar", RestartCount: 2, LastTerminationState: terminatedAgo(time.Minute)},
+		},
+	}}
+
+	assert.Equal(t, int32(7), recentRestarts(pod, now, time.Hour), "containers stable for longer than the window are left out")
+	assert.Equal(t, int32(300), recentRestarts(pod, now, 72*time.Hour))
+	assert.Equal(t, int32(0), recentRestarts(corev1.Pod{}, now, time.Hour))
+}
+
+func TestFilterHealthChanges(t *testing.T) {
+	now := time.Date(2024, 6, 2, 12, 0, 0, 0, time.UTC)
+	changes := []data.HealthChange{
+		{Time: now.Add(-48 * time.Hour), ClusterName: "prod-eu", Name: "old"},
+		{Time: now.Add(-time.Hour), ClusterName: "prod-eu", Name: "recent"},
+		{Time: now.Add(-time.Hour), ClusterName: "staging", Name: "other-cluster"},
+	}
+
+	filtered := filterHealthChanges(changes, 24*time.Hour, "prod", now)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "recent", filtered[0].Name)
+
+	assert.Len(t, filterHealthChanges(changes, 0, "", now), 3)
+}
@@ -0,0 +1,355 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/jordiprats/kubectl-eks/pkg/diff"
+	"github.com/jordiprats/kubectl-eks/pkg/eks"
+	"github.com/jordiprats/kubectl-eks/pkg/printutils"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	diffStatusBaseline  = "BASELINE"
+	diffStatusIdentical = "IDENTICAL"
+	diffStatusDiffers   = "DIFFERS"
+	diffStatusNotFound  = "NOT FOUND"
+	diffStatusError     = "ERROR"
+)
+
+// clusterObject is the copy of the compared resource fetched from a cluster
+type clusterObject struct {
+	cluster data.ClusterInfo
+	object  *unstructured.Unstructured
+	status  string
+	err     string
+}
+
+var mDiffCmd = &cobra.Command{
+	Use:   "mdiff <resource-type>/<resource-name> | <resource-type> <resource-name>",
+	Short: "Compare a resource across multiple clusters",
+	Long: `Fetch the same resource from several clusters and diff every copy
+against a baseline cluster.
+
+Fields managed by the API server (managedFields, resourceVersion, uid,
+creationTimestamp, generation and the last-applied-configuration annotation)
+are removed before comparing. The status is ignored unless --include-status
+is set.
+
+Clusters are picked by name with --clusters, or with the usual cluster
+filters. The baseline defaults to the first selected cluster.`,
+	Example: `  # Compare a deployment across three clusters
+  kubectl eks mdiff deployment/checkout -n shop --clusters a,b,c
+
+  # Side-by-side diff against a specific baseline
+  kubectl eks mdiff deployment checkout -n shop --name-contains prod --baseline prod-eu -o side-by-side
+
+  # Only show which clusters diverge
+  kubectl eks mdiff configmap/coredns -n kube-system --name-contains prod --summary`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		resourceType, resourceName, err := parseResourceRef(args)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+
+		refresh, _ := cmd.Flags().GetBool("refresh")
+		profile, _ := cmd.Flags().GetString("profile")
+		profileContains, _ := cmd.Flags().GetString("profile-contains")
+		nameContains, _ := cmd.Flags().GetString("name-contains")
+		nameNotContains, _ := cmd.Flags().GetString("name-not-contains")
+		region, _ := cmd.Flags().GetString("region")
+		version, _ := cmd.Flags().GetString("version")
+		namespace, _ := cmd.Flags().GetString("namespace")
+		clusterNames, _ := cmd.Flags().GetStringSlice("clusters")
+		baselineName, _ := cmd.Flags().GetString("baseline")
+		output, _ := cmd.Flags().GetString("output")
+		includeStatus, _ := cmd.Flags().GetBool("include-status")
+		summaryOnly, _ := cmd.Flags().GetBool("summary")
+		width, _ := cmd.Flags().GetInt("width")
+		noHeaders, _ := cmd.Flags().GetBool("no-headers")
+
+		if output != "" && output != "unified" && output != "side-by-side" {
+			log.Fatalf("Output format %q is not supported (use unified or side-by-side)", output)
+		}
+		if width < 1 {
+			log.Fatalf("--width must be at least 1, got %d", width)
+		}
+
+		var clusterList []data.ClusterInfo
+		if len(clusterNames) > 0 {
+			allClusters, err := loadCachedClusterList(profile, profileContains, nameContains, nameNotContains, region, version, refresh)
+			if err != nil {
+				log.Fatalf("Error loading cluster list: %v", err)
+			}
+			clusterList, err = selectClustersByName(allClusters, clusterNames)
+			if err != nil {
+				log.Fatalf("%v", err)
+			}
+		} else {
+			clusterList, err = LoadClusterList([]string{}, profile, profileContains, nameContains, nameNotContains, region, version, refresh)
+			if err != nil {
+				log.Fatalf("Error loading cluster list: %v", err)
+			}
+		}
+
+		if len(clusterList) < 2 {
+			log.Fatalf("At least two clusters are needed to compare, got %d", len(clusterList))
+		}
+
+		baselineIndex := 0
+		if baselineName != "" {
+			baselineIndex = -1
+			for i, clusterInfo := range clusterList {
+				if clusterInfo.ClusterName == baselineName {
+					baselineIndex = i
+					break
+				}
+			}
+			if baselineIndex < 0 {
+				log.Fatalf("Baseline cluster %q is not among the selected clusters", baselineName)
+			}
+		}
+
+		// Save and restore context
+		loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+		config, err := loadingRules.Load()
+		if err != nil {
+			log.Fatalf("Error loading kubeconfig: %v", err)
+		}
+		previousContext := config.CurrentContext
+		restoreContext := func() {
+			config.CurrentContext = previousContext
+			clientcmd.ModifyConfig(loadingRules, *config, true)
+		}
+		defer restoreContext()
+
+		objects := []clusterObject{}
+		for _, clusterInfo := range clusterList {
+			objects = append(objects, fetchClusterObject(clusterInfo, resourceType, resourceName, namespace))
+		}
+
+		baseline := objects[baselineIndex]
+		if baseline.object == nil {
+			// log.Fatalf exits without running deferred calls
+			restoreContext()
+			log.Fatalf("Unable to get %s/%s from baseline cluster %s: %s", resourceType, resourceName, baseline.cluster.ClusterName, baseline.err)
+		}
+
+		results, diffs := compareClusterObjects(baselineIndex, objects, includeStatus, output, width)
+
+		if !summaryOnly {
+			for _, d := range diffs {
+				fmt.Println(d)
+			}
+		}
+
+		printutils.PrintDiffSummary(noHeaders, results)
+
+		saveCacheToDisk()
+	},
+}
+
+// parseResourceRef accepts both "type/name" and "type name"
+func parseResourceRef(args []string) (string, string, error) {
+	if len(args) == 2 {
+		return args[0], args[1], nil
+	}
+
+	resourceType, resourceName, found := strings.Cut(args[0], "/")
+	if !found || resourceType == "" || resourceName == "" {
+		return "", "", fmt.Errorf("expected <resource-type>/<resource-name>, got %q", args[0])
+	}
+	return resourceType, resourceName, nil
+}
+
+// selectClustersByName keeps the clusters named in names, in the same order.
+// Names matching several clusters (same name in different accounts or
+// regions) are rejected so the comparison is never ambiguous.
+func selectClustersByName(clusterList []data.ClusterInfo, names []string) ([]data.ClusterInfo, error) {
+	selected := []data.ClusterInfo{}
+	for _, name := range names {
+		matches := []data.ClusterInfo{}
+		for _, cluster := range clusterList {
+			if cluster.ClusterName == name {
+				matches = append(matches, cluster)
+			}
+		}
+
+		switch len(matches) {
+		case 0:
+			return nil, fmt.Errorf("cluster %q not found", name)
+		case 1:
+			selected = append(selected, matches[0])
+		default:
+			return nil, fmt.Errorf("cluster name %q matches %d clusters, narrow it down with --profile or --region", name, len(matches))
+		}
+	}
+	return selected, nil
+}
+
+func fetchClusterObject(clusterInfo data.ClusterInfo, resourceType, resourceName, namespace string) clusterObject {
+	result := clusterObject{cluster: clusterInfo}
+
+	err := eks.UpdateKubeConfig(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName, "")
+	if err != nil {
+		result.status = diffStatusError
+		result.err = fmt.Sprintf("failed to update kubeconfig: %v", err)
+		return result
+	}
+
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{},
+	)
+
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		result.status = diffStatusError
+		result.err = err.Error()
+		return result
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		result.status = diffStatusError
+		result.err = err.Error()
+		return result
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		result.status = diffStatusError
+		result.err = err.Error()
+		return result
+	}
+
+	gvr, namespaced, err := resolveResourceType(discoveryClient, resourceType)
+	if err != nil {
+		result.status = diffStatusError
+		result.err = fmt.Sprintf("failed to resolve resource type '%s': %v", resourceType, err)
+		return result
+	}
+
+	var resourceInterface dynamic.ResourceInterface = dynamicClient.Resource(gvr)
+	if namespaced {
+		ns := namespace
+		if ns == "" {
+			ns, _, err = clientConfig.Namespace()
+			if err != nil {
+				ns = "default"
+			}
+		}
+		resourceInterface = dynamicClient.Resource(gvr).Namespace(ns)
+	}
+
+	obj, err := resourceInterface.Get(context.Background(), resourceName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			result.status = diffStatusNotFound
+		} else {
+			result.status = diffStatusError
+			result.err = err.Error()
+		}
+		return result
+	}
+
+	result.object = obj
+	return result
+}
+
+// compareClusterObjects diffs every object against the baseline one. It
+// returns a summary row per cluster and the rendered diffs of the clusters
+// that diverge.
+func compareClusterObjects(baselineIndex int, objects []clusterObject, includeStatus bool, output string, width int) ([]data.ResourceDiffResult, []string) {
+	results := []data.ResourceDiffResult{}
+	diffs := []string{}
+
+	baseline := objects[baselineIndex]
+	baselineYAML, err := diff.ToYAML(diff.Normalize(baseline.object, includeStatus))
+	if err != nil {
+		log.Fatalf("Error rendering baseline object: %v", err)
+	}
+	baselineLabel := fmt.Sprintf("%s (baseline)", baseline.cluster.ClusterName)
+
+	for i, obj := range objects {
+		result := data.ResourceDiffResult{
+			Profile:     obj.cluster.AWSProfile,
+			Region:      obj.cluster.Region,
+			ClusterName: obj.cluster.ClusterName,
+			Status:      obj.status,
+			Error:       obj.err,
+		}
+
+		if i == baselineIndex {
+			result.Status = diffStatusBaseline
+			results = append(results, result)
+			continue
+		}
+
+		if obj.object == nil {
+			results = append(results, result)
+			continue
+		}
+
+		objectYAML, err := diff.ToYAML(diff.Normalize(obj.object, includeStatus))
+		if err != nil {
+			result.Status = diffStatusError
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		result.Removed, result.Added = diff.ChangedLines(baselineYAML, objectYAML)
+		if result.Added == 0 && result.Removed == 0 {
+			result.Status = diffStatusIdentical
+			results = append(results, result)
+			continue
+		}
+		result.Status = diffStatusDiffers
+		results = append(results, result)
+
+		if output == "side-by-side" {
+			diffs = append(diffs, diff.SideBySide(baselineLabel, obj.cluster.ClusterName, baselineYAML, objectYAML, width))
+		} else {
+			unified, err := diff.Unified(baselineLabel, obj.cluster.ClusterName, baselineYAML, objectYAML)
+			if err != nil {
+				log.Printf("Warning: Unable to diff cluster %s: %v", obj.cluster.ClusterName, err)
+				continue
+			}
+			diffs = append(diffs, unified)
+		}
+	}
+
+	return results, diffs
+}
+
+func init() {
+	mDiffCmd.Flags().BoolP("refresh", "u", false, "Do not use cached data, refresh from AWS")
+	mDiffCmd.Flags().StringP("profile", "p", "", "AWS profile to use")
+	mDiffCmd.Flags().StringP("profile-contains", "q", "", "AWS profile contains string")
+	mDiffCmd.Flags().StringP("name-contains", "c", "", "Cluster name contains string")
+	mDiffCmd.Flags().StringP("name-not-contains", "x", "", "Cluster name does not contain string")
+	mDiffCmd.Flags().StringP("region", "r", "", "AWS region to use")
+	mDiffCmd.Flags().StringP("version", "v", "", "Filter by EKS version")
+	mDiffCmd.Flags().StringP("namespace", "n", "", "Kubernetes namespace")
+	mDiffCmd.Flags().StringSlice("clusters", []string{}, "Comma separated list of cluster names to compare")
+	mDiffCmd.Flags().String("baseline", "", "Cluster to compare the others against (defaults to the first cluster)")
+	mDiffCmd.Flags().StringP("output", "o", "", "Diff format: unified|side-by-side")
+	mDiffCmd.Flags().Bool("include-status", false, "Also compare the status of the resource")
+	mDiffCmd.Flags().Bool("summary", false, "Only print the summary table")
+	mDiffCmd.Flags().Int("width", 60, "Column width for side-by-side output")
+	mDiffCmd.Flags().Bool("no-headers", false, "Don't print headers")
+
+	rootCmd.AddCommand(mDiffCmd)
+}
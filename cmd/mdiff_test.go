@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestParseResourceRef(t *testing.T) {
+	resourceType, resourceName, err := parseResourceRef([]string{"deployment/checkout"})
+	require.NoError(t, err)
+	assert.Equal(t, "deployment", resourceType)
+	assert.Equal(t, "checkout", resourceName)
+
+	resourceType, resourceName, err = parseResourceRef([]string{"deployment", "checkout"})
+	require.NoError(t, err)
+	assert.Equal(t, "deployment", resourceType)
+	assert.Equal(t, "checkout", resourceName)
+
+	_, _, err = parseResourceRef([]string{"deployment"})
+	assert.Error(t, err)
+}
+
+func TestSelectClustersByName(t *testing.T) {
+	clusters := []data.ClusterInfo{
+		{AWSProfile: "dev", Region: "eu-west-1", ClusterName: "a"},
+		{AWSProfile: "dev", Region: "eu-west-1", ClusterName: "b"},
+		{AWSProfile: "prod", Region: "eu-west-1", ClusterName: "c"},
+		{AWSProfile: "prod", Region: "us-east-1", ClusterName: "c"},
+	}
+
+	selected, err := selectClustersByName(clusters, []string{"b", "a"})
+	require.NoError(t, err)
+	require.Len(t, selected, 2)
+	assert.Equal(t, "b", selected[0].ClusterName, "the requested order is kept")
+	assert.Equal(t, "a", selected[1].ClusterName)
+
+	_, err = selectClustersByName(clusters, []string{"a", "missing"})
+	assert.ErrorContains(t, err, "not found")
+
+	_, err = selectClustersByName(clusters, []string{"c"})
+	assert.ErrorContains(t, err, "matches 2 clusters")
+}
+
+func newDiffTestObject(image string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]interface{}{"name": "web", "uid": image},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{map[string]interface{}{"name": "web", "image": image}},
+		},
+	}}
+}
+
+func TestCompareClusterObjects(t *testing.T) {
+	objects := []clusterObject{
+		{cluster: data.ClusterInfo{ClusterName: "a"}, object: newDiffTestObject("web:1")},
+		{cluster: data.ClusterInfo{ClusterName: "b"}, object: newDiffTestObject("web:1")},
+		{cluster: data.ClusterInfo{ClusterName: "c"}, object: newDiffTestObject("web:2")},
+		{cluster: data.ClusterInfo{ClusterName: "d"}, status: diffStatusNotFound},
+	}
+
+	results, diffs := compareClusterObjects(0, objects, false, "", 60)
+
+	require.Len(t, results, 4)
+	assert.Equal(t, diffStatusBaseline, results[0].Status)
+	assert.Equal(t, diffStatusIdentical, results[1].Status, "server managed fields such as uid are ignored")
+	assert.Equal(t, diffStatusDiffers, results[2].Status)
+	assert.Equal(t, 1, results[2].Added)
+	assert.Equal(t, 1, results[2].Removed)
+	assert.Equal(t, diffStatusNotFound, results[3].Status)
+
+	require.Len(t, diffs, 1)
+	assert.Contains(t, diffs[0], "--- a (baseline)")
+	assert.Contains(t, diffs[0], "+++ c")
+}
@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"reflect"
+	"regexp"
 	"strings"
 
 	"github.com/jordiprats/kubectl-eks/pkg/data"
@@ -33,7 +37,22 @@ Supports output formats:
   -o wide          Additional details
   -o json          JSON output
   -o yaml          YAML output
-  -o jsonpath=...  Extract specific fields using JSONPath`,
+  -o jsonpath=...  Extract specific fields using JSONPath
+
+Use --watch to open a watch on every selected cluster and stream
+ADDED/MODIFIED/DELETED changes as they happen. Watches reconnect
+automatically and re-list when their resourceVersion expires.
+
+Use --count to print how many resources each cluster has, or --group-by
+with a JSONPath expression to count them per value (a resource yielding
+several values, like a pod with several containers, is counted once under
+each distinct value). The table has a column per cluster, labelled
+profile/region/name, and a total; -o json prints the same counts as JSON.
+
+By default -o jsonpath prints the first value matched for each resource.
+Use --explode to print one row per matched value instead: arrays are
+expanded into their elements and {range} expressions produce one row per
+line of output.`,
 	Example: `  # List all pods across clusters
   kubectl eks mget pods
 
@@ -45,6 +64,12 @@ Supports output formats:
 
   # Extract specific fields with JSONPath
   kubectl eks mget pods -o jsonpath='{.spec.dnsPolicy}'
+
+  # Image inventory, one row per container
+  kubectl eks mget pods -A -o jsonpath='{.spec.containers[*].image}' --explode
+
+  # One row per range iteration
+  kubectl eks mget pods -A -o jsonpath='{range .spec.containers[*]}{.name}={.image}{"\n"}{end}' --explode
   
   # List deployments with additional details
   kubectl eks mget deployments -o wide
@@ -56,7 +81,19 @@ Supports output formats:
   kubectl eks mget pods --name-contains prod --resource-contains api
   
   # Works with any resource including CRDs
-  kubectl eks mget ec2nodeclass -A`,
+  kubectl eks mget ec2nodeclass -A
+
+  # Filter by label selector
+  kubectl eks mget pods -l app=api -A
+
+  # Count pods per image across prod clusters
+  kubectl eks mget pods -A --name-contains prod --group-by '{.spec.containers[*].image}'
+
+  # Count deployments per cluster
+  kubectl eks mget deployments -A --count
+
+  # Stream changes across clusters
+  kubectl eks mget pods -l app=api --watch --name-contains prod`,
 	Args: cobra.RangeArgs(1, 2),
 	Run: func(cmd *cobra.Command, args []string) {
 		resourceType := args[0]
@@ -79,6 +116,19 @@ Supports output formats:
 		startsWith, _ := cmd.Flags().GetString("resource-starts-with")
 		contains, _ := cmd.Flags().GetString("resource-contains")
 		noHeaders, _ := cmd.Flags().GetBool("no-headers")
+		labelSelector, _ := cmd.Flags().GetString("selector")
+		watchMode, _ := cmd.Flags().GetBool("watch")
+		groupBy, _ := cmd.Flags().GetString("group-by")
+		countMode, _ := cmd.Flags().GetBool("count")
+		explode, _ := cmd.Flags().GetBool("explode")
+
+		if explode && !strings.HasPrefix(output, "jsonpath=") {
+			log.Fatalf("--explode requires -o jsonpath=...")
+		}
+
+		if (groupBy != "" || countMode) && output != "" && output != "json" {
+			log.Fatalf("Output format %q is not supported with --count or --group-by (use json)", output)
+		}
 
 		// Load cluster list
 		clusterList, err := LoadClusterList([]string{}, profile, profileContains, nameContains, nameNotContains, region, version, refresh)
@@ -98,23 +148,29 @@ Supports output formats:
 			clientcmd.ModifyConfig(loadingRules, *config, true)
 		}()
 
-		// Check if JSONPath output
-		if strings.HasPrefix(output, "jsonpath=") {
+		if watchMode {
+			// Stream changes from every cluster until interrupted
+			runWatch(clusterList, resourceType, resourceName, namespace, allNamespaces, labelSelector, startsWith, contains, output, noHeaders)
+		} else if groupBy != "" || countMode {
+			// Count resources per cluster, optionally grouped by a JSONPath
+			runAggregation(clusterList, resourceType, resourceName, namespace, allNamespaces, labelSelector, startsWith, contains, groupBy, output, noHeaders)
+		} else if strings.HasPrefix(output, "jsonpath=") {
+			// JSONPath output
 			jsonpathExpr := strings.TrimPrefix(output, "jsonpath=")
-			runJsonPathQuery(clusterList, resourceType, resourceName, jsonpathExpr, namespace, allNamespaces, startsWith, contains, noHeaders)
+			runJsonPathQuery(clusterList, resourceType, resourceName, jsonpathExpr, namespace, allNamespaces, labelSelector, startsWith, contains, explode, noHeaders)
 		} else if (resourceType == "pods" || resourceType == "pod" || resourceType == "po") && output == "" {
 			// Use existing pod listing functionality only for default output
-			runPodListing(clusterList, namespace, allNamespaces, startsWith, contains, noHeaders)
+			runPodListing(clusterList, namespace, allNamespaces, labelSelector, startsWith, contains, noHeaders)
 		} else {
 			// Generic resource listing using dynamic client
-			runGenericListing(clusterList, resourceType, resourceName, namespace, allNamespaces, startsWith, contains, output, noHeaders)
+			runGenericListing(clusterList, resourceType, resourceName, namespace, allNamespaces, labelSelector, startsWith, contains, output, noHeaders)
 		}
 
 		saveCacheToDisk()
 	},
 }
 
-func runPodListing(clusterList []data.ClusterInfo, namespace string, allNamespaces bool, startsWith, contains string, noHeaders bool) {
+func runPodListing(clusterList []data.ClusterInfo, namespace string, allNamespaces bool, labelSelector, startsWith, contains string, noHeaders bool) {
 	k8SClusterPodList := []k8s.K8SClusterPodList{}
 
 	for _, clusterInfo := range clusterList {
@@ -124,7 +180,7 @@ func runPodListing(clusterList []data.ClusterInfo, namespace string, allNamespac
 			continue
 		}
 
-		k8sPodList, err := k8s.GetPods(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName, clusterInfo.Arn, clusterInfo.Version, namespace, allNamespaces)
+		k8sPodList, err := k8s.GetPods(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName, clusterInfo.Arn, clusterInfo.Version, namespace, allNamespaces, labelSelector)
 		if err != nil {
 			log.Printf("Warning: Failed to get pods from cluster %s: %v", clusterInfo.ClusterName, err)
 			continue
@@ -150,7 +206,16 @@ func runPodListing(clusterList []data.ClusterInfo, namespace string, allNamespac
 	printutils.PrintMultiGetPods(noHeaders, k8SClusterPodList...)
 }
 
-func runGenericListing(clusterList []data.ClusterInfo, resourceType, resourceName, namespace string, allNamespaces bool, startsWith, contains, output string, noHeaders bool) {
+func runGenericListing(clusterList []data.ClusterInfo, resourceType, resourceName, namespace string, allNamespaces bool, labelSelector, startsWith, contains, output string, noHeaders bool) {
+	results := collectGenericResults(clusterList, resourceType, resourceName, namespace, allNamespaces, labelSelector, startsWith, contains)
+
+	// Print results based on output format
+	printutils.PrintGenericResults(results, output, noHeaders)
+}
+
+// collectGenericResults gets or lists the resource from every cluster using
+// the dynamic client
+func collectGenericResults(clusterList []data.ClusterInfo, resourceType, resourceName, namespace string, allNamespaces bool, labelSelector, startsWith, contains string) []data.ResourceResult {
 	results := []data.ResourceResult{}
 
 	for _, clusterInfo := range clusterList {
@@ -256,7 +321,7 @@ func runGenericListing(clusterList []data.ClusterInfo, resourceType, resourceNam
 				})
 			} else {
 				// List resources
-				list, err := resourceInterface.List(context.Background(), metav1.ListOptions{})
+				list, err := resourceInterface.List(context.Background(), metav1.ListOptions{LabelSelector: labelSelector})
 				if err != nil {
 					results = append(results, data.ResourceResult{
 						Profile:     clusterInfo.AWSProfile,
@@ -292,10 +357,13 @@ func runGenericListing(clusterList []data.ClusterInfo, resourceType, resourceNam
 		}
 	}
 
-	// Print results based on output format
-	printutils.PrintGenericResults(results, output, noHeaders)
+	return results
 }
 
+// errResourceTypeNotFound is returned by resolveResourceType when the cluster
+// does not serve the resource type
+var errResourceTypeNotFound = errors.New("not found")
+
 // resolveResourceType converts a resource type string (like "pods", "po", "deploy") to a GroupVersionResource
 func resolveResourceType(discoveryClient *discovery.DiscoveryClient, resourceType string) (schema.GroupVersionResource, bool, error) {
 	// Common short names mapping
@@ -366,7 +434,7 @@ func resolveResourceType(discoveryClient *discovery.DiscoveryClient, resourceTyp
 		}
 	}
 
-	return schema.GroupVersionResource{}, false, fmt.Errorf("resource type '%s' not found", resourceType)
+	return schema.GroupVersionResource{}, false, fmt.Errorf("resource type '%s' %w", resourceType, errResourceTypeNotFound)
 }
 
 func isClusterScoped(resource string) bool {
@@ -383,7 +451,9 @@ func isClusterScoped(resource string) bool {
 	return clusterScoped[resource]
 }
 
-func runJsonPathQuery(clusterList []data.ClusterInfo, resourceType, resourceName, jsonpathExpr, namespace string, allNamespaces bool, startsWith, contains string, noHeaders bool) {
+// parseJsonPath parses a JSONPath expression given with or without the
+// surrounding braces
+func parseJsonPath(jsonpathExpr string) (*jsonpath.JSONPath, error) {
 	// Normalize JSONPath expression
 	jsonpathExpr = strings.TrimSpace(jsonpathExpr)
 	if strings.HasPrefix(jsonpathExpr, "{") && strings.HasSuffix(jsonpathExpr, "}") {
@@ -398,8 +468,17 @@ func runJsonPathQuery(clusterList []data.ClusterInfo, resourceType, resourceName
 		parseExpr = "{" + parseExpr + "}"
 	}
 	if err := jp.Parse(parseExpr); err != nil {
-		log.Fatalf("Error parsing JSONPath expression '%s': %v", jsonpathExpr, err)
+		return nil, fmt.Errorf("Error parsing JSONPath expression '%s': %v", jsonpathExpr, err)
+	}
+	return jp, nil
+}
+
+func runJsonPathQuery(clusterList []data.ClusterInfo, resourceType, resourceName, jsonpathExpr, namespace string, allNamespaces bool, labelSelector, startsWith, contains string, explode, noHeaders bool) {
+	jp, err := parseJsonPath(jsonpathExpr)
+	if err != nil {
+		log.Fatalf("%v", err)
 	}
+	rangeExpr := isRangeExpression(jsonpathExpr)
 
 	results := []data.JsonPathResult{}
 
@@ -494,7 +573,7 @@ func runJsonPathQuery(clusterList []data.ClusterInfo, resourceType, resourceName
 				objects = append(objects, obj)
 				resourceNames = append(resourceNames, obj.GetName())
 			} else {
-				list, err := resourceInterface.List(context.Background(), metav1.ListOptions{})
+				list, err := resourceInterface.List(context.Background(), metav1.ListOptions{LabelSelector: labelSelector})
 				if err != nil {
 					results = append(results, data.JsonPathResult{
 						Profile:     clusterInfo.AWSProfile,
@@ -537,34 +616,89 @@ func runJsonPathQuery(clusterList []data.ClusterInfo, resourceType, resourceName
 					continue
 				}
 
-				if len(values) == 0 || len(values[0]) == 0 {
+				valueStrs := []string{}
+				if explode {
+					valueStrs, err = explodeJsonPathValues(jp, rangeExpr, values)
+					if err != nil {
+						results = append(results, data.JsonPathResult{
+							Profile:     clusterInfo.AWSProfile,
+							Region:      clusterInfo.Region,
+							ClusterName: clusterInfo.ClusterName,
+							Namespace:   ns,
+							Resource:    resourceNames[i],
+							Error:       fmt.Sprintf("JSONPath error: %v", err),
+						})
+						continue
+					}
+				} else if len(values) > 0 && len(values[0]) > 0 {
+					valueStrs = append(valueStrs, formatValue(values[0][0].Interface()))
+				}
+
+				if len(valueStrs) == 0 {
+					valueStrs = append(valueStrs, "<not found>")
+				}
+
+				for _, valueStr := range valueStrs {
 					results = append(results, data.JsonPathResult{
 						Profile:     clusterInfo.AWSProfile,
 						Region:      clusterInfo.Region,
 						ClusterName: clusterInfo.ClusterName,
 						Namespace:   ns,
 						Resource:    resourceNames[i],
-						Value:       "<not found>",
+						Value:       valueStr,
 					})
-					continue
 				}
+			}
+		}
+	}
 
-				val := values[0][0].Interface()
-				valueStr := formatValue(val)
+	printutils.PrintJsonPathResults(noHeaders, results)
+}
 
-				results = append(results, data.JsonPathResult{
-					Profile:     clusterInfo.AWSProfile,
-					Region:      clusterInfo.Region,
-					ClusterName: clusterInfo.ClusterName,
-					Namespace:   ns,
-					Resource:    resourceNames[i],
-					Value:       valueStr,
-				})
+// rangeExpressionRegex matches the start of a {range ...} block
+var rangeExpressionRegex = regexp.MustCompile(`\{\s*range\s`)
+
+// isRangeExpression reports whether a JSONPath expression uses {range}
+func isRangeExpression(jsonpathExpr string) bool {
+	return rangeExpressionRegex.MatchString(jsonpathExpr)
+}
+
+// explodeJsonPathValues returns one value per element found by the JSONPath.
+// Range expressions are rendered as text and split into one value per line,
+// so each iteration is expected to end with {"\n"}. Other expressions yield
+// every matched value, and arrays are expanded into their elements.
+func explodeJsonPathValues(jp *jsonpath.JSONPath, rangeExpr bool, values [][]reflect.Value) ([]string, error) {
+	valueStrs := []string{}
+
+	if rangeExpr {
+		var buf bytes.Buffer
+		for _, group := range values {
+			if err := jp.PrintResults(&buf, group); err != nil {
+				return nil, err
+			}
+		}
+		for _, line := range strings.Split(buf.String(), "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" {
+				valueStrs = append(valueStrs, line)
 			}
 		}
+		return valueStrs, nil
 	}
 
-	printutils.PrintJsonPathResults(noHeaders, results)
+	for _, group := range values {
+		for _, value := range group {
+			val := value.Interface()
+			if items, ok := val.([]interface{}); ok {
+				for _, item := range items {
+					valueStrs = append(valueStrs, formatValue(item))
+				}
+				continue
+			}
+			valueStrs = append(valueStrs, formatValue(val))
+		}
+	}
+	return valueStrs, nil
 }
 
 func formatValue(val interface{}) string {
@@ -596,6 +730,11 @@ func init() {
 	mGetCmd.Flags().StringP("resource-starts-with", "w", "", "Filter resources that start with this string")
 	mGetCmd.Flags().String("resource-contains", "", "Filter resources that contain this string")
 	mGetCmd.Flags().Bool("no-headers", false, "Don't print headers")
+	mGetCmd.Flags().StringP("selector", "l", "", "Label selector to filter resources (e.g. app=api)")
+	mGetCmd.Flags().Bool("watch", false, "Watch for changes across clusters and stream them until interrupted")
+	mGetCmd.Flags().String("group-by", "", "Count resources per value of a JSONPath expression (e.g. '{.spec.containers[*].image}')")
+	mGetCmd.Flags().Bool("count", false, "Print resource counts per cluster instead of the resources")
+	mGetCmd.Flags().Bool("explode", false, "With -o jsonpath, print one row per value when the expression matches several values or uses range")
 
 	rootCmd.AddCommand(mGetCmd)
 }
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"log"
+	"sort"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/jordiprats/kubectl-eks/pkg/printutils"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// noneKey is used for resources where the group-by JSONPath finds nothing
+const noneKey = "<none>"
+
+func runAggregation(clusterList []data.ClusterInfo, resourceType, resourceName, namespace string, allNamespaces bool, labelSelector, startsWith, contains, groupBy, output string, noHeaders bool) {
+	var jp *jsonpath.JSONPath
+	if groupBy != "" {
+		var err error
+		jp, err = parseJsonPath(groupBy)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+	}
+
+	results := collectGenericResults(clusterList, resourceType, resourceName, namespace, allNamespaces, labelSelector, startsWith, contains)
+	for _, result := range results {
+		if result.Error != "" {
+			log.Printf("Warning: Skipping results from cluster %s: %s", result.ClusterName, result.Error)
+		}
+	}
+
+	aggregation := aggregateResults(clusterList, results, jp, resourceType)
+	printutils.PrintAggregation(aggregation, output, noHeaders)
+}
+
+// aggregationClusterKey identifies a cluster in an aggregation. Cluster names
+// alone are not unique across accounts and regions.
+func aggregationClusterKey(profile, region, clusterName string) string {
+	return profile + "/" + region + "/" + clusterName
+}
+
+// aggregateResults counts the results per cluster. With a JSONPath each
+// resource is counted once under every distinct value it yields; without one
+// all resources are counted under defaultKey.
+func aggregateResults(clusterList []data.ClusterInfo, results []data.ResourceResult, jp *jsonpath.JSONPath, defaultKey string) data.ResourceAggregation {
+	aggregation := data.ResourceAggregation{
+		Clusters: []string{},
+		Totals:   make(map[string]int),
+	}
+	seen := make(map[string]bool)
+	for _, clusterInfo := range clusterList {
+		clusterKey := aggregationClusterKey(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName)
+		if !seen[clusterKey] {
+			seen[clusterKey] = true
+			aggregation.Clusters = append(aggregation.Clusters, clusterKey)
+		}
+	}
+	rows := make(map[string]*data.AggregationRow)
+
+	for _, result := range results {
+		if result.Error != "" {
+			continue
+		}
+
+		clusterKey := aggregationClusterKey(result.Profile, result.Region, result.ClusterName)
+		keys := []string{defaultKey}
+		if jp != nil {
+			keys = groupKeys(jp, result.Data)
+		}
+
+		for _, key := range keys {
+			row, exists := rows[key]
+			if !exists {
+				row = &data.AggregationRow{Key: key, Counts: make(map[string]int)}
+				rows[key] = row
+			}
+			row.Counts[clusterKey]++
+			row.Total++
+		}
+
+		aggregation.Totals[clusterKey]++
+		aggregation.Total++
+	}
+
+	for _, row := range rows {
+		aggregation.Rows = append(aggregation.Rows, *row)
+	}
+	sort.Slice(aggregation.Rows, func(i, j int) bool {
+		if aggregation.Rows[i].Total != aggregation.Rows[j].Total {
+			return aggregation.Rows[i].Total > aggregation.Rows[j].Total
+		}
+		return aggregation.Rows[i].Key < aggregation.Rows[j].Key
+	})
+
+	return aggregation
+}
+
+// groupKeys returns the distinct values the JSONPath yields for an object
+func groupKeys(jp *jsonpath.JSONPath, obj interface{}) []string {
+	values, err := jp.FindResults(obj)
+	if err != nil {
+		return []string{noneKey}
+	}
+
+	keys := []string{}
+	seen := make(map[string]bool)
+	for _, group := range values {
+		for _, value := range group {
+			key := formatValue(value.Interface())
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	if len(keys) == 0 {
+		return []string{noneKey}
+	}
+	return keys
+}
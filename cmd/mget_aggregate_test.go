@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAggregateTestPod(cluster string, images ...string) data.ResourceResult {
+	return newAggregateTestPodIn("default", "us-east-1", cluster, images...)
+}
+
+func newAggregateTestPodIn(profile, region, cluster string, images ...string) data.ResourceResult {
+	containers := []interface{}{}
+	for _, image := range images {
+		containers = append(containers, map[string]interface{}{"image": image})
+	}
+	return data.ResourceResult{
+		Profile:     profile,
+		Region:      region,
+		ClusterName: cluster,
+		Kind:        "Pod",
+		Data: map[string]interface{}{
+			"spec": map[string]interface{}{"containers": containers},
+		},
+	}
+}
+
+func newAggregateTestClusters(names ...string) []data.ClusterInfo {
+	clusterList := []data.ClusterInfo{}
+	for _, name := range names {
+		clusterList = append(clusterList, data.ClusterInfo{AWSProfile: "default", Region: "us-east-1", ClusterName: name})
+	}
+	return clusterList
+}
+
+func TestAggregateResults_GroupBy(t *testing.T) {
+	jp, err := parseJsonPath("{.spec.containers[*].image}")
+	require.NoError(t, err)
+
+	results := []data.ResourceResult{
+		newAggregateTestPod("a", "nginx:1", "envoy:1"),
+		newAggregateTestPod("a", "nginx:1", "nginx:1"),
+		newAggregateTestPod("b", "nginx:1"),
+		newAggregateTestPod("b"),
+		{Profile: "default", Region: "us-east-1", ClusterName: "b", Error: "forbidden"},
+	}
+
+	aggregation := aggregateResults(newAggregateTestClusters("a", "b", "c"), results, jp, "pods")
+
+	require.Len(t, aggregation.Rows, 3)
+	assert.Equal(t, "nginx:1", aggregation.Rows[0].Key)
+	assert.Equal(t, map[string]int{"default/us-east-1/a": 2, "default/us-east-1/b": 1}, aggregation.Rows[0].Counts, "a pod is counted once per distinct value")
+	assert.Equal(t, 3, aggregation.Rows[0].Total)
+	assert.Equal(t, noneKey, aggregation.Rows[1].Key)
+	assert.Equal(t, "envoy:1", aggregation.Rows[2].Key)
+
+	assert.Equal(t, map[string]int{"default/us-east-1/a": 2, "default/us-east-1/b": 2}, aggregation.Totals)
+	assert.Equal(t, 4, aggregation.Total)
+	assert.Equal(t, []string{"default/us-east-1/a", "default/us-east-1/b", "default/us-east-1/c"}, aggregation.Clusters)
+}
+
+func TestAggregateResults_Count(t *testing.T) {
+	results := []data.ResourceResult{
+		newAggregateTestPod("a", "nginx:1"),
+		newAggregateTestPod("b", "nginx:1"),
+		newAggregateTestPod("b", "nginx:1"),
+	}
+
+	aggregation := aggregateResults(newAggregateTestClusters("a", "b"), results, nil, "pods")
+
+	require.Len(t, aggregation.Rows, 1)
+	assert.Equal(t, "pods", aggregation.Rows[0].Key)
+	assert.Equal(t, map[string]int{"default/us-east-1/a": 1, "default/us-east-1/b": 2}, aggregation.Rows[0].Counts)
+	assert.Equal(t, 3, aggregation.Total)
+}
+
+func TestAggregateResults_SameNameInTwoRegions(t *testing.T) {
+	clusterList := []data.ClusterInfo{
+		{AWSProfile: "prod", Region: "us-east-1", ClusterName: "main"},
+		{AWSProfile: "prod", Region: "eu-west-1", ClusterName: "main"},
+	}
+	results := []data.ResourceResult{
+		newAggregateTestPodIn("prod", "us-east-1", "main", "nginx:1"),
+		newAggregateTestPodIn("prod", "eu-west-1", "main", "nginx:1"),
+		newAggregateTestPodIn("prod", "eu-west-1", "main", "nginx:1"),
+	}
+
+	aggregation := aggregateResults(clusterList, results, nil, "pods")
+
+	assert.Equal(t, []string{"prod/us-east-1/main", "prod/eu-west-1/main"}, aggregation.Clusters)
+	assert.Equal(t, map[string]int{"prod/us-east-1/main": 1, "prod/eu-west-1/main": 2}, aggregation.Totals)
+}
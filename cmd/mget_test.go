@@ -93,3 +93,63 @@ func TestFormatValue(t *testing.T) {
 		})
 	}
 }
+
+func TestExplodeJsonPathValues(t *testing.T) {
+	pod := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "app:1"},
+				map[string]interface{}{"name": "sidecar", "image": "envoy:1"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		expr     string
+		expected []string
+	}{
+		{
+			name:     "wildcard",
+			expr:     "{.spec.containers[*].image}",
+			expected: []string{"app:1", "envoy:1"},
+		},
+		{
+			name:     "single value",
+			expr:     "{.spec.containers[0].name}",
+			expected: []string{"app"},
+		},
+		{
+			name:     "array is expanded",
+			expr:     "{.spec.containers}",
+			expected: []string{`{"image":"app:1","name":"app"}`, `{"image":"envoy:1","name":"sidecar"}`},
+		},
+		{
+			name:     "range",
+			expr:     `{range .spec.containers[*]}{.name}={.image}{"\n"}{end}`,
+			expected: []string{"app=app:1", "sidecar=envoy:1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jp, err := parseJsonPath(tt.expr)
+			if !assert.NoError(t, err) {
+				return
+			}
+			values, err := jp.FindResults(pod)
+			if !assert.NoError(t, err) {
+				return
+			}
+			got, err := explodeJsonPathValues(jp, isRangeExpression(tt.expr), values)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestIsRangeExpression(t *testing.T) {
+	assert.True(t, isRangeExpression(`{range .items[*]}{.name}{end}`))
+	assert.True(t, isRangeExpression(`{ range .items[*]}{.name}{end}`))
+	assert.False(t, isRangeExpression(`{.spec.rangeField}`))
+}
@@ -0,0 +1,337 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/jordiprats/kubectl-eks/pkg/eks"
+	"github.com/jordiprats/kubectl-eks/pkg/printutils"
+	"github.com/jordiprats/kubectl-eks/pkg/status"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	watchMinBackoff = 1 * time.Second
+	watchMaxBackoff = 30 * time.Second
+	// watchMinHealthy is how long a watch without events must stay open
+	// before the backoff is reset
+	watchMinHealthy = 10 * time.Second
+)
+
+// clusterWatchTarget is the resource a cluster watch streams from
+type clusterWatchTarget struct {
+	cluster   data.ClusterInfo
+	resource  dynamic.ResourceInterface
+	namespace string
+}
+
+func runWatch(clusterList []data.ClusterInfo, resourceType, resourceName, namespace string, allNamespaces bool, labelSelector, startsWith, contains, output string, noHeaders bool) {
+	if output != "" && output != "wide" && output != "json" {
+		log.Fatalf("Output format %q is not supported with --watch (use wide or json)", output)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	printer := printutils.NewResourceEventPrinter(os.Stdout, output, noHeaders)
+
+	// Clients are built one cluster at a time since updating the kubeconfig
+	// switches the current context; the watches then run concurrently.
+	targets := []clusterWatchTarget{}
+	for _, clusterInfo := range clusterList {
+		err := eks.UpdateKubeConfig(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName, "")
+		if err != nil {
+			log.Printf("Warning: Failed to update kubeconfig for cluster %s: %v", clusterInfo.ClusterName, err)
+			continue
+		}
+
+		clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			clientcmd.NewDefaultClientConfigLoadingRules(),
+			&clientcmd.ConfigOverrides{},
+		)
+
+		restConfig, err := clientConfig.ClientConfig()
+		if err != nil {
+			continue
+		}
+
+		dynamicClient, err := dynamic.NewForConfig(restConfig)
+		if err != nil {
+			continue
+		}
+
+		discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+		if err != nil {
+			continue
+		}
+
+		gvr, namespaced, err := resolveResourceType(discoveryClient, resourceType)
+		if err != nil {
+			printer.Print(data.ResourceEvent{
+				Type: "ERROR",
+				Result: data.ResourceResult{
+					Profile:     clusterInfo.AWSProfile,
+					Region:      clusterInfo.Region,
+					ClusterName: clusterInfo.ClusterName,
+					Error:       fmt.Sprintf("Failed to resolve resource type '%s': %v", resourceType, err),
+				},
+			})
+			continue
+		}
+
+		target := clusterWatchTarget{cluster: clusterInfo}
+		if namespaced && !allNamespaces {
+			target.namespace = namespace
+			if target.namespace == "" {
+				ns, _, err := clientConfig.Namespace()
+				if err != nil {
+					ns = "default"
+				}
+				target.namespace = ns
+			}
+			target.resource = dynamicClient.Resource(gvr).Namespace(target.namespace)
+		} else {
+			target.resource = dynamicClient.Resource(gvr)
+		}
+
+		targets = append(targets, target)
+	}
+
+	listOptions := metav1.ListOptions{LabelSelector: labelSelector}
+	if resourceName != "" {
+		listOptions.FieldSelector = fields.OneTermEqualSelector("metadata.name", resourceName).String()
+	}
+
+	events := make(chan data.ResourceEvent)
+	var wg sync.WaitGroup
+
+	for _, target := range targets {
+		wg.Add(1)
+		go func(target clusterWatchTarget) {
+			defer wg.Done()
+
+			send := func(event data.ResourceEvent) {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+				}
+			}
+
+			onEvent := func(eventType watch.EventType, obj *unstructured.Unstructured) {
+				name := obj.GetName()
+				if startsWith != "" && !strings.HasPrefix(name, startsWith) {
+					return
+				}
+				if contains != "" && !strings.Contains(name, contains) {
+					return
+				}
+				send(data.ResourceEvent{
+					Type: string(eventType),
+					Result: data.ResourceResult{
+						Profile:     target.cluster.AWSProfile,
+						Region:      target.cluster.Region,
+						ClusterName: target.cluster.ClusterName,
+						Namespace:   obj.GetNamespace(),
+						Name:        name,
+						Kind:        obj.GetKind(),
+						Data:        obj.Object,
+						Status:      status.ExtractStatus(obj.Object, obj.GetKind()),
+					},
+				})
+			}
+
+			onError := func(err error) {
+				send(data.ResourceEvent{
+					Type: "ERROR",
+					Result: data.ResourceResult{
+						Profile:     target.cluster.AWSProfile,
+						Region:      target.cluster.Region,
+						ClusterName: target.cluster.ClusterName,
+						Namespace:   target.namespace,
+						Error:       err.Error(),
+					},
+				})
+			}
+
+			watchResources(ctx, target.resource, listOptions, onEvent, onError)
+		}(target)
+	}
+
+	go func() {
+		wg.Wait()
+		close(events)
+	}()
+
+	for event := range events {
+		printer.Print(event)
+	}
+}
+
+// watchResources lists and then watches the resources served by ri until ctx
+// is cancelled. Dropped watches are resumed from the last seen
+// resourceVersion; when that version has expired the resources are re-listed
+// and the differences with the last known state are emitted as events.
+func watchResources(ctx context.Context, ri dynamic.ResourceInterface, opts metav1.ListOptions, onEvent func(watch.EventType, *unstructured.Unstructured), onError func(error)) {
+	known := make(map[types.UID]*unstructured.Unstructured)
+	resourceVersion := ""
+	backoff := watchMinBackoff
+
+	for ctx.Err() == nil {
+		if resourceVersion == "" {
+			list, err := ri.List(ctx, opts)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				onError(fmt.Errorf("list failed: %w", err))
+				backoff = sleepBackoff(ctx, backoff)
+				continue
+			}
+			resourceVersion = list.GetResourceVersion()
+			for _, event := range syncKnownObjects(known, list.Items) {
+				onEvent(event.Type, event.Object)
+			}
+		}
+
+		watchOpts := opts
+		watchOpts.ResourceVersion = resourceVersion
+		watchOpts.AllowWatchBookmarks = true
+
+		w, err := ri.Watch(ctx, watchOpts)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+				resourceVersion = ""
+				continue
+			}
+			onError(fmt.Errorf("watch failed: %w", err))
+			backoff = sleepBackoff(ctx, backoff)
+			continue
+		}
+
+		opened := time.Now()
+		watchedVersion := resourceVersion
+		resourceVersion, err = consumeWatch(ctx, w, known, resourceVersion, onEvent)
+		w.Stop()
+
+		// Only a watch that delivered events or stayed open for a while
+		// resets the backoff, so endpoints closing watches right away are
+		// not reconnected to in a tight loop
+		healthy := resourceVersion != watchedVersion || time.Since(opened) >= watchMinHealthy
+		if healthy {
+			backoff = watchMinBackoff
+		}
+
+		if err != nil {
+			if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+				resourceVersion = ""
+				continue
+			}
+			onError(fmt.Errorf("watch interrupted: %w", err))
+			backoff = sleepBackoff(ctx, backoff)
+		} else if !healthy {
+			backoff = sleepBackoff(ctx, backoff)
+		}
+	}
+}
+
+// consumeWatch forwards watch events until the watch ends and returns the
+// last resourceVersion seen, together with the error the server sent if any.
+func consumeWatch(ctx context.Context, w watch.Interface, known map[types.UID]*unstructured.Unstructured, resourceVersion string, onEvent func(watch.EventType, *unstructured.Unstructured)) (string, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return resourceVersion, nil
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return resourceVersion, nil
+			}
+
+			if event.Type == watch.Error {
+				return resourceVersion, apierrors.FromObject(event.Object)
+			}
+
+			obj, ok := event.Object.(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+			resourceVersion = obj.GetResourceVersion()
+
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				known[obj.GetUID()] = obj
+				onEvent(event.Type, obj)
+			case watch.Deleted:
+				delete(known, obj.GetUID())
+				onEvent(event.Type, obj)
+			}
+		}
+	}
+}
+
+type objectEvent struct {
+	Type   watch.EventType
+	Object *unstructured.Unstructured
+}
+
+// syncKnownObjects replaces the known objects with a freshly listed set and
+// returns the events needed to go from the previous state to the new one.
+func syncKnownObjects(known map[types.UID]*unstructured.Unstructured, items []unstructured.Unstructured) []objectEvent {
+	events := []objectEvent{}
+	seen := make(map[types.UID]bool)
+
+	for i := range items {
+		obj := &items[i]
+		uid := obj.GetUID()
+		seen[uid] = true
+
+		previous, exists := known[uid]
+		if !exists {
+			events = append(events, objectEvent{Type: watch.Added, Object: obj})
+		} else if previous.GetResourceVersion() != obj.GetResourceVersion() {
+			events = append(events, objectEvent{Type: watch.Modified, Object: obj})
+		}
+		known[uid] = obj
+	}
+
+	for uid, obj := range known {
+		if !seen[uid] {
+			events = append(events, objectEvent{Type: watch.Deleted, Object: obj})
+			delete(known, uid)
+		}
+	}
+
+	return events
+}
+
+// sleepBackoff waits for the given backoff (or until ctx is done) and returns
+// the next, doubled, backoff capped at watchMaxBackoff
+func sleepBackoff(ctx context.Context, backoff time.Duration) time.Duration {
+	select {
+	case <-ctx.Done():
+	case <-time.After(backoff):
+	}
+	backoff *= 2
+	if backoff > watchMaxBackoff {
+		backoff = watchMaxBackoff
+	}
+	return backoff
+}
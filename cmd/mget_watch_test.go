@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var watchTestGVR = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "configmaps"}
+
+func newWatchTestObject(name, uid, resourceVersion string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace("default")
+	obj.SetName(name)
+	obj.SetUID(types.UID(uid))
+	obj.SetResourceVersion(resourceVersion)
+	return obj
+}
+
+func TestSyncKnownObjects(t *testing.T) {
+	known := map[types.UID]*unstructured.Unstructured{}
+
+	events := syncKnownObjects(known, []unstructured.Unstructured{
+		*newWatchTestObject("a", "uid-a", "1"),
+		*newWatchTestObject("b", "uid-b", "1"),
+	})
+	require.Len(t, events, 2)
+	assert.Equal(t, watch.Added, events[0].Type)
+	assert.Equal(t, watch.Added, events[1].Type)
+
+	events = syncKnownObjects(known, []unstructured.Unstructured{
+		*newWatchTestObject("a", "uid-a", "1"),
+		*newWatchTestObject("c", "uid-c", "3"),
+		*newWatchTestObject("b", "uid-b", "2"),
+	})
+	got := map[string]watch.EventType{}
+	for _, e := range events {
+		got[e.Object.GetName()] = e.Type
+	}
+	assert.Equal(t, map[string]watch.EventType{"b": watch.Modified, "c": watch.Added}, got)
+
+	events = syncKnownObjects(known, []unstructured.Unstructured{
+		*newWatchTestObject("c", "uid-c", "3"),
+	})
+	got = map[string]watch.EventType{}
+	for _, e := range events {
+		got[e.Object.GetName()] = e.Type
+	}
+	assert.Equal(t, map[string]watch.EventType{"a": watch.Deleted, "b": watch.Deleted}, got)
+	assert.Len(t, known, 1)
+}
+
+// recordedEvents collects the events emitted by watchResources
+type recordedEvents struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recordedEvents) add(eventType watch.EventType, obj *unstructured.Unstructured) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, string(eventType)+" "+obj.GetName())
+}
+
+func (r *recordedEvents) snapshot() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.events...)
+}
+
+func TestWatchResources_RelistOnExpiredResourceVersion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{watchTestGVR: "ConfigMapList"},
+		newWatchTestObject("a", "uid-a", "1"),
+	)
+
+	watchers := make(chan *watch.FakeWatcher, 2)
+	client.PrependWatchReactor("configmaps", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w := watch.NewFakeWithChanSize(10, false)
+		watchers <- w
+		return true, w, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	recorded := &recordedEvents{}
+	errs := make(chan error, 10)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watchResources(ctx, client.Resource(watchTestGVR).Namespace("default"), metav1.ListOptions{}, recorded.add, func(err error) { errs <- err })
+	}()
+
+	// First watch: a live change, then the resourceVersion expires
+	first := <-watchers
+	first.Add(newWatchTestObject("b", "uid-b", "2"))
+	expired := apierrors.NewResourceExpired("too old resource version")
+	first.Error(&expired.ErrStatus)
+
+	// The relist sees only "a", so "b" must be reported as deleted
+	second := <-watchers
+	second.Modify(newWatchTestObject("a", "uid-a", "5"))
+
+	assert.Eventually(t, func() bool {
+		return len(recorded.snapshot()) == 4
+	}, 2*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+
+	assert.Equal(t, []string{"ADDED a", "ADDED b", "DELETED b", "MODIFIED a"}, recorded.snapshot())
+	assert.Empty(t, errs, "expired resourceVersions are handled without reporting errors")
+}
+
+func TestWatchResources_ReconnectAfterClose(t *testing.T) {
+	scheme := runtime.NewScheme()
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{watchTestGVR: "ConfigMapList"},
+	)
+
+	watchers := make(chan *watch.FakeWatcher, 2)
+	resourceVersions := make(chan string, 2)
+	client.PrependWatchReactor("configmaps", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		if watchAction, ok := action.(k8stesting.WatchActionImpl); ok {
+			resourceVersions <- watchAction.WatchRestrictions.ResourceVersion
+		}
+		w := watch.NewFakeWithChanSize(10, false)
+		watchers <- w
+		return true, w, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	recorded := &recordedEvents{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watchResources(ctx, client.Resource(watchTestGVR).Namespace("default"), metav1.ListOptions{}, recorded.add, func(error) {})
+	}()
+
+	first := <-watchers
+	<-resourceVersions
+	first.Add(newWatchTestObject("a", "uid-a", "7"))
+	first.Stop()
+
+	<-watchers
+	assert.Equal(t, "7", <-resourceVersions, "the watch resumes from the last seen resourceVersion")
+
+	cancel()
+	<-done
+
+	assert.Equal(t, []string{"ADDED a"}, recorded.snapshot())
+}
+
+func TestWatchResources_BackoffAfterEmptyClose(t *testing.T) {
+	scheme := runtime.NewScheme()
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{watchTestGVR: "ConfigMapList"},
+	)
+
+	watchers := make(chan *watch.FakeWatcher, 2)
+	client.PrependWatchReactor("configmaps", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w := watch.NewFakeWithChanSize(10, false)
+		watchers <- w
+		return true, w, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watchResources(ctx, client.Resource(watchTestGVR).Namespace("default"), metav1.ListOptions{}, func(watch.EventType, *unstructured.Unstructured) {}, func(error) {})
+	}()
+
+	// Closed by the server before delivering anything
+	first := <-watchers
+	closed := time.Now()
+	first.Stop()
+
+	select {
+	case <-watchers:
+		assert.GreaterOrEqual(t, time.Since(closed), watchMinBackoff, "the watch is reopened after the backoff")
+	case <-time.After(2 * watchMinBackoff):
+		t.Fatal("the watch was not reopened")
+	}
+
+	cancel()
+	<-done
+}
@@ -1,19 +1,23 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"os"
 	"regexp"
 	"strings"
 
 	"github.com/jordiprats/kubectl-eks/pkg/ec2"
 	"github.com/jordiprats/kubectl-eks/pkg/eks"
+	"github.com/jordiprats/kubectl-eks/pkg/k8s"
 	"github.com/jordiprats/kubectl-eks/pkg/printutils"
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
 )
 
 var nodegroupsCmd = &cobra.Command{
-	Use:     "nodegroups",
+	Use:     "nodegroups [cluster-arn | nodegroup-name]",
 	Aliases: []string{"ng"},
 	Short:   "List EKS managed node groups",
 	Long: `List EKS managed node groups with configuration and status details.
@@ -22,11 +26,37 @@ Displays node group name, status, instance types, scaling configuration
 (min/max/desired size), AMI type, capacity type (On-Demand/Spot), and
 current Kubernetes version.
 
-Use this to audit node group configurations and identify scaling settings.`,
+Use this to audit node group configurations and identify scaling settings.
+
+With -o wide, the latest EKS optimized AMI release for the AMI type and
+Kubernetes version of each node group is resolved from the SSM public
+parameters, showing how many releases behind the node group is, along with
+node groups whose Kubernetes version lags the control plane.
+
+Given a node group name, describes that node group of the current cluster:
+health issues, subnets, labels, taints, update and remote access settings,
+disk size, Auto Scaling groups and instance types, along with its live node
+count from the Kubernetes API.`,
+	Example: `  # List the node groups of the current cluster
+  kubectl eks nodegroups
+
+  # Check AMI release freshness and version skew
+  kubectl eks nodegroups -o wide
+
+  # Describe a node group of the current cluster
+  kubectl eks nodegroups workers`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		clusterArn := ""
+		nodeGroupName := ""
+
+		// A single argument is either a cluster ARN or a node group name
+		clusterFromArgs := len(args) == 1 && strings.HasPrefix(strings.TrimSpace(args[0]), "arn:")
+		if len(args) == 1 && !clusterFromArgs {
+			nodeGroupName = strings.TrimSpace(args[0])
+		}
 
-		if len(args) != 1 {
+		if !clusterFromArgs {
 			// Load Kubernetes configuration
 			config, err := KubernetesConfigFlags.ToRawKubeConfigLoader().RawConfig()
 			if err != nil {
@@ -56,7 +86,7 @@ Use this to audit node group configurations and identify scaling settings.`,
 
 		matches := re.FindStringSubmatch(clusterArn)
 		if matches == nil {
-			if len(args) != 1 {
+			if !clusterFromArgs {
 				fmt.Printf("Current cluster is not an EKS cluster\n")
 			} else {
 				fmt.Printf("Invalid cluster ARN: %q\n", clusterArn)
@@ -82,7 +112,23 @@ Use this to audit node group configurations and identify scaling settings.`,
 			noHeaders = false
 		}
 
-		if ami != "" {
+		if nodeGroupName != "" {
+			ngInfo, err := eks.GetEKSNodeGroup(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName, nodeGroupName)
+			if err != nil {
+				fmt.Printf("Error describing nodegroup: %s\n", err.Error())
+				os.Exit(1)
+			}
+
+			counts, err := currentNodeGroupNodes()
+			if err != nil {
+				log.Printf("Warning: Failed to count the nodes of nodegroup %s: %v", ngInfo.Name, err)
+			} else {
+				ngInfo.Nodes = counts[ngInfo.Name].Total
+				ngInfo.ReadyNodes = counts[ngInfo.Name].Ready
+			}
+
+			printutils.PrintNodeGroupDetails(noHeaders, *ngInfo)
+		} else if ami != "" {
 			amiInfo, err := ec2.GetAMIInfo(clusterInfo.AWSProfile, clusterInfo.Region, ami)
 			if err != nil {
 				fmt.Printf("Error getting AMI info: %s\n", err.Error())
@@ -99,13 +145,45 @@ Use this to audit node group configurations and identify scaling settings.`,
 				return
 			}
 
-			printutils.PrintNodeGroup(noHeaders, clusterNGList...)
+			output, _ := cmd.Flags().GetString("output")
+			wide := output == "wide"
+
+			if wide {
+				resolver, err := eks.NewAMIReleaseResolver(clusterInfo.AWSProfile, clusterInfo.Region)
+				if err != nil {
+					log.Fatalf("Error creating SSM client: %v", err)
+				}
+				for i := range clusterNGList {
+					if err := resolver.CheckNodeGroup(&clusterNGList[i], clusterInfo.Version); err != nil {
+						log.Printf("Warning: Failed to resolve the latest AMI release for nodegroup %s: %v", clusterNGList[i].Name, err)
+					}
+				}
+			}
+
+			printutils.PrintNodeGroup(noHeaders, wide, clusterNGList...)
 		}
 	},
 }
 
+// currentNodeGroupNodes counts the nodes of each node group of the cluster
+// of the current context
+func currentNodeGroupNodes() (map[string]k8s.NodeGroupNodes, error) {
+	restConfig, err := KubernetesConfigFlags.ToRESTConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	return k8s.CountNodeGroupNodes(context.TODO(), clientset)
+}
+
 func init() {
 	nodegroupsCmd.Flags().StringP("ami", "a", "", "Describe AMI used by the nodegroup")
+	nodegroupsCmd.Flags().StringP("output", "o", "", "Output format: wide")
 
 	rootCmd.AddCommand(nodegroupsCmd)
 }
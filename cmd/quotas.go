@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"log"
+	"strings"
 
 	"github.com/jordiprats/kubectl-eks/pkg/data"
 	"github.com/jordiprats/kubectl-eks/pkg/k8s"
@@ -54,10 +55,13 @@ Helps identify quota constraints and capacity planning opportunities.`,
 			namespace = ""
 		}
 
-		quotas, err := k8s.GetResourceQuotas(context.Background(), namespace)
+		quotas, inaccessible, err := k8s.GetResourceQuotas(context.Background(), namespace)
 		if err != nil {
 			log.Fatalf("Error getting resource quotas: %v", err)
 		}
+		if len(inaccessible) > 0 {
+			log.Printf("Warning: Unable to list resource quotas in namespaces: %s", strings.Join(inaccessible, ", "))
+		}
 
 		if len(quotas) == 0 {
 			if namespace == "" {
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/jordiprats/kubectl-eks/pkg/eks"
+	"github.com/jordiprats/kubectl-eks/pkg/exporter"
+	"github.com/jordiprats/kubectl-eks/pkg/k8s"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Export fleet health and stats as Prometheus metrics",
+	Long: `Periodically run the mcheck health checks and the stats collectors over the
+clusters that match a filter and serve the results over HTTP:
+
+  /metrics          Prometheus metrics
+  /api/v1/clusters  the last collection as JSON
+  /healthz          liveness probe
+
+Metrics include:
+  - kubectl_eks_cluster_up and kubectl_eks_cluster_info (with the version)
+  - kubectl_eks_resources_checked by kind and kubectl_eks_resources_unhealthy
+    by kind and namespace
+  - kubectl_eks_nodes by state, kubectl_eks_cpu_cores and kubectl_eks_memory_bytes
+  - kubectl_eks_pods, kubectl_eks_pods_not_running, kubectl_eks_pods_with_restarts
+    and kubectl_eks_namespaces
+  - kubectl_eks_insights by status
+  - kubectl_eks_cluster_collect_errors, kubectl_eks_last_collection_timestamp_seconds
+    and kubectl_eks_collection_duration_seconds
+
+Up to --parallel clusters are collected at the same time. Each cluster gets a
+private kubeconfig file in a temporary directory, so serve never changes the
+current context of your kubeconfig. The cluster list is loaded once at
+startup. Until the first collection finishes /metrics and /api/v1/clusters
+answer 503.`,
+	Example: `  # Export every production cluster on port 9090
+  kubectl eks serve --listen :9090 --name-contains prod
+
+  # Collect every 10 minutes, also checking cert-manager certificates
+  kubectl eks serve --interval 10m --kinds certificates`,
+	Run: func(cmd *cobra.Command, args []string) {
+		refresh, _ := cmd.Flags().GetBool("refresh")
+		profile, _ := cmd.Flags().GetString("profile")
+		profileContains, _ := cmd.Flags().GetString("profile-contains")
+		nameContains, _ := cmd.Flags().GetString("name-contains")
+		nameNotContains, _ := cmd.Flags().GetString("name-not-contains")
+		region, _ := cmd.Flags().GetString("region")
+		version, _ := cmd.Flags().GetString("version")
+		namespace, _ := cmd.Flags().GetString("namespace")
+		listen, _ := cmd.Flags().GetString("listen")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		customKinds, _ := cmd.Flags().GetStringSlice("kinds")
+		withInsights, _ := cmd.Flags().GetBool("insights")
+		parallelism, _ := cmd.Flags().GetInt("parallel")
+
+		if interval <= 0 {
+			log.Fatalf("--interval must be positive")
+		}
+		if parallelism < 1 {
+			log.Fatalf("--parallel must be at least 1")
+		}
+
+		clusterList, err := LoadClusterList([]string{}, profile, profileContains, nameContains, nameNotContains, region, version, refresh)
+		if err != nil {
+			log.Fatalf("Error loading cluster list: %v", err)
+		}
+		saveCacheToDisk()
+
+		configs, err := newClusterConfigs()
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		defer configs.cleanup()
+
+		server := &exporter.Server{}
+		httpServer := &http.Server{Addr: listen, Handler: server.Handler()}
+		go func() {
+			if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				configs.cleanup()
+				log.Fatalf("Error listening on %s: %v", listen, err)
+			}
+		}()
+		log.Printf("Serving metrics for %d clusters on %s every %s", len(clusterList), listen, interval)
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		for {
+			snapshot := collectFleet(configs, clusterList, namespace, customKinds, withInsights, parallelism)
+			server.Update(snapshot)
+			log.Printf("Collected %d clusters in %s", len(snapshot.Clusters), snapshot.Duration.Truncate(time.Second))
+
+			select {
+			case <-ctx.Done():
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				if err := httpServer.Shutdown(shutdownCtx); err != nil {
+					log.Printf("Warning: Unable to shut down the HTTP server: %v", err)
+				}
+				return
+			case <-time.After(interval):
+			}
+		}
+	},
+}
+
+// collectFleet runs the health checks, node stats, Kubernetes stats and
+// (optionally) EKS insights collectors over the clusters, up to parallelism
+// clusters at a time
+func collectFleet(configs *clusterConfigs, clusterList []data.ClusterInfo, namespace string, customKinds []string, withInsights bool, parallelism int) exporter.Snapshot {
+	start := time.Now()
+
+	clusters := make([]exporter.Cluster, len(clusterList))
+	forEachCluster(clusterList, parallelism, func(i int, info data.ClusterInfo) {
+		clusters[i] = collectCluster(configs, info, namespace, customKinds, withInsights)
+	})
+
+	return exporter.Snapshot{CollectedAt: time.Now(), Duration: time.Since(start), Clusters: clusters}
+}
+
+// collectCluster runs every collector on a single cluster
+func collectCluster(configs *clusterConfigs, info data.ClusterInfo, namespace string, customKinds []string, withInsights bool) exporter.Cluster {
+	restConfig, err := configs.restConfig(info)
+	if err != nil {
+		summary := unreachableClusterSummary(info, err)
+		return exporter.Cluster{
+			Info:      info,
+			Health:    summary,
+			Unhealthy: []data.HealthCheckResult{},
+			Errors:    append([]string{}, summary.Errors...),
+		}
+	}
+
+	results, summary := checkClusterHealth(info, restConfig, namespace, allHealthChecks, customKinds, defaultRestartThreshold, nil)
+	// Node stats are best effort, as in list -o wide
+	_ = addClusterNodeStats(&info, restConfig)
+
+	cluster := exporter.Cluster{
+		Info:      info,
+		Health:    summary,
+		Unhealthy: clusterUnhealthyResults(results, info),
+		Errors:    append([]string{}, summary.Errors...),
+	}
+
+	if cluster.Reachable() {
+		stats, err := k8s.GetK8sStatsWithConfig(restConfig, info.AWSProfile, info.Region, info.ClusterName, info.Arn, info.Version)
+		if err != nil {
+			cluster.Errors = append(cluster.Errors, fmt.Sprintf("stats: %v", err))
+		} else {
+			cluster.Stats = stats
+			for _, statsErr := range stats.Errors {
+				cluster.Errors = append(cluster.Errors, fmt.Sprintf("stats: %s", statsErr))
+			}
+		}
+	}
+
+	if withInsights {
+		insightsList, err := eks.GetEKSInsights(info.AWSProfile, info.Region, info.ClusterName)
+		if err != nil {
+			cluster.Errors = append(cluster.Errors, fmt.Sprintf("insights: %v", err))
+		} else {
+			cluster.Insights = make(map[string]int)
+			for _, insight := range insightsList {
+				cluster.Insights[insight.Status]++
+			}
+		}
+	}
+
+	return cluster
+}
+
+// clusterUnhealthyResults returns the unhealthy results of a single cluster
+func clusterUnhealthyResults(results []data.HealthCheckResult, cluster data.ClusterInfo) []data.HealthCheckResult {
+	unhealthy := []data.HealthCheckResult{}
+	for _, r := range results {
+		if !r.IsHealthy && r.Profile == cluster.AWSProfile && r.Region == cluster.Region && r.ClusterName == cluster.ClusterName {
+			unhealthy = append(unhealthy, r)
+		}
+	}
+	return unhealthy
+}
+
+func init() {
+	serveCmd.Flags().BoolP("refresh", "u", false, "Do not use cached data, refresh from AWS")
+	serveCmd.Flags().StringP("profile", "p", "", "AWS profile to use")
+	serveCmd.Flags().StringP("profile-contains", "q", "", "AWS profile contains string")
+	serveCmd.Flags().StringP("name-contains", "c", "", "Cluster name contains string")
+	serveCmd.Flags().StringP("name-not-contains", "x", "", "Cluster name does not contain string")
+	serveCmd.Flags().StringP("region", "r", "", "AWS region to use")
+	serveCmd.Flags().StringP("version", "v", "", "Filter by EKS version")
+	serveCmd.Flags().StringP("namespace", "n", "", "Kubernetes namespace to health check (default: all namespaces)")
+	serveCmd.Flags().String("listen", ":9090", "Address to serve the metrics on")
+	serveCmd.Flags().Duration("interval", 5*time.Minute, "Time between collections")
+	serveCmd.Flags().StringSlice("kinds", []string{}, "Other resource types to health check by their conditions, e.g. certificates,externalsecrets")
+	serveCmd.Flags().Bool("insights", true, "Collect EKS insights")
+	serveCmd.Flags().Int("parallel", 4, "Number of clusters to collect at the same time")
+
+	rootCmd.AddCommand(serveCmd)
+}
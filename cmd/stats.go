@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"fmt"
 	"log"
+	"strings"
 
+	"github.com/jordiprats/kubectl-eks/pkg/data"
 	"github.com/jordiprats/kubectl-eks/pkg/eks"
 	"github.com/jordiprats/kubectl-eks/pkg/k8s"
 	"github.com/jordiprats/kubectl-eks/pkg/printutils"
@@ -16,14 +19,27 @@ var statsCmd = &cobra.Command{
 	Long: `Display aggregated statistics for EKS clusters including resource counts and usage.
 
 Shows summary metrics such as:
-  - Node counts by type and status
-  - Pod counts across namespaces
-  - Resource requests and limits (CPU/memory)
+  - Namespace, node and pod counts
+  - Nodes not ready, pods not running and pods with restarts
   - Kubernetes version
-  - Cluster age and uptime
 
-Supports filtering to show stats for specific clusters or aggregate across
-multiple clusters matching your criteria.`,
+Use -o wide for a fleet report that adds:
+  - Workloads by kind (Deployments, StatefulSets, DaemonSets, Jobs, CronJobs)
+  - Pods by QoS class (Guaranteed, Burstable, BestEffort)
+  - Pods with a container missing CPU or memory requests or limits
+  - Pods running on Fargate vs EC2 nodes
+  - PVC count and total capacity
+  - Services by type
+
+Supports filtering to show stats for specific clusters. When more than one
+cluster matches, a TOTAL row adds them up. Clusters or resources that cannot
+be read are reported in the ERRORS column instead of stopping the command.
+Use -o json for the raw per-cluster stats.`,
+	Example: `  # Stats of the current cluster
+  kubectl eks stats
+
+  # Fleet report for all production clusters
+  kubectl eks stats --name-contains prod -o wide`,
 	Run: func(cmd *cobra.Command, args []string) {
 		refresh, _ := cmd.Flags().GetBool("refresh")
 
@@ -72,17 +88,21 @@ multiple clusters matching your criteria.`,
 		// current k8s context
 		k8sStatsList := []k8s.K8Sstats{}
 		for _, clusterInfo := range clusterList {
-			err := eks.UpdateKubeConfig(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName, "")
+			stats, err := collectClusterStats(clusterInfo)
 			if err != nil {
-				continue
-			}
-
-			stats, err := k8s.GetK8sStats(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName, clusterInfo.Arn, clusterInfo.Version)
-			if err != nil {
-				continue
-			} else {
-				k8sStatsList = append(k8sStatsList, *stats)
+				log.Printf("Warning: Unable to get stats for cluster %s: %v", clusterInfo.ClusterName, err)
+				stats = &k8s.K8Sstats{
+					AWSProfile:  clusterInfo.AWSProfile,
+					Region:      clusterInfo.Region,
+					ClusterName: clusterInfo.ClusterName,
+					Arn:         clusterInfo.Arn,
+					Version:     clusterInfo.Version,
+					Errors:      []string{err.Error()},
+				}
+			} else if len(stats.Errors) > 0 {
+				log.Printf("Warning: Incomplete stats for cluster %s: %s", clusterInfo.ClusterName, strings.Join(stats.Errors, "; "))
 			}
+			k8sStatsList = append(k8sStatsList, *stats)
 		}
 
 		// Restore the previous context
@@ -101,12 +121,23 @@ multiple clusters matching your criteria.`,
 			noHeaders = false
 		}
 
-		printutils.PrintK8SStats(noHeaders, k8sStatsList...)
+		output, _ := cmd.Flags().GetString("output")
+
+		printutils.PrintK8SStats(noHeaders, output, k8sStatsList...)
 
 		saveCacheToDisk()
 	},
 }
 
+// collectClusterStats switches the kubeconfig to the cluster and collects its stats
+func collectClusterStats(clusterInfo data.ClusterInfo) (*k8s.K8Sstats, error) {
+	err := eks.UpdateKubeConfig(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to update kubeconfig: %w", err)
+	}
+	return k8s.GetK8sStats(clusterInfo.AWSProfile, clusterInfo.Region, clusterInfo.ClusterName, clusterInfo.Arn, clusterInfo.Version)
+}
+
 func init() {
 	statsCmd.Flags().BoolP("refresh", "u", false, "Do not use cached data, refresh from AWS")
 	statsCmd.Flags().StringP("profile", "p", "", "AWS profile to use")
@@ -115,6 +146,7 @@ func init() {
 	statsCmd.Flags().StringP("name-not-contains", "x", "", "Cluster name does not contain string")
 	statsCmd.Flags().StringP("region", "r", "", "AWS region to use")
 	statsCmd.Flags().StringP("version", "v", "", "Filter by EKS version")
+	statsCmd.Flags().StringP("output", "o", "", "Output format: wide|json")
 
 	rootCmd.AddCommand(statsCmd)
 }
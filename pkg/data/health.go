@@ -1,5 +1,7 @@
 package data
 
+import "time"
+
 // HealthCheckResult contains the health status of a single resource
 type HealthCheckResult struct {
 	Profile     string
@@ -12,6 +14,18 @@ type HealthCheckResult struct {
 	Status      string
 	Message     string
 	IsHealthy   bool
+	// The fields below are not printed, they are inputs for health policies
+	Labels            map[string]string
+	PodTemplateLabels map[string]string
+	CreatedAt         time.Time
+	// Highest restart count of a container last terminated within the
+	// mcheck --restart-window
+	RecentRestarts  int32
+	DesiredReplicas int32
+	ReadyReplicas   int32
+	// Pending pods only waiting to be scheduled or for their containers to
+	// be created, without container errors
+	Starting bool
 }
 
 // ClusterHealthSummary contains aggregated health status for a cluster
@@ -29,5 +43,49 @@ type ClusterHealthSummary struct {
 	HealthyDaemonSets   int
 	TotalReplicaSets    int
 	HealthyReplicaSets  int
+	TotalJobs           int
+	HealthyJobs         int
+	TotalCronJobs       int
+	HealthyCronJobs     int
+	TotalPVCs           int
+	HealthyPVCs         int
+	TotalPDBs           int
+	HealthyPDBs         int
+	TotalHPAs           int
+	HealthyHPAs         int
+	TotalServices       int
+	HealthyServices     int
 	OverallStatus       string
+	// CustomTotals and CustomHealthy count the --kinds resources by kind
+	CustomTotals  map[string]int
+	CustomHealthy map[string]int
+	// InaccessibleNamespaces lists namespaces RBAC did not allow reading
+	InaccessibleNamespaces []string
+	// Errors lists what could not be checked (unreachable cluster, failed lists)
+	Errors []string
+}
+
+// HealthSnapshot is a recorded mcheck run. Only the unhealthy results are
+// kept in full; the summaries tell which clusters were checked and Healthy
+// lists the healthy resources of each cluster, by profile/region/name, as
+// kind/namespace/name.
+type HealthSnapshot struct {
+	Time      time.Time              `json:"time"`
+	Summaries []ClusterHealthSummary `json:"summaries"`
+	Results   []HealthCheckResult    `json:"results"`
+	Healthy   map[string][]string    `json:"healthy,omitempty"`
+}
+
+// HealthChange is a resource that broke, recovered or disappeared between
+// two runs
+type HealthChange struct {
+	Time        time.Time
+	Profile     string
+	Region      string
+	ClusterName string
+	Change      string
+	Kind        string
+	Namespace   string
+	Name        string
+	Message     string
 }
@@ -16,41 +16,231 @@ type KarpenterNodePoolInfo struct {
 	ConsolidationMode string
 	ExpireAfter       string
 	Weight            int32
+	// Resources currently provisioned by the NodePool, from its status
+	CPUUsage    string
+	MemoryUsage string
+	// Percentage of spec.limits consumed, -1 when there is no limit
+	CPUPercent    float64
+	MemoryPercent float64
+	// NodeClaims of the NodePool by karpenter.sh/capacity-type
+	NodeCount           int
+	NodesByCapacityType map[string]int
+	// Scheduling constraints of the NodePool template
+	Requirements []KarpenterRequirement
+	Labels       map[string]string
+	Taints       []KarpenterTaint
+}
+
+type KarpenterRequirement struct {
+	Key      string
+	Operator string
+	Values   []string
+}
+
+type KarpenterTaint struct {
+	Key    string
+	Value  string
+	Effect string
 }
 
 type KarpenterNodeClaimInfo struct {
+	Profile       string
+	Region        string
+	ClusterName   string
+	Name          string
+	NodeName      string
+	NodePoolName  string
+	NodeClassName string
+	InstanceType  string
+	Zone          string
+	CapacityType  string
+	AMI           string
+	Status        string
+	Age           time.Time
+	Drifted       bool
+}
+
+type KarpenterAMIUsageInfo struct {
+	Profile       string
+	Region        string
+	ClusterName   string
+	NodePoolName  string
+	NodeClassName string
+	CurrentAMI    string
+	NodeCount     int
+	NodeNames     []string
+	// Filled in from DescribeImages for -o wide
+	AMIName         string
+	Architecture    string
+	CreationDate    time.Time
+	DeprecationTime string
+	AgeDays         int
+	// Newest AMI selected by the EC2NodeClass for the same architecture,
+	// empty when it cannot be told
+	LatestAMI string
+	// The AMI is older than the --max-age threshold
+	Outdated bool
+}
+
+type KarpenterDriftInfo struct {
+	Profile       string
+	Region        string
+	ClusterName   string
+	ResourceType  string
+	Name          string
+	NodeName      string
+	NodePoolName  string
+	NodeClassName string
+	// DriftedSince is the last transition time of the Drifted condition
+	DriftedSince time.Time
+	// Reason and Message of the Drifted condition
+	Reason  string
+	Message string
+	// Cause points at what changed in the NodePool or EC2NodeClass
+	Cause string
+	// BlockedBy lists what prevents Karpenter from replacing the node:
+	// do-not-disrupt annotations and PodDisruptionBudgets allowing no
+	// disruptions
+	BlockedBy []string
+}
+
+// KarpenterDriftSummary counts the drifted NodeClaims of a NodePool
+type KarpenterDriftSummary struct {
 	Profile      string
 	Region       string
 	ClusterName  string
-	Name         string
-	NodeName     string
 	NodePoolName string
-	InstanceType string
-	Zone         string
-	CapacityType string
-	AMI          string
-	Status       string
-	Age          time.Time
-	Drifted      bool
+	NodeClaims   int
+	Drifted      int
+	Blocked      int
+	// Reasons counts the drifted NodeClaims by Drifted condition reason
+	Reasons map[string]int
 }
 
-type KarpenterAMIUsageInfo struct {
+type KarpenterNodeClassInfo struct {
+	Profile     string
+	Region      string
+	ClusterName string
+	Name        string
+	// Selector terms as written in the spec, one entry per term
+	AMISelectorTerms           []string
+	SubnetSelectorTerms        []string
+	SecurityGroupSelectorTerms []string
+	// What the selectors resolved to, from the status
+	AMIs           []string
+	Subnets        []string
+	SecurityGroups []string
+	Role           string
+	// InstanceProfile is the one set in the spec or, when a role is used,
+	// the one Karpenter created for it
+	InstanceProfile     string
+	BlockDeviceMappings []string
+	Ready               string
+	// Conditions that are not True, as Type: Reason
+	FailingConditions []string
+	Age               time.Time
+}
+
+// KarpenterBudgetInfo is a disruption budget of a NodePool
+type KarpenterBudgetInfo struct {
+	Nodes    string
+	Schedule string
+	Duration string
+	Reasons  []string
+	// Active is true when the budget applies now: it has no schedule or the
+	// current time falls within duration of its last scheduled start
+	Active bool
+}
+
+// KarpenterDisruptionInfo explains what Karpenter may disrupt in a NodePool
+type KarpenterDisruptionInfo struct {
+	Profile             string
+	Region              string
+	ClusterName         string
+	NodePoolName        string
+	ConsolidationPolicy string
+	ConsolidateAfter    string
+	Budgets             []KarpenterBudgetInfo
+	// AllowedDisruptions is the number of nodes that may be disrupted right
+	// now for each reason (Empty, Drifted, Underutilized)
+	AllowedDisruptions map[string]int
+	NodeClaims         int
+	// Disrupting NodeClaims are being deleted or their nodes are tainted
+	// karpenter.sh/disrupted
+	Disrupting int
+	// Candidates are the NodeClaims Karpenter considers consolidatable
+	Candidates int
+	// Blocked NodeClaims cannot be drained because of do-not-disrupt
+	// annotations or PodDisruptionBudgets
+	Blocked int
+}
+
+// KarpenterDisruptionNodeInfo is a NodeClaim that is being disrupted, could
+// be consolidated or cannot be drained
+type KarpenterDisruptionNodeInfo struct {
+	Profile       string
+	Region        string
+	ClusterName   string
+	NodePoolName  string
+	NodeClaimName string
+	NodeName      string
+	State         string
+	BlockedBy     []string
+}
+
+type KarpenterVersionInfo struct {
+	Profile     string
+	Region      string
+	ClusterName string
+	// Newest Karpenter API served, empty when Karpenter is not installed
+	APIVersion string
+	// Every Karpenter API served; more than one while upgrading
+	ServedAPIs []string
+	// Resources of the API in use, e.g. provisioners/machines/awsnodetemplates
+	Resources string
+	// Image tag of the Karpenter controller deployment
+	ControllerVersion string
+}
+
+type KarpenterPodPlacementInfo struct {
 	Profile      string
 	Region       string
 	ClusterName  string
 	NodePoolName string
-	CurrentAMI   string
-	NodeCount    int
+	Weight       int32
+	// Selected, Compatible or Excluded
+	Result  string
+	Reasons []string
 }
 
-type KarpenterDriftInfo struct {
+type KarpenterNodeClaimTimelineEntry struct {
+	Profile       string
+	Region        string
+	ClusterName   string
+	NodeClaimName string
+	Time          time.Time
+	// Condition, Event or Lifecycle
+	Source string
+	// Condition type, event reason or lifecycle step (Created, Deleting)
+	Name    string
+	Status  string
+	Message string
+	// Time elapsed since the NodeClaim was created and since the previous entry
+	SinceCreated  time.Duration
+	SincePrevious time.Duration
+}
+
+type KarpenterLaunchStats struct {
 	Profile      string
 	Region       string
 	ClusterName  string
-	ResourceType string
-	Name         string
-	NodeName     string
 	NodePoolName string
-	DriftedSince time.Time
-	Reason       string
+	InstanceType string
+	// NodeClaims that became Ready, the only ones the percentiles cover
+	Count int
+	// NodeClaims still waiting to become Ready
+	Pending int
+	P50     time.Duration
+	P95     time.Duration
+	Max     time.Duration
 }
@@ -85,6 +85,39 @@ type ResourceResult struct {
 	Status      string
 }
 
+// ResourceAggregation counts resources per key and per cluster
+type ResourceAggregation struct {
+	Clusters []string
+	Rows     []AggregationRow
+	Totals   map[string]int
+	Total    int
+}
+
+// AggregationRow holds the counts of a single key
+type AggregationRow struct {
+	Key    string
+	Counts map[string]int
+	Total  int
+}
+
+// ResourceEvent is a single change streamed while watching a resource
+type ResourceEvent struct {
+	Type   string
+	Result ResourceResult
+}
+
+// ResourceDiffResult compares the copy of a resource in one cluster with the
+// copy in the baseline cluster
+type ResourceDiffResult struct {
+	Profile     string
+	Region      string
+	ClusterName string
+	Status      string
+	Added       int
+	Removed     int
+	Error       string
+}
+
 type AWSProfile struct {
 	Name           string
 	DefaultRegion  string
@@ -100,6 +133,7 @@ type AMIInfo struct {
 	Name            string
 	Architecture    string
 	State           string
+	CreationDate    string
 	DeprecationTime string
 }
 
@@ -156,16 +190,18 @@ type ResourceQuotaInfo struct {
 }
 
 type EventInfo struct {
-	Profile     string
-	Region      string
-	ClusterName string
-	Namespace   string
-	LastSeen    time.Time
-	Type        string
-	Reason      string
-	Object      string
-	Message     string
-	Count       int32
+	Profile      string
+	Region       string
+	ClusterName  string
+	Namespace    string
+	FirstSeen    time.Time
+	LastSeen     time.Time
+	Type         string
+	Reason       string
+	InvolvedKind string
+	Object       string
+	Message      string
+	Count        int32
 }
 
 type IRSAInfo struct {
@@ -0,0 +1,168 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// serverManagedAnnotations are annotations set by the API server or by
+// controllers that differ between clusters even for identical manifests
+var serverManagedAnnotations = []string{
+	"kubectl.kubernetes.io/last-applied-configuration",
+	"deployment.kubernetes.io/revision",
+}
+
+// Normalize returns a copy of obj without the fields the API server manages
+// (managedFields, resourceVersion, uid, ...). The status is only kept when
+// includeStatus is set.
+func Normalize(obj *unstructured.Unstructured, includeStatus bool) *unstructured.Unstructured {
+	normalized := obj.DeepCopy()
+
+	for _, field := range []string{"managedFields", "resourceVersion", "uid", "creationTimestamp", "generation", "selfLink"} {
+		unstructured.RemoveNestedField(normalized.Object, "metadata", field)
+	}
+
+	annotations := normalized.GetAnnotations()
+	for _, annotation := range serverManagedAnnotations {
+		delete(annotations, annotation)
+	}
+	if len(annotations) == 0 {
+		unstructured.RemoveNestedField(normalized.Object, "metadata", "annotations")
+	} else {
+		normalized.SetAnnotations(annotations)
+	}
+
+	if !includeStatus {
+		unstructured.RemoveNestedField(normalized.Object, "status")
+	}
+
+	return normalized
+}
+
+// ToYAML renders obj as YAML with sorted keys so that the output of two
+// clusters can be compared line by line
+func ToYAML(obj *unstructured.Unstructured) (string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(obj.Object); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Unified returns a unified diff between from and to. It is empty when both
+// are identical.
+func Unified(fromName, toName, from, to string) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(from),
+		B:        difflib.SplitLines(to),
+		FromFile: fromName,
+		ToFile:   toName,
+		Context:  3,
+	})
+}
+
+// ChangedLines returns the number of lines removed from and added to from to
+// get to
+func ChangedLines(from, to string) (removed int, added int) {
+	matcher := difflib.NewMatcher(splitLines(from), splitLines(to))
+	for _, op := range matcher.GetOpCodes() {
+		switch op.Tag {
+		case 'r':
+			removed += op.I2 - op.I1
+			added += op.J2 - op.J1
+		case 'd':
+			removed += op.I2 - op.I1
+		case 'i':
+			added += op.J2 - op.J1
+		}
+	}
+	return removed, added
+}
+
+// SideBySide renders from and to in two columns of the given width. Changed
+// lines are marked with "|", removed lines with "<" and added lines with ">".
+// Widths below 1 are treated as 1.
+func SideBySide(fromName, toName, from, to string, width int) string {
+	width = max(width, 1)
+	a := splitLines(from)
+	b := splitLines(to)
+
+	var sb strings.Builder
+	writeRow := func(left, marker, right string) {
+		fmt.Fprintf(&sb, "%-*s %s %s\n", width, truncate(left, width), marker, truncate(right, width))
+	}
+
+	writeRow(fromName, " ", toName)
+	writeRow(strings.Repeat("-", width), " ", strings.Repeat("-", width))
+
+	matcher := difflib.NewMatcher(a, b)
+	for _, op := range matcher.GetOpCodes() {
+		switch op.Tag {
+		case 'e':
+			for i := 0; i < op.I2-op.I1; i++ {
+				writeRow(a[op.I1+i], " ", b[op.J1+i])
+			}
+		case 'r':
+			rows := max(op.I2-op.I1, op.J2-op.J1)
+			for i := 0; i < rows; i++ {
+				left, right := "", ""
+				marker := "|"
+				if op.I1+i < op.I2 {
+					left = a[op.I1+i]
+				} else {
+					marker = ">"
+				}
+				if op.J1+i < op.J2 {
+					right = b[op.J1+i]
+				} else {
+					marker = "<"
+				}
+				writeRow(left, marker, right)
+			}
+		case 'd':
+			for i := op.I1; i < op.I2; i++ {
+				writeRow(a[i], "<", "")
+			}
+		case 'i':
+			for j := op.J1; j < op.J2; j++ {
+				writeRow("", ">", b[j])
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, "\n")
+}
+
+// truncate shortens s to width characters, counted in runes so multi-byte
+// characters are never split
+func truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width <= 3 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-3]) + "..."
+}
@@ -0,0 +1,116 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newDeployment(replicas int64, image string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":              "checkout",
+			"namespace":         "shop",
+			"uid":               "1234",
+			"resourceVersion":   "42",
+			"generation":        int64(3),
+			"creationTimestamp": "2024-01-01T00:00:00Z",
+			"managedFields":     []interface{}{map[string]interface{}{"manager": "kubectl"}},
+			"annotations": map[string]interface{}{
+				"deployment.kubernetes.io/revision": "7",
+			},
+			"labels": map[string]interface{}{"app": "checkout"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app", "image": image},
+					},
+				},
+			},
+		},
+		"status": map[string]interface{}{"readyReplicas": replicas},
+	}}
+}
+
+func TestNormalize(t *testing.T) {
+	obj := newDeployment(3, "checkout:1.0")
+
+	normalized := Normalize(obj, false)
+
+	metadata := normalized.Object["metadata"].(map[string]interface{})
+	for _, field := range []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields", "annotations"} {
+		assert.NotContains(t, metadata, field)
+	}
+	assert.Equal(t, "checkout", metadata["name"])
+	assert.Contains(t, metadata, "labels")
+	assert.NotContains(t, normalized.Object, "status")
+
+	assert.Contains(t, obj.Object["metadata"], "uid", "the original object is left untouched")
+
+	withStatus := Normalize(obj, true)
+	assert.Contains(t, withStatus.Object, "status")
+}
+
+func TestNormalize_KeepsUserAnnotations(t *testing.T) {
+	obj := newDeployment(3, "checkout:1.0")
+	obj.SetAnnotations(map[string]string{
+		"deployment.kubernetes.io/revision": "7",
+		"team":                              "payments",
+	})
+
+	normalized := Normalize(obj, false)
+
+	assert.Equal(t, map[string]string{"team": "payments"}, normalized.GetAnnotations())
+}
+
+func TestChangedLinesAndUnified(t *testing.T) {
+	a, err := ToYAML(Normalize(newDeployment(3, "checkout:1.0"), false))
+	require.NoError(t, err)
+	b, err := ToYAML(Normalize(newDeployment(3, "checkout:1.0"), false))
+	require.NoError(t, err)
+
+	removed, added := ChangedLines(a, b)
+	assert.Zero(t, removed)
+	assert.Zero(t, added)
+
+	c, err := ToYAML(Normalize(newDeployment(5, "checkout:1.1"), false))
+	require.NoError(t, err)
+
+	removed, added = ChangedLines(a, c)
+	assert.Equal(t, 2, removed)
+	assert.Equal(t, 2, added)
+
+	unified, err := Unified("a", "c", a, c)
+	require.NoError(t, err)
+	assert.Contains(t, unified, "--- a")
+	assert.Contains(t, unified, "+++ c")
+	assert.Contains(t, unified, "-  replicas: 3")
+	assert.Contains(t, unified, "+  replicas: 5")
+}
+
+func TestSideBySide(t *testing.T) {
+	out := SideBySide("a", "b", "same\nold\ngone\n", "same\nnew\n", 10)
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+
+	require.Len(t, lines, 5)
+	assert.Equal(t, "a            b", lines[0])
+	assert.Equal(t, "same         same", lines[2])
+	assert.Equal(t, "old        | new", lines[3])
+	assert.Equal(t, "gone       < ", lines[4])
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", truncate("short", 10))
+	assert.Equal(t, "a very...", truncate("a very long line", 9))
+	assert.Equal(t, "café...", truncate("café con leche", 7), "multi-byte characters are not split")
+	assert.Equal(t, "", truncate("short", 0))
+	assert.Equal(t, "", truncate("short", -5))
+}
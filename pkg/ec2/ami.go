@@ -7,6 +7,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/jordiprats/kubectl-eks/pkg/data"
 )
 
@@ -50,6 +51,7 @@ func GetAMIInfo(profile, region, ami string) (*data.AMIInfo, error) {
 		Name:         aws.ToString(result.Images[0].Name),
 		Architecture: string(result.Images[0].Architecture),
 		State:        string(result.Images[0].State),
+		CreationDate: aws.ToString(result.Images[0].CreationDate),
 	}
 
 	if result.Images[0].DeprecationTime != nil {
@@ -58,3 +60,50 @@ func GetAMIInfo(profile, region, ami string) (*data.AMIInfo, error) {
 
 	return &info, nil
 }
+
+// DescribeAMIs describes all the given AMIs with a single DescribeImages
+// call. AMIs that no longer exist are left out of the result instead of
+// failing the whole request.
+func DescribeAMIs(profile, region string, amis []string) (map[string]data.AMIInfo, error) {
+	result := make(map[string]data.AMIInfo)
+	if len(amis) == 0 {
+		return result, nil
+	}
+
+	ctx := context.Background()
+
+	cfg, err := config.LoadDefaultConfig(ctx,
+		config.WithSharedConfigProfile(profile),
+		config.WithRegion(region),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	svc := ec2.NewFromConfig(cfg)
+
+	// Filtering by image-id does not fail on deregistered AMIs like ImageIds does
+	output, err := svc.DescribeImages(ctx, &ec2.DescribeImagesInput{
+		Filters: []types.Filter{
+			{Name: aws.String("image-id"), Values: amis},
+		},
+		IncludeDeprecated: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe AMIs for profile %s in region %s: %w", profile, region, err)
+	}
+
+	for _, image := range output.Images {
+		info := data.AMIInfo{
+			ID:              aws.ToString(image.ImageId),
+			Name:            aws.ToString(image.Name),
+			Architecture:    string(image.Architecture),
+			State:           string(image.State),
+			CreationDate:    aws.ToString(image.CreationDate),
+			DeprecationTime: aws.ToString(image.DeprecationTime),
+		}
+		result[info.ID] = info
+	}
+
+	return result, nil
+}
@@ -0,0 +1,251 @@
+package eks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/jordiprats/kubectl-eks/pkg/awsutil"
+)
+
+// amiReleaseTrees maps the nodegroup AMI types to the SSM public parameter
+// tree holding their releases, relative to the path of the Kubernetes version
+var amiReleaseTrees = map[string]string{
+	"AL2_x86_64":                 "amazon-linux-2",
+	"AL2_x86_64_GPU":             "amazon-linux-2-gpu",
+	"AL2_ARM_64":                 "amazon-linux-2-arm64",
+	"AL2023_x86_64_STANDARD":     "amazon-linux-2023/x86_64/standard",
+	"AL2023_ARM_64_STANDARD":     "amazon-linux-2023/arm64/standard",
+	"AL2023_x86_64_NVIDIA":       "amazon-linux-2023/x86_64/nvidia",
+	"AL2023_ARM_64_NVIDIA":       "amazon-linux-2023/arm64/nvidia",
+	"AL2023_x86_64_NEURON":       "amazon-linux-2023/x86_64/neuron",
+	"BOTTLEROCKET_x86_64":        "x86_64",
+	"BOTTLEROCKET_ARM_64":        "arm64",
+	"BOTTLEROCKET_x86_64_NVIDIA": "x86_64",
+	"BOTTLEROCKET_ARM_64_NVIDIA": "arm64",
+}
+
+// AMIReleaseResolver looks up the EKS optimized AMI releases AWS publishes
+// as SSM public parameters
+type AMIReleaseResolver struct {
+	api awsutil.SSMAPI
+	// Releases already looked up, by parameter tree
+	latest   map[string]string
+	releases map[string][]string
+}
+
+// NewAMIReleaseResolver creates a resolver reading the parameters of the
+// given region
+func NewAMIReleaseResolver(profile, region string) (*AMIReleaseResolver, error) {
+	cfg, err := awsutil.LoadConfig(profile, region)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return NewAMIReleaseResolverWithAPI(ssm.NewFromConfig(cfg)), nil
+}
+
+// NewAMIReleaseResolverWithAPI creates a resolver with a custom API implementation (for testing)
+func NewAMIReleaseResolverWithAPI(api awsutil.SSMAPI) *AMIReleaseResolver {
+	return &AMIReleaseResolver{
+		api:      api,
+		latest:   make(map[string]string),
+		releases: make(map[string][]string),
+	}
+}
+
+// amiReleasePaths returns the parameter tree with every release of an AMI
+// type for a Kubernetes version, and the parameter naming the recommended
+// one. AMI types without EKS optimized releases, such as CUSTOM, return
+// false.
+func amiReleasePaths(amiType, version string) (string, string, bool) {
+	tree, ok := amiReleaseTrees[amiType]
+	if !ok || version == "" {
+		return "", "", false
+	}
+
+	if strings.HasPrefix(amiType, "BOTTLEROCKET_") {
+		variant := "aws-k8s-" + version
+		if strings.HasSuffix(amiType, "_NVIDIA") {
+			variant += "-nvidia"
+		}
+		base := fmt.Sprintf("/aws/service/bottlerocket/%s/%s", variant, tree)
+		return base, base + "/latest/image_version", true
+	}
+
+	base := fmt.Sprintf("/aws/service/eks/optimized-ami/%s/%s", version, tree)
+	return base, base + "/recommended/release_version", true
+}
+
+// Releases returns the recommended release of an AMI type for a Kubernetes
+// version and every release published for it, oldest first
+func (r *AMIReleaseResolver) Releases(amiType, version string) (string, []string, error) {
+	base, recommended, ok := amiReleasePaths(amiType, version)
+	if !ok {
+		return "", nil, fmt.Errorf("no EKS optimized AMI releases for AMI type %s", amiType)
+	}
+	if latest, ok := r.latest[base]; ok {
+		return latest, r.releases[base], nil
+	}
+
+	ctx := context.TODO()
+
+	output, err := r.api.GetParameter(ctx, &ssm.GetParameterInput{Name: aws.String(recommended)})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get parameter %s: %w", recommended, err)
+	}
+	latest := aws.ToString(output.Parameter.Value)
+
+	seen := map[string]bool{latest: true}
+	releases := []string{latest}
+	paginator := ssm.NewGetParametersByPathPaginator(r.api, &ssm.GetParametersByPathInput{
+		Path:      aws.String(base),
+		Recursive: aws.Bool(true),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to list parameters under %s: %w", base, err)
+		}
+		for _, parameter := range page.Parameters {
+			name := aws.ToString(parameter.Name)
+			if !strings.HasSuffix(name, "/release_version") && !strings.HasSuffix(name, "/image_version") {
+				continue
+			}
+			if value := aws.ToString(parameter.Value); value != "" && !seen[value] {
+				seen[value] = true
+				releases = append(releases, value)
+			}
+		}
+	}
+
+	sort.Slice(releases, func(i, j int) bool {
+		return compareReleases(releases[i], releases[j]) < 0
+	})
+
+	r.latest[base] = latest
+	r.releases[base] = releases
+
+	return latest, releases, nil
+}
+
+// CheckNodeGroup fills in the latest release for the AMI type and version of
+// a nodegroup, how many releases behind it is and how many minor versions
+// it lags the control plane. Nodegroups with custom AMIs are left without a
+// latest release.
+func (r *AMIReleaseResolver) CheckNodeGroup(ng *EKSNodeGroupInfo, clusterVersion string) error {
+	ng.ControlPlaneVersion = clusterVersion
+	ng.MinorVersionsBehind = -1
+	if behind, ok := minorVersionsBehind(ng.Version, clusterVersion); ok {
+		ng.MinorVersionsBehind = max(behind, 0)
+		ng.AheadOfControlPlane = behind < 0
+	}
+	ng.ReleasesBehind = -1
+
+	if _, _, ok := amiReleasePaths(ng.AMIType, ng.Version); !ok {
+		return nil
+	}
+
+	latest, releases, err := r.Releases(ng.AMIType, ng.Version)
+	if err != nil {
+		return err
+	}
+	ng.LatestReleaseVersion = latest
+	if ng.ReleaseVersion != "" {
+		ng.ReleasesBehind = releasesBehind(ng.ReleaseVersion, latest, releases)
+	}
+
+	return nil
+}
+
+// releasesBehind counts the releases newer than current, up to the latest
+func releasesBehind(current, latest string, releases []string) int {
+	behind := 0
+	for _, release := range releases {
+		if compareReleases(release, current) > 0 && compareReleases(release, latest) <= 0 {
+			behind++
+		}
+	}
+	return behind
+}
+
+// compareReleases orders release versions. Amazon Linux releases, e.g.
+// 1.29.3-20240531, are ordered by build date and Bottlerocket ones, e.g.
+// 1.20.1-7c3e9198, by version.
+func compareReleases(a, b string) int {
+	aVersion, aBuild, _ := strings.Cut(a, "-")
+	bVersion, bBuild, _ := strings.Cut(b, "-")
+
+	if isBuildDate(aBuild) && isBuildDate(bBuild) && aBuild != bBuild {
+		return strings.Compare(aBuild, bBuild)
+	}
+	if c := compareDotted(aVersion, bVersion); c != 0 {
+		return c
+	}
+	return strings.Compare(aBuild, bBuild)
+}
+
+func isBuildDate(build string) bool {
+	if len(build) != 8 {
+		return false
+	}
+	_, err := strconv.Atoi(build)
+	return err == nil
+}
+
+// compareDotted compares dotted version numbers numerically
+func compareDotted(a, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var aNum, bNum int
+		if i < len(aParts) {
+			aNum, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			bNum, _ = strconv.Atoi(bParts[i])
+		}
+		if aNum != bNum {
+			if aNum < bNum {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// minorVersionsBehind returns how many minor versions a nodegroup lags the
+// control plane, negative when it is ahead. It returns false when either
+// version can't be parsed or the major versions differ.
+func minorVersionsBehind(nodeVersion, clusterVersion string) (int, bool) {
+	nodeMajor, nodeMinor, ok := parseMinorVersion(nodeVersion)
+	if !ok {
+		return 0, false
+	}
+	clusterMajor, clusterMinor, ok := parseMinorVersion(clusterVersion)
+	if !ok || nodeMajor != clusterMajor {
+		return 0, false
+	}
+	return clusterMinor - nodeMinor, true
+}
+
+func parseMinorVersion(version string) (int, int, bool) {
+	majorPart, rest, ok := strings.Cut(strings.TrimPrefix(version, "v"), ".")
+	if !ok {
+		return 0, 0, false
+	}
+	minorPart, _, _ := strings.Cut(rest, ".")
+	major, err := strconv.Atoi(majorPart)
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err := strconv.Atoi(minorPart)
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
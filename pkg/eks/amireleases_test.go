@@ -0,0 +1,158 @@
+package eks
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSSM serves parameters from a map, a few per page as Parameter Store does
+type fakeSSM struct {
+	parameters map[string]string
+	pathCalls  int
+}
+
+func (f *fakeSSM) GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+	value, ok := f.parameters[aws.ToString(params.Name)]
+	if !ok {
+		return nil, &ssmtypes.ParameterNotFound{}
+	}
+	return &ssm.GetParameterOutput{Parameter: &ssmtypes.Parameter{Name: params.Name, Value: aws.String(value)}}, nil
+}
+
+func (f *fakeSSM) GetParametersByPath(ctx context.Context, params *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error) {
+	f.pathCalls++
+
+	names := []string{}
+	for name := range f.parameters {
+		if strings.HasPrefix(name, aws.ToString(params.Path)+"/") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	start := 0
+	if params.NextToken != nil {
+		start, _ = strconv.Atoi(*params.NextToken)
+	}
+	end := min(start+3, len(names))
+
+	output := &ssm.GetParametersByPathOutput{}
+	for _, name := range names[start:end] {
+		output.Parameters = append(output.Parameters, ssmtypes.Parameter{Name: aws.String(name), Value: aws.String(f.parameters[name])})
+	}
+	if end < len(names) {
+		output.NextToken = aws.String(strconv.Itoa(end))
+	}
+	return output, nil
+}
+
+func newFakeSSM() *fakeSSM {
+	al2023 := "/aws/service/eks/optimized-ami/1.29/amazon-linux-2023/x86_64/standard"
+	bottlerocket := "/aws/service/bottlerocket/aws-k8s-1.29/arm64"
+
+	return &fakeSSM{parameters: map[string]string{
+		al2023 + "/recommended/release_version":                                           "1.29.3-20240531",
+		al2023 + "/recommended/image_id":                                                  "ami-0531",
+		al2023 + "/amazon-eks-node-al2023-x86_64-standard-1.29-v20240415/release_version": "1.29.0-20240415",
+		al2023 + "/amazon-eks-node-al2023-x86_64-standard-1.29-v20240503/release_version": "1.29.3-20240503",
+		al2023 + "/amazon-eks-node-al2023-x86_64-standard-1.29-v20240522/release_version": "1.29.3-20240522",
+		al2023 + "/amazon-eks-node-al2023-x86_64-standard-1.29-v20240531/release_version": "1.29.3-20240531",
+		al2023 + "/amazon-eks-node-al2023-x86_64-standard-1.29-v20240531/image_id":        "ami-0531",
+
+		bottlerocket + "/latest/image_version": "1.20.2-536d69d0",
+		bottlerocket + "/1.19.5/image_version": "1.19.5-64049ba8",
+		bottlerocket + "/1.20.1/image_version": "1.20.1-7c3e9198",
+		bottlerocket + "/1.20.2/image_version": "1.20.2-536d69d0",
+		// The NVIDIA variant is a tree of its own
+		"/aws/service/bottlerocket/aws-k8s-1.29-nvidia/arm64/latest/image_version": "1.21.0-aaaaaaaa",
+	}}
+}
+
+func TestAMIReleaseResolver_Releases(t *testing.T) {
+	api := newFakeSSM()
+	resolver := NewAMIReleaseResolverWithAPI(api)
+
+	latest, releases, err := resolver.Releases("AL2023_x86_64_STANDARD", "1.29")
+	require.NoError(t, err)
+	assert.Equal(t, "1.29.3-20240531", latest)
+	assert.Equal(t, []string{"1.29.0-20240415", "1.29.3-20240503", "1.29.3-20240522", "1.29.3-20240531"}, releases)
+
+	calls := api.pathCalls
+	_, _, err = resolver.Releases("AL2023_x86_64_STANDARD", "1.29")
+	require.NoError(t, err)
+	assert.Equal(t, calls, api.pathCalls, "releases are looked up once per tree")
+
+	latest, releases, err = resolver.Releases("BOTTLEROCKET_ARM_64", "1.29")
+	require.NoError(t, err)
+	assert.Equal(t, "1.20.2-536d69d0", latest)
+	assert.Equal(t, []string{"1.19.5-64049ba8", "1.20.1-7c3e9198", "1.20.2-536d69d0"}, releases)
+
+	_, _, err = resolver.Releases("CUSTOM", "1.29")
+	assert.ErrorContains(t, err, "no EKS optimized AMI releases for AMI type CUSTOM")
+
+	_, _, err = resolver.Releases("AL2_x86_64", "1.29")
+	assert.ErrorContains(t, err, "failed to get parameter /aws/service/eks/optimized-ami/1.29/amazon-linux-2/recommended/release_version")
+}
+
+func TestAMIReleaseResolver_CheckNodeGroup(t *testing.T) {
+	resolver := NewAMIReleaseResolverWithAPI(newFakeSSM())
+
+	ng := EKSNodeGroupInfo{Name: "workers", AMIType: "AL2023_x86_64_STANDARD", Version: "1.29", ReleaseVersion: "1.29.3-20240503"}
+	require.NoError(t, resolver.CheckNodeGroup(&ng, "1.31"))
+	assert.Equal(t, "1.29.3-20240531", ng.LatestReleaseVersion)
+	assert.Equal(t, 2, ng.ReleasesBehind)
+	assert.Equal(t, "1.31", ng.ControlPlaneVersion)
+	assert.Equal(t, 2, ng.MinorVersionsBehind)
+
+	current := EKSNodeGroupInfo{Name: "gpu", AMIType: "BOTTLEROCKET_ARM_64", Version: "1.29", ReleaseVersion: "1.20.2-536d69d0"}
+	require.NoError(t, resolver.CheckNodeGroup(&current, "1.29"))
+	assert.Equal(t, 0, current.ReleasesBehind)
+	assert.Equal(t, 0, current.MinorVersionsBehind)
+
+	custom := EKSNodeGroupInfo{Name: "custom", AMIType: "CUSTOM", Version: "1.28"}
+	require.NoError(t, resolver.CheckNodeGroup(&custom, "1.29"))
+	assert.Empty(t, custom.LatestReleaseVersion)
+	assert.Equal(t, -1, custom.ReleasesBehind)
+	assert.Equal(t, 1, custom.MinorVersionsBehind, "the version skew is known without a release")
+
+	ahead := EKSNodeGroupInfo{Name: "ahead", AMIType: "CUSTOM", Version: "1.30"}
+	require.NoError(t, resolver.CheckNodeGroup(&ahead, "1.29"))
+	assert.Equal(t, 0, ahead.MinorVersionsBehind)
+	assert.True(t, ahead.AheadOfControlPlane)
+
+	unknown := EKSNodeGroupInfo{Name: "unknown", AMIType: "CUSTOM"}
+	require.NoError(t, resolver.CheckNodeGroup(&unknown, "1.29"))
+	assert.Equal(t, -1, unknown.MinorVersionsBehind)
+	assert.False(t, unknown.AheadOfControlPlane)
+}
+
+func TestMinorVersionsBehind(t *testing.T) {
+	behind, ok := minorVersionsBehind("1.29", "v1.31.2")
+	assert.True(t, ok)
+	assert.Equal(t, 2, behind)
+
+	behind, ok = minorVersionsBehind("1.30", "1.29")
+	assert.True(t, ok)
+	assert.Equal(t, -1, behind, "nodegroups ahead of the control plane are negative")
+
+	_, ok = minorVersionsBehind("", "1.29")
+	assert.False(t, ok)
+
+	_, ok = minorVersionsBehind("2.0", "1.29")
+	assert.False(t, ok, "different major versions can't be compared")
+}
+
+func TestCompareReleases(t *testing.T) {
+	assert.Equal(t, -1, compareReleases("1.29.3-20240503", "1.29.0-20240531"), "Amazon Linux releases are ordered by build date")
+	assert.Equal(t, 1, compareReleases("1.20.10-7c3e9198", "1.20.9-536d69d0"))
+	assert.Equal(t, 0, compareReleases("1.29.3-20240531", "1.29.3-20240531"))
+}
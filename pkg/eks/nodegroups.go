@@ -3,11 +3,14 @@ package eks
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/jordiprats/kubectl-eks/pkg/awsutil"
 )
 
 type EKSNodeGroupInfo struct {
@@ -21,80 +24,236 @@ type EKSNodeGroupInfo struct {
 	MinCapacity     int64
 	Version         string
 	Status          string
+	AMIType         string
+	// Filled in by AMIReleaseResolver.CheckNodeGroup
+	LatestReleaseVersion string
+	// -1 when unknown
+	ReleasesBehind      int
+	ControlPlaneVersion string
+	// -1 when unknown, 0 when ahead of the control plane
+	MinorVersionsBehind int
+	AheadOfControlPlane bool
+
+	Arn                   string
+	CreatedAt             time.Time
+	HealthIssues          []NodeGroupHealthIssue
+	InstanceTypes         []string
+	DiskSize              int64
+	LaunchTemplateVersion string
+	Subnets               []string
+	AutoScalingGroups     []string
+	NodeRole              string
+	Labels                map[string]string
+	Taints                []NodeGroupTaint
+	// Nodes that can be unavailable during an update, e.g. 1 or 33%
+	MaxUnavailable     string
+	RemoteAccessKey    string
+	RemoteAccessGroups []string
+	// Live node counts from the Kubernetes API, -1 when unknown
+	Nodes      int
+	ReadyNodes int
 }
 
-func GetEKSNodeGroups(profile, region, clusterName string) ([]EKSNodeGroupInfo, error) {
-	ctx := context.Background()
+type NodeGroupHealthIssue struct {
+	Code        string
+	Message     string
+	ResourceIDs []string
+}
+
+type NodeGroupTaint struct {
+	Key    string
+	Value  string
+	Effect string
+}
+
+// rootDeviceNames are the root device names of the EKS optimized AMIs
+var rootDeviceNames = map[string]bool{
+	"/dev/xvda": true,
+	"/dev/sda1": true,
+}
 
-	// Load the AWS configuration using the profile and region
-	cfg, err := config.LoadDefaultConfig(ctx,
-		config.WithSharedConfigProfile(profile),
-		config.WithRegion(region),
-	)
+// NodeGroupClient wraps the EKS and EC2 APIs used to describe node groups
+type NodeGroupClient struct {
+	eks awsutil.EKSAPI
+	ec2 awsutil.EC2API
+}
+
+// NewNodeGroupClient creates a new node group client
+func NewNodeGroupClient(profile, region string) (*NodeGroupClient, error) {
+	cfg, err := awsutil.LoadConfig(profile, region)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
+	return &NodeGroupClient{eks: eks.NewFromConfig(cfg), ec2: ec2.NewFromConfig(cfg)}, nil
+}
 
-	// Create clients
-	eksSvc := eks.NewFromConfig(cfg)
-	ec2Svc := ec2.NewFromConfig(cfg)
+// NewNodeGroupClientWithAPI creates a client with custom API implementations (for testing)
+func NewNodeGroupClientWithAPI(eksAPI awsutil.EKSAPI, ec2API awsutil.EC2API) *NodeGroupClient {
+	return &NodeGroupClient{eks: eksAPI, ec2: ec2API}
+}
 
-	// Get NG names
-	result, err := eksSvc.ListNodegroups(ctx, &eks.ListNodegroupsInput{
+func GetEKSNodeGroups(profile, region, clusterName string) ([]EKSNodeGroupInfo, error) {
+	client, err := NewNodeGroupClient(profile, region)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetNodeGroups(clusterName)
+}
+
+// GetEKSNodeGroup describes a single node group
+func GetEKSNodeGroup(profile, region, clusterName, name string) (*EKSNodeGroupInfo, error) {
+	client, err := NewNodeGroupClient(profile, region)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetNodeGroup(clusterName, name)
+}
+
+// GetNodeGroups describes every node group of a cluster. Node groups that
+// can't be described are left out.
+func (c *NodeGroupClient) GetNodeGroups(clusterName string) ([]EKSNodeGroupInfo, error) {
+	result, err := c.eks.ListNodegroups(context.TODO(), &eks.ListNodegroupsInput{
 		ClusterName: aws.String(clusterName),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to list node groups for cluster %s in region %s: %w", clusterName, region, err)
+		return nil, fmt.Errorf("failed to list node groups for cluster %s: %w", clusterName, err)
 	}
 
-	ngList := make([]EKSNodeGroupInfo, len(result.Nodegroups))
-
-	for i, ng := range result.Nodegroups {
-		// describe NodeGroup
-		ngDesc, err := eksSvc.DescribeNodegroup(ctx, &eks.DescribeNodegroupInput{
-			ClusterName:   aws.String(clusterName),
-			NodegroupName: aws.String(ng),
-		})
+	ngList := make([]EKSNodeGroupInfo, 0, len(result.Nodegroups))
 
+	for _, ng := range result.Nodegroups {
+		info, err := c.GetNodeGroup(clusterName, ng)
 		if err != nil {
 			continue
 		}
+		ngList = append(ngList, *info)
+	}
 
-		if ngDesc.Nodegroup == nil {
-			continue
-		}
+	return ngList, nil
+}
 
-		instanceType := ""
+// GetNodeGroup describes a single node group, adding the instance type and
+// root volume size of its launch template
+func (c *NodeGroupClient) GetNodeGroup(clusterName, name string) (*EKSNodeGroupInfo, error) {
+	ctx := context.TODO()
 
-		if ngDesc.Nodegroup.LaunchTemplate != nil {
-			ltDesc, err := ec2Svc.DescribeLaunchTemplateVersions(ctx, &ec2.DescribeLaunchTemplateVersionsInput{
-				LaunchTemplateId: ngDesc.Nodegroup.LaunchTemplate.Id,
-				Versions:         []string{aws.ToString(ngDesc.Nodegroup.LaunchTemplate.Version)},
-			})
+	ngDesc, err := c.eks.DescribeNodegroup(ctx, &eks.DescribeNodegroupInput{
+		ClusterName:   aws.String(clusterName),
+		NodegroupName: aws.String(name),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe node group %s: %w", name, err)
+	}
+	if ngDesc.Nodegroup == nil {
+		return nil, fmt.Errorf("node group %s not found", name)
+	}
+
+	info := nodeGroupInfo(ngDesc.Nodegroup)
 
-			if err == nil && len(ltDesc.LaunchTemplateVersions) > 0 && ltDesc.LaunchTemplateVersions[0].LaunchTemplateData != nil {
-				instanceType = string(ltDesc.LaunchTemplateVersions[0].LaunchTemplateData.InstanceType)
+	if ngDesc.Nodegroup.LaunchTemplate != nil {
+		ltDesc, err := c.ec2.DescribeLaunchTemplateVersions(ctx, &ec2.DescribeLaunchTemplateVersionsInput{
+			LaunchTemplateId: ngDesc.Nodegroup.LaunchTemplate.Id,
+			Versions:         []string{aws.ToString(ngDesc.Nodegroup.LaunchTemplate.Version)},
+		})
+
+		if err == nil && len(ltDesc.LaunchTemplateVersions) > 0 && ltDesc.LaunchTemplateVersions[0].LaunchTemplateData != nil {
+			ltData := ltDesc.LaunchTemplateVersions[0].LaunchTemplateData
+			if ltData.InstanceType != "" {
+				info.InstanceType = string(ltData.InstanceType)
+				if len(info.InstanceTypes) == 0 {
+					info.InstanceTypes = []string{info.InstanceType}
+				}
+			}
+			// With a launch template the root volume size comes from it.
+			// Other mappings are data volumes.
+			if info.DiskSize == 0 {
+				for _, mapping := range ltData.BlockDeviceMappings {
+					if rootDeviceNames[aws.ToString(mapping.DeviceName)] && mapping.Ebs != nil {
+						info.DiskSize = int64(aws.ToInt32(mapping.Ebs.VolumeSize))
+						break
+					}
+				}
 			}
 		}
+	}
 
-		launchTemplateID := ""
-		if ngDesc.Nodegroup.LaunchTemplate != nil {
-			launchTemplateID = aws.ToString(ngDesc.Nodegroup.LaunchTemplate.Id)
+	return &info, nil
+}
+
+// nodeGroupInfo converts the DescribeNodegroup output. Instance types set in
+// a launch template are added by GetNodeGroup.
+func nodeGroupInfo(ng *types.Nodegroup) EKSNodeGroupInfo {
+	info := EKSNodeGroupInfo{
+		Name:           aws.ToString(ng.NodegroupName),
+		CapacityType:   string(ng.CapacityType),
+		ReleaseVersion: aws.ToString(ng.ReleaseVersion),
+		Version:        aws.ToString(ng.Version),
+		Status:         string(ng.Status),
+		AMIType:        string(ng.AmiType),
+		ReleasesBehind: -1,
+		Arn:            aws.ToString(ng.NodegroupArn),
+		CreatedAt:      aws.ToTime(ng.CreatedAt),
+		InstanceTypes:  ng.InstanceTypes,
+		DiskSize:       int64(aws.ToInt32(ng.DiskSize)),
+		Subnets:        ng.Subnets,
+		NodeRole:       aws.ToString(ng.NodeRole),
+		Labels:         ng.Labels,
+		Nodes:          -1,
+		ReadyNodes:     -1,
+	}
+
+	// Mixed instance types are only listed in the node group
+	if len(ng.InstanceTypes) > 0 {
+		info.InstanceType = strings.Join(ng.InstanceTypes, ",")
+	}
+
+	if ng.ScalingConfig != nil {
+		info.DesiredCapacity = int64(aws.ToInt32(ng.ScalingConfig.DesiredSize))
+		info.MaxCapacity = int64(aws.ToInt32(ng.ScalingConfig.MaxSize))
+		info.MinCapacity = int64(aws.ToInt32(ng.ScalingConfig.MinSize))
+	}
+
+	if ng.LaunchTemplate != nil {
+		info.LaunchTemplate = aws.ToString(ng.LaunchTemplate.Id)
+		info.LaunchTemplateVersion = aws.ToString(ng.LaunchTemplate.Version)
+	}
+
+	if ng.Health != nil {
+		for _, issue := range ng.Health.Issues {
+			info.HealthIssues = append(info.HealthIssues, NodeGroupHealthIssue{
+				Code:        string(issue.Code),
+				Message:     aws.ToString(issue.Message),
+				ResourceIDs: issue.ResourceIds,
+			})
 		}
+	}
 
-		ngList[i] = EKSNodeGroupInfo{
-			Name:            ng,
-			CapacityType:    string(ngDesc.Nodegroup.CapacityType),
-			ReleaseVersion:  aws.ToString(ngDesc.Nodegroup.ReleaseVersion),
-			InstanceType:    instanceType,
-			LaunchTemplate:  launchTemplateID,
-			DesiredCapacity: int64(aws.ToInt32(ngDesc.Nodegroup.ScalingConfig.DesiredSize)),
-			MaxCapacity:     int64(aws.ToInt32(ngDesc.Nodegroup.ScalingConfig.MaxSize)),
-			MinCapacity:     int64(aws.ToInt32(ngDesc.Nodegroup.ScalingConfig.MinSize)),
-			Version:         aws.ToString(ngDesc.Nodegroup.Version),
-			Status:          string(ngDesc.Nodegroup.Status),
+	if ng.Resources != nil {
+		for _, asg := range ng.Resources.AutoScalingGroups {
+			info.AutoScalingGroups = append(info.AutoScalingGroups, aws.ToString(asg.Name))
 		}
 	}
 
-	return ngList, nil
+	for _, taint := range ng.Taints {
+		info.Taints = append(info.Taints, NodeGroupTaint{
+			Key:    aws.ToString(taint.Key),
+			Value:  aws.ToString(taint.Value),
+			Effect: string(taint.Effect),
+		})
+	}
+
+	if ng.UpdateConfig != nil {
+		if ng.UpdateConfig.MaxUnavailable != nil {
+			info.MaxUnavailable = fmt.Sprintf("%d", aws.ToInt32(ng.UpdateConfig.MaxUnavailable))
+		} else if ng.UpdateConfig.MaxUnavailablePercentage != nil {
+			info.MaxUnavailable = fmt.Sprintf("%d%%", aws.ToInt32(ng.UpdateConfig.MaxUnavailablePercentage))
+		}
+	}
+
+	if ng.RemoteAccess != nil {
+		info.RemoteAccessKey = aws.ToString(ng.RemoteAccess.Ec2SshKey)
+		info.RemoteAccessGroups = ng.RemoteAccess.SourceSecurityGroups
+	}
+
+	return info
 }
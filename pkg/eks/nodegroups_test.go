@@ -0,0 +1,142 @@
+package eks
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEC2 serves launch template versions by template ID
+type fakeEC2 struct {
+	templates map[string]ec2types.ResponseLaunchTemplateData
+}
+
+func (f *fakeEC2) DescribeLaunchTemplateVersions(ctx context.Context, params *ec2.DescribeLaunchTemplateVersionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeLaunchTemplateVersionsOutput, error) {
+	data, ok := f.templates[aws.ToString(params.LaunchTemplateId)]
+	if !ok {
+		return nil, errors.New("launch template not found")
+	}
+	return &ec2.DescribeLaunchTemplateVersionsOutput{
+		LaunchTemplateVersions: []ec2types.LaunchTemplateVersion{{LaunchTemplateData: &data}},
+	}, nil
+}
+
+func TestNodeGroupInfo(t *testing.T) {
+	created := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	ng := &types.Nodegroup{
+		NodegroupName:  aws.String("workers"),
+		NodegroupArn:   aws.String("arn:aws:eks:eu-west-1:123456789012:nodegroup/main/workers/abc"),
+		AmiType:        types.AMITypesAl2023X8664Standard,
+		CapacityType:   types.CapacityTypesSpot,
+		ReleaseVersion: aws.String("1.29.3-20240531"),
+		Version:        aws.String("1.29"),
+		Status:         types.NodegroupStatusDegraded,
+		CreatedAt:      aws.Time(created),
+		InstanceTypes:  []string{"m5.large", "m5a.large"},
+		DiskSize:       aws.Int32(50),
+		Subnets:        []string{"subnet-a", "subnet-b"},
+		NodeRole:       aws.String("arn:aws:iam::123456789012:role/nodes"),
+		Labels:         map[string]string{"team": "web"},
+		Taints:         []types.Taint{{Key: aws.String("dedicated"), Value: aws.String("web"), Effect: types.TaintEffectNoSchedule}},
+		ScalingConfig:  &types.NodegroupScalingConfig{DesiredSize: aws.Int32(3), MinSize: aws.Int32(1), MaxSize: aws.Int32(6)},
+		UpdateConfig:   &types.NodegroupUpdateConfig{MaxUnavailablePercentage: aws.Int32(33)},
+		RemoteAccess:   &types.RemoteAccessConfig{Ec2SshKey: aws.String("ops"), SourceSecurityGroups: []string{"sg-1"}},
+		Resources: &types.NodegroupResources{
+			AutoScalingGroups: []types.AutoScalingGroup{{Name: aws.String("eks-workers-abc")}},
+		},
+		Health: &types.NodegroupHealth{Issues: []types.Issue{{
+			Code:        types.NodegroupIssueCodeAsgInstanceLaunchFailures,
+			Message:     aws.String("Could not launch Spot Instances"),
+			ResourceIds: []string{"eks-workers-abc"},
+		}}},
+	}
+
+	info := nodeGroupInfo(ng)
+
+	assert.Equal(t, "workers", info.Name)
+	assert.Equal(t, "AL2023_x86_64_STANDARD", info.AMIType)
+	assert.Equal(t, "m5.large,m5a.large", info.InstanceType, "mixed instance types are listed")
+	assert.Equal(t, int64(50), info.DiskSize)
+	assert.Equal(t, int64(3), info.DesiredCapacity)
+	assert.Equal(t, int64(6), info.MaxCapacity)
+	assert.Equal(t, "33%", info.MaxUnavailable)
+	assert.Equal(t, "ops", info.RemoteAccessKey)
+	assert.Equal(t, []string{"eks-workers-abc"}, info.AutoScalingGroups)
+	assert.Equal(t, []NodeGroupTaint{{Key: "dedicated", Value: "web", Effect: "NO_SCHEDULE"}}, info.Taints)
+	assert.Equal(t, []NodeGroupHealthIssue{{Code: "AsgInstanceLaunchFailures", Message: "Could not launch Spot Instances", ResourceIDs: []string{"eks-workers-abc"}}}, info.HealthIssues)
+	assert.Equal(t, created, info.CreatedAt)
+	assert.Equal(t, -1, info.Nodes, "node counts come from the Kubernetes API")
+	assert.Equal(t, -1, info.ReleasesBehind)
+}
+
+func TestNodeGroupInfo_LaunchTemplate(t *testing.T) {
+	info := nodeGroupInfo(&types.Nodegroup{
+		NodegroupName:  aws.String("custom"),
+		LaunchTemplate: &types.LaunchTemplateSpecification{Id: aws.String("lt-123"), Version: aws.String("4")},
+		UpdateConfig:   &types.NodegroupUpdateConfig{MaxUnavailable: aws.Int32(2)},
+	})
+
+	assert.Equal(t, "lt-123", info.LaunchTemplate)
+	assert.Equal(t, "4", info.LaunchTemplateVersion)
+	assert.Equal(t, "2", info.MaxUnavailable)
+	assert.Empty(t, info.InstanceType, "set from the launch template data")
+	assert.Empty(t, info.HealthIssues)
+}
+
+func TestNodeGroupClient_GetNodeGroup(t *testing.T) {
+	mockAPI := new(MockEKSAPI)
+	mockAPI.On("DescribeNodegroup", mock.Anything, mock.MatchedBy(func(input *eks.DescribeNodegroupInput) bool {
+		return aws.ToString(input.NodegroupName) == "custom"
+	})).Return(&eks.DescribeNodegroupOutput{Nodegroup: &types.Nodegroup{
+		NodegroupName:  aws.String("custom"),
+		LaunchTemplate: &types.LaunchTemplateSpecification{Id: aws.String("lt-123"), Version: aws.String("4")},
+	}}, nil)
+	mockAPI.On("DescribeNodegroup", mock.Anything, mock.Anything).Return(nil, errors.New("ResourceNotFoundException"))
+
+	client := NewNodeGroupClientWithAPI(mockAPI, &fakeEC2{templates: map[string]ec2types.ResponseLaunchTemplateData{
+		"lt-123": {
+			InstanceType: ec2types.InstanceTypeM5Large,
+			BlockDeviceMappings: []ec2types.LaunchTemplateBlockDeviceMapping{
+				{DeviceName: aws.String("/dev/xvdb"), Ebs: &ec2types.LaunchTemplateEbsBlockDevice{VolumeSize: aws.Int32(500)}},
+				{DeviceName: aws.String("/dev/xvda"), Ebs: &ec2types.LaunchTemplateEbsBlockDevice{VolumeSize: aws.Int32(80)}},
+			},
+		},
+	}})
+
+	info, err := client.GetNodeGroup("main", "custom")
+	require.NoError(t, err)
+	assert.Equal(t, "m5.large", info.InstanceType)
+	assert.Equal(t, []string{"m5.large"}, info.InstanceTypes)
+	assert.Equal(t, int64(80), info.DiskSize, "the size of the root volume, not of the first mapping")
+
+	_, err = client.GetNodeGroup("main", "missing")
+	assert.ErrorContains(t, err, "failed to describe node group missing")
+}
+
+func TestNodeGroupClient_GetNodeGroups(t *testing.T) {
+	mockAPI := new(MockEKSAPI)
+	mockAPI.On("ListNodegroups", mock.Anything, mock.Anything).Return(&eks.ListNodegroupsOutput{Nodegroups: []string{"workers", "broken"}}, nil)
+	mockAPI.On("DescribeNodegroup", mock.Anything, mock.MatchedBy(func(input *eks.DescribeNodegroupInput) bool {
+		return aws.ToString(input.NodegroupName) == "workers"
+	})).Return(&eks.DescribeNodegroupOutput{Nodegroup: &types.Nodegroup{
+		NodegroupName: aws.String("workers"),
+		DiskSize:      aws.Int32(50),
+	}}, nil)
+	mockAPI.On("DescribeNodegroup", mock.Anything, mock.Anything).Return(nil, errors.New("AccessDeniedException"))
+
+	ngList, err := NewNodeGroupClientWithAPI(mockAPI, &fakeEC2{}).GetNodeGroups("main")
+	require.NoError(t, err)
+	require.Len(t, ngList, 1, "node groups that can't be described are left out")
+	assert.Equal(t, "workers", ngList[0].Name)
+	assert.Equal(t, int64(50), ngList[0].DiskSize)
+}
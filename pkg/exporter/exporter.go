@@ -0,0 +1,231 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/jordiprats/kubectl-eks/pkg/k8s"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Snapshot is the result of a collection over the cluster group
+type Snapshot struct {
+	CollectedAt time.Time     `json:"collectedAt"`
+	Duration    time.Duration `json:"duration"`
+	Clusters    []Cluster     `json:"clusters"`
+}
+
+// Cluster holds everything collected for a single cluster
+type Cluster struct {
+	Info data.ClusterInfo `json:"info"`
+	// Health is the mcheck summary of the cluster
+	Health data.ClusterHealthSummary `json:"health"`
+	// Unhealthy are the unhealthy resources found by mcheck
+	Unhealthy []data.HealthCheckResult `json:"unhealthy"`
+	// Stats is nil when they could not be collected
+	Stats *k8s.K8Sstats `json:"stats,omitempty"`
+	// Insights counts the EKS insights by status, nil when not collected
+	Insights map[string]int `json:"insights,omitempty"`
+	// Errors lists what could not be collected
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Reachable reports whether the health checks could reach the cluster
+func (c Cluster) Reachable() bool {
+	return c.Health.OverallStatus != "Error"
+}
+
+// metric is a Prometheus metric family being written
+type metric struct {
+	name    string
+	help    string
+	samples []sample
+}
+
+type sample struct {
+	labels []string // name, value pairs
+	value  float64
+}
+
+func (m *metric) add(value float64, labels ...string) {
+	m.samples = append(m.samples, sample{labels: labels, value: value})
+}
+
+// WriteMetrics writes the snapshot in the Prometheus text exposition format
+func WriteMetrics(w io.Writer, snapshot Snapshot) error {
+	up := &metric{name: "kubectl_eks_cluster_up", help: "Whether the cluster could be reached by the health checks"}
+	info := &metric{name: "kubectl_eks_cluster_info", help: "Cluster metadata, always 1"}
+	collectErrors := &metric{name: "kubectl_eks_cluster_collect_errors", help: "Number of checks or collectors that failed for the cluster"}
+	checked := &metric{name: "kubectl_eks_resources_checked", help: "Resources checked by kind"}
+	unhealthy := &metric{name: "kubectl_eks_resources_unhealthy", help: "Unhealthy resources by kind and namespace"}
+	nodes := &metric{name: "kubectl_eks_nodes", help: "Nodes by state"}
+	cpu := &metric{name: "kubectl_eks_cpu_cores", help: "CPU cores of the nodes by type (capacity, allocatable, used)"}
+	memory := &metric{name: "kubectl_eks_memory_bytes", help: "Memory of the nodes by type (capacity, allocatable, used)"}
+	pods := &metric{name: "kubectl_eks_pods", help: "Pods in the cluster"}
+	podsNotRunning := &metric{name: "kubectl_eks_pods_not_running", help: "Pods not in the Running phase"}
+	podsWithRestarts := &metric{name: "kubectl_eks_pods_with_restarts", help: "Pods with at least one container restart"}
+	namespaces := &metric{name: "kubectl_eks_namespaces", help: "Namespaces in the cluster"}
+	insights := &metric{name: "kubectl_eks_insights", help: "EKS insights by status"}
+
+	for _, c := range snapshot.Clusters {
+		base := []string{"profile", c.Info.AWSProfile, "region", c.Info.Region, "cluster", c.Info.ClusterName}
+		with := func(labels ...string) []string {
+			return append(append([]string{}, base...), labels...)
+		}
+
+		up.add(boolValue(c.Reachable()), base...)
+		info.add(1, with("version", c.Info.Version, "arn", c.Info.Arn)...)
+		collectErrors.add(float64(len(c.Errors)), base...)
+
+		for _, kc := range kindCounts(c.Health) {
+			checked.add(float64(kc.total), with("kind", kc.kind)...)
+		}
+		for _, nc := range unhealthyByNamespace(c.Unhealthy) {
+			unhealthy.add(float64(nc.count), with("kind", nc.kind, "namespace", nc.namespace)...)
+		}
+
+		if c.Info.NodeCount > 0 {
+			nodes.add(float64(c.Info.NodeReady), with("state", "ready")...)
+			nodes.add(float64(c.Info.NodeNotReady), with("state", "not_ready")...)
+			nodes.add(float64(c.Info.NodeSchedDisabled), with("state", "scheduling_disabled")...)
+
+			addQuantity(cpu, c.Info.CPUCapacityTotal, with("type", "capacity"))
+			addQuantity(cpu, c.Info.CPUAllocatableTotal, with("type", "allocatable"))
+			addQuantity(cpu, c.Info.CPUUsedTotal, with("type", "used"))
+			addQuantity(memory, c.Info.MemoryCapacityTotal, with("type", "capacity"))
+			addQuantity(memory, c.Info.MemoryAllocatableTotal, with("type", "allocatable"))
+			addQuantity(memory, c.Info.MemoryUsedTotal, with("type", "used"))
+		}
+
+		if c.Stats != nil {
+			pods.add(float64(c.Stats.PodCount), base...)
+			podsNotRunning.add(float64(c.Stats.PodsNotRunning), base...)
+			podsWithRestarts.add(float64(c.Stats.PodsWithRestartsCount), base...)
+			namespaces.add(float64(c.Stats.NamespaceCount), base...)
+		}
+
+		statuses := make([]string, 0, len(c.Insights))
+		for status := range c.Insights {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+		for _, status := range statuses {
+			insights.add(float64(c.Insights[status]), with("status", status)...)
+		}
+	}
+
+	lastCollection := &metric{name: "kubectl_eks_last_collection_timestamp_seconds", help: "Unix time of the last finished collection"}
+	lastCollection.add(float64(snapshot.CollectedAt.Unix()))
+	duration := &metric{name: "kubectl_eks_collection_duration_seconds", help: "Duration of the last collection"}
+	duration.add(snapshot.Duration.Seconds())
+
+	bw := bufio.NewWriter(w)
+	for _, m := range []*metric{up, info, collectErrors, checked, unhealthy, nodes, cpu, memory, pods, podsNotRunning, podsWithRestarts, namespaces, insights, lastCollection, duration} {
+		writeMetric(bw, m)
+	}
+	return bw.Flush()
+}
+
+func writeMetric(w *bufio.Writer, m *metric) {
+	fmt.Fprintf(w, "# HELP %s %s\n", m.name, m.help)
+	fmt.Fprintf(w, "# TYPE %s gauge\n", m.name)
+	for _, s := range m.samples {
+		w.WriteString(m.name)
+		if len(s.labels) > 0 {
+			pairs := []string{}
+			for i := 0; i+1 < len(s.labels); i += 2 {
+				pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", s.labels[i], escapeLabelValue(s.labels[i+1])))
+			}
+			fmt.Fprintf(w, "{%s}", strings.Join(pairs, ","))
+		}
+		fmt.Fprintf(w, " %g\n", s.value)
+	}
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// addQuantity adds a Kubernetes quantity, such as the node totals of
+// ClusterInfo, as a plain number. Empty or invalid quantities are skipped.
+func addQuantity(m *metric, quantity string, labels []string) {
+	q, err := resource.ParseQuantity(quantity)
+	if err != nil {
+		return
+	}
+	m.add(q.AsApproximateFloat64(), labels...)
+}
+
+type kindCount struct {
+	kind  string
+	total int
+}
+
+// kindCounts returns the number of checked resources per kind of a summary
+func kindCounts(s data.ClusterHealthSummary) []kindCount {
+	counts := []kindCount{
+		{"Pod", s.TotalPods},
+		{"Deployment", s.TotalDeployments},
+		{"StatefulSet", s.TotalStatefulSets},
+		{"DaemonSet", s.TotalDaemonSets},
+		{"ReplicaSet", s.TotalReplicaSets},
+		{"Job", s.TotalJobs},
+		{"CronJob", s.TotalCronJobs},
+		{"PersistentVolumeClaim", s.TotalPVCs},
+		{"PodDisruptionBudget", s.TotalPDBs},
+		{"HorizontalPodAutoscaler", s.TotalHPAs},
+		{"Service", s.TotalServices},
+	}
+
+	custom := make([]string, 0, len(s.CustomTotals))
+	for kind := range s.CustomTotals {
+		custom = append(custom, kind)
+	}
+	sort.Strings(custom)
+	for _, kind := range custom {
+		counts = append(counts, kindCount{kind, s.CustomTotals[kind]})
+	}
+	return counts
+}
+
+type namespaceCount struct {
+	kind      string
+	namespace string
+	count     int
+}
+
+// unhealthyByNamespace counts the unhealthy results by kind and namespace
+func unhealthyByNamespace(results []data.HealthCheckResult) []namespaceCount {
+	counts := make(map[[2]string]int)
+	for _, r := range results {
+		if !r.IsHealthy {
+			counts[[2]string{r.Kind, r.Namespace}]++
+		}
+	}
+
+	out := make([]namespaceCount, 0, len(counts))
+	for key, count := range counts {
+		out = append(out, namespaceCount{kind: key[0], namespace: key[1], count: count})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].kind != out[j].kind {
+			return out[i].kind < out[j].kind
+		}
+		return out[i].namespace < out[j].namespace
+	})
+	return out
+}
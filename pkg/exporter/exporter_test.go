@@ -0,0 +1,87 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/jordiprats/kubectl-eks/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSnapshot = Snapshot{
+	CollectedAt: time.Unix(1717243200, 0),
+	Duration:    90 * time.Second,
+	Clusters: []Cluster{
+		{
+			Info: data.ClusterInfo{
+				AWSProfile: "prod", Region: "eu-west-1", ClusterName: "a", Version: "1.30",
+				NodeCount: 3, NodeReady: 2, NodeNotReady: 1, CPUCapacityTotal: "6", MemoryUsedTotal: "2Gi",
+			},
+			Health: data.ClusterHealthSummary{TotalPods: 10, HealthyPods: 8, CustomTotals: map[string]int{"Certificate": 2}, OverallStatus: "2 Unhealthy"},
+			Unhealthy: []data.HealthCheckResult{
+				{Kind: "Pod", Namespace: "shop", Name: "api"},
+				{Kind: "Pod", Namespace: "shop", Name: "worker"},
+			},
+			Stats:    &k8s.K8Sstats{PodCount: 10, PodsNotRunning: 2, NamespaceCount: 4},
+			Insights: map[string]int{"PASSING": 5, "WARNING": 1},
+		},
+		{
+			Info:   data.ClusterInfo{AWSProfile: "prod", Region: "eu-west-1", ClusterName: "b\"quoted"},
+			Health: data.ClusterHealthSummary{OverallStatus: "Error", Errors: []string{"connection refused"}},
+			Errors: []string{"connection refused"},
+		},
+	},
+}
+
+func TestWriteMetrics(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteMetrics(&buf, testSnapshot))
+	out := buf.String()
+
+	assert.Contains(t, out, "# TYPE kubectl_eks_cluster_up gauge\n")
+	assert.Contains(t, out, `kubectl_eks_cluster_up{profile="prod",region="eu-west-1",cluster="a"} 1`)
+	assert.Contains(t, out, `kubectl_eks_cluster_up{profile="prod",region="eu-west-1",cluster="b\"quoted"} 0`)
+	assert.Contains(t, out, `kubectl_eks_cluster_info{profile="prod",region="eu-west-1",cluster="a",version="1.30",arn=""} 1`)
+	assert.Contains(t, out, `kubectl_eks_resources_unhealthy{profile="prod",region="eu-west-1",cluster="a",kind="Pod",namespace="shop"} 2`)
+	assert.Contains(t, out, `kubectl_eks_resources_checked{profile="prod",region="eu-west-1",cluster="a",kind="Certificate"} 2`)
+	assert.Contains(t, out, `kubectl_eks_nodes{profile="prod",region="eu-west-1",cluster="a",state="not_ready"} 1`)
+	assert.Contains(t, out, `kubectl_eks_cpu_cores{profile="prod",region="eu-west-1",cluster="a",type="capacity"} 6`)
+	assert.Contains(t, out, `kubectl_eks_memory_bytes{profile="prod",region="eu-west-1",cluster="a",type="used"} 2.147483648e+09`)
+	assert.Contains(t, out, `kubectl_eks_pods_not_running{profile="prod",region="eu-west-1",cluster="a"} 2`)
+	assert.Contains(t, out, `kubectl_eks_insights{profile="prod",region="eu-west-1",cluster="a",status="WARNING"} 1`)
+	assert.Contains(t, out, `kubectl_eks_cluster_collect_errors{profile="prod",region="eu-west-1",cluster="b\"quoted"} 1`)
+	assert.Contains(t, out, "kubectl_eks_last_collection_timestamp_seconds 1.7172432e+09\n")
+	assert.Contains(t, out, "kubectl_eks_collection_duration_seconds 90\n")
+	assert.NotContains(t, out, `kubectl_eks_nodes{profile="prod",region="eu-west-1",cluster="b`, "clusters without node stats have no node samples")
+}
+
+func TestServerHandler(t *testing.T) {
+	server := &Server{}
+	handler := server.Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "no snapshot yet")
+
+	server.Update(testSnapshot)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "kubectl_eks_cluster_up")
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/clusters", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var decoded Snapshot
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &decoded))
+	require.Len(t, decoded.Clusters, 2)
+	assert.Equal(t, "a", decoded.Clusters[0].Info.ClusterName)
+	assert.Equal(t, 5, decoded.Clusters[0].Insights["PASSING"])
+}
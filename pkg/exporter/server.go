@@ -0,0 +1,62 @@
+package exporter
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// Server serves the latest snapshot as Prometheus metrics on /metrics and
+// as JSON on /api/v1/clusters. Until the first collection finishes both
+// endpoints answer 503.
+type Server struct {
+	mu       sync.RWMutex
+	snapshot *Snapshot
+}
+
+// Update replaces the served snapshot
+func (s *Server) Update(snapshot Snapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshot = &snapshot
+}
+
+func (s *Server) latest() *Snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.snapshot
+}
+
+// Handler returns the HTTP handler of the exporter
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		snapshot := s.latest()
+		if snapshot == nil {
+			http.Error(w, "no collection finished yet", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = WriteMetrics(w, *snapshot)
+	})
+
+	mux.HandleFunc("/api/v1/clusters", func(w http.ResponseWriter, r *http.Request) {
+		snapshot := s.latest()
+		if snapshot == nil {
+			http.Error(w, "no collection finished yet", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(snapshot)
+	})
+
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok\n"))
+	})
+
+	return mux
+}
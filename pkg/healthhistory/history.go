@@ -0,0 +1,303 @@
+package healthhistory
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+)
+
+// Change types reported by Compare. A resource that was unhealthy and is
+// not in the current run at all, because it was deleted or not checked this
+// time, is GONE rather than RECOVERED.
+const (
+	ChangeBroke     = "BROKE"
+	ChangeRecovered = "RECOVERED"
+	ChangeGone      = "GONE"
+)
+
+// NewSnapshot builds the snapshot of a run, keeping the unhealthy results
+// and the identity of the healthy ones
+func NewSnapshot(now time.Time, results []data.HealthCheckResult, summaries []data.ClusterHealthSummary) data.HealthSnapshot {
+	snapshot := data.HealthSnapshot{
+		Time:      now,
+		Summaries: summaries,
+		Results:   []data.HealthCheckResult{},
+		Healthy:   make(map[string][]string),
+	}
+	for _, s := range summaries {
+		snapshot.Healthy[clusterKey(s.Profile, s.Region, s.ClusterName)] = []string{}
+	}
+	for _, r := range results {
+		if !r.IsHealthy {
+			snapshot.Results = append(snapshot.Results, r)
+			continue
+		}
+		key := clusterKey(r.Profile, r.Region, r.ClusterName)
+		snapshot.Healthy[key] = append(snapshot.Healthy[key], resourceID(r))
+	}
+	return snapshot
+}
+
+// maxLineSize is the largest snapshot line read from the history file
+const maxLineSize = 64 * 1024 * 1024
+
+// Append adds a snapshot as a new line of the JSONL history file. Only the
+// latest keep runs are kept, so recording on a schedule doesn't grow the file
+// forever; keep 0 keeps every run.
+func Append(path string, snapshot data.HealthSnapshot, keep int) error {
+	line, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	if keep > 0 {
+		lines, err := readLines(path)
+		if err != nil {
+			return err
+		}
+		if len(lines) >= keep {
+			return rewrite(path, append(lines[len(lines)-keep+1:], line))
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// readLines returns the non-empty lines of the history file. A missing file
+// has no lines.
+func readLines(path string) ([][]byte, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	lines := [][]byte{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		lines = append(lines, append([]byte(nil), scanner.Bytes()...))
+	}
+	return lines, scanner.Err()
+}
+
+// rewrite replaces the history file with lines, through a temporary file so
+// an interrupted run never leaves it half written
+func rewrite(path string, lines [][]byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	w := bufio.NewWriter(f)
+	for _, line := range lines {
+		w.Write(line)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Load reads every snapshot of the history file, oldest first. A missing
+// file is an empty history.
+func Load(path string) ([]data.HealthSnapshot, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []data.HealthSnapshot{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	snapshots := []data.HealthSnapshot{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var snapshot data.HealthSnapshot
+		if err := json.Unmarshal(scanner.Bytes(), &snapshot); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path, lineNumber, err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].Time.Before(snapshots[j].Time)
+	})
+	return snapshots, nil
+}
+
+// Compare returns what broke, recovered or is gone in current since the
+// previous run of each of its clusters. The previous run of a cluster is the
+// latest snapshot in history that checked it. Clusters never checked before,
+// or that could not be fully checked in either run, are not compared.
+// Resources are only RECOVERED when current recorded them as healthy, which
+// snapshots written before healthy resources were recorded never do.
+func Compare(history []data.HealthSnapshot, current data.HealthSnapshot) []data.HealthChange {
+	changes := []data.HealthChange{}
+
+	for _, summary := range current.Summaries {
+		if len(summary.Errors) > 0 {
+			continue
+		}
+		key := clusterKey(summary.Profile, summary.Region, summary.ClusterName)
+
+		var previous *data.HealthSnapshot
+		for i := len(history) - 1; i >= 0; i-- {
+			if !history[i].Time.Before(current.Time) {
+				continue
+			}
+			if prevSummary, ok := findSummary(history[i], key); ok {
+				if len(prevSummary.Errors) == 0 {
+					previous = &history[i]
+				}
+				break
+			}
+		}
+		if previous == nil {
+			continue
+		}
+
+		before := unhealthyByResource(*previous, key)
+		after := unhealthyByResource(current, key)
+		healthy := make(map[string]bool)
+		for _, id := range current.Healthy[key] {
+			healthy[id] = true
+		}
+
+		for id, r := range after {
+			if _, ok := before[id]; !ok {
+				changes = append(changes, newChange(current.Time, ChangeBroke, r))
+			}
+		}
+		for id, r := range before {
+			if _, ok := after[id]; ok {
+				continue
+			}
+			if healthy[id] {
+				change := newChange(current.Time, ChangeRecovered, r)
+				change.Message = fmt.Sprintf("Was: %s", r.Message)
+				changes = append(changes, change)
+			} else {
+				change := newChange(current.Time, ChangeGone, r)
+				change.Message = fmt.Sprintf("Deleted or not checked, was: %s", r.Message)
+				changes = append(changes, change)
+			}
+		}
+	}
+
+	sortChanges(changes)
+	return changes
+}
+
+// Changelog compares every snapshot with the runs before it and returns all
+// the changes, oldest first
+func Changelog(history []data.HealthSnapshot) []data.HealthChange {
+	changes := []data.HealthChange{}
+	for i := range history {
+		changes = append(changes, Compare(history[:i], history[i])...)
+	}
+	return changes
+}
+
+func newChange(now time.Time, change string, r data.HealthCheckResult) data.HealthChange {
+	return data.HealthChange{
+		Time:        now,
+		Profile:     r.Profile,
+		Region:      r.Region,
+		ClusterName: r.ClusterName,
+		Change:      change,
+		Kind:        r.Kind,
+		Namespace:   r.Namespace,
+		Name:        r.Name,
+		Message:     r.Message,
+	}
+}
+
+func findSummary(snapshot data.HealthSnapshot, key string) (data.ClusterHealthSummary, bool) {
+	for _, s := range snapshot.Summaries {
+		if clusterKey(s.Profile, s.Region, s.ClusterName) == key {
+			return s, true
+		}
+	}
+	return data.ClusterHealthSummary{}, false
+}
+
+func unhealthyByResource(snapshot data.HealthSnapshot, key string) map[string]data.HealthCheckResult {
+	resources := make(map[string]data.HealthCheckResult)
+	for _, r := range snapshot.Results {
+		if r.IsHealthy || clusterKey(r.Profile, r.Region, r.ClusterName) != key {
+			continue
+		}
+		resources[resourceID(r)] = r
+	}
+	return resources
+}
+
+func resourceID(r data.HealthCheckResult) string {
+	return r.Kind + "/" + r.Namespace + "/" + r.Name
+}
+
+func clusterKey(profile, region, clusterName string) string {
+	return profile + "/" + region + "/" + clusterName
+}
+
+func sortChanges(changes []data.HealthChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if !a.Time.Equal(b.Time) {
+			return a.Time.Before(b.Time)
+		}
+		if ka, kb := clusterKey(a.Profile, a.Region, a.ClusterName), clusterKey(b.Profile, b.Region, b.ClusterName); ka != kb {
+			return ka < kb
+		}
+		if a.Change != b.Change {
+			return a.Change < b.Change
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+}
@@ -0,0 +1,155 @@
+package healthhistory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func summary(cluster string, errs ...string) data.ClusterHealthSummary {
+	return data.ClusterHealthSummary{Profile: "prod", Region: "eu-west-1", ClusterName: cluster, Errors: errs}
+}
+
+func unhealthy(cluster, kind, name, message string) data.HealthCheckResult {
+	return data.HealthCheckResult{Profile: "prod", Region: "eu-west-1", ClusterName: cluster, Kind: kind, Namespace: "shop", Name: name, Message: message}
+}
+
+func TestAppendAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+
+	snapshots, err := Load(path)
+	require.NoError(t, err)
+	assert.Empty(t, snapshots, "a missing file is an empty history")
+
+	healthy := unhealthy("a", "Pod", "api", "All containers ready")
+	healthy.IsHealthy = true
+	first := NewSnapshot(start, []data.HealthCheckResult{healthy, unhealthy("a", "Pod", "worker", "CrashLoopBackOff")}, []data.ClusterHealthSummary{summary("a")})
+	require.Len(t, first.Results, 1, "healthy results are not recorded")
+	assert.Equal(t, map[string][]string{"prod/eu-west-1/a": {"Pod/shop/api"}}, first.Healthy, "only their identity is")
+
+	require.NoError(t, Append(path, first, 0))
+	require.NoError(t, Append(path, NewSnapshot(start.Add(time.Hour), nil, []data.ClusterHealthSummary{summary("a")}), 0))
+
+	snapshots, err = Load(path)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.True(t, snapshots[0].Time.Equal(start))
+	assert.Equal(t, "worker", snapshots[0].Results[0].Name)
+
+	require.NoError(t, os.WriteFile(path, []byte("{broken\n"), 0644))
+	_, err = Load(path)
+	assert.ErrorContains(t, err, "line 1")
+}
+
+func TestAppend_Keep(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, Append(path, NewSnapshot(start.Add(time.Duration(i)*time.Hour), nil, []data.ClusterHealthSummary{summary("a")}), 3))
+	}
+
+	snapshots, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 3, "only the latest runs are kept")
+	assert.True(t, snapshots[0].Time.Equal(start.Add(2*time.Hour)))
+	assert.True(t, snapshots[2].Time.Equal(start.Add(4*time.Hour)))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+}
+
+func TestCompare(t *testing.T) {
+	history := []data.HealthSnapshot{
+		{
+			Time:      start,
+			Summaries: []data.ClusterHealthSummary{summary("a"), summary("b")},
+			Results: []data.HealthCheckResult{
+				unhealthy("a", "Pod", "worker", "CrashLoopBackOff"),
+				unhealthy("b", "Deployment", "checkout", "Ready 0/2"),
+			},
+		},
+		{
+			// A later run that only checked cluster b
+			Time:      start.Add(time.Hour),
+			Summaries: []data.ClusterHealthSummary{summary("b")},
+			Results:   []data.HealthCheckResult{unhealthy("b", "Deployment", "checkout", "Ready 0/2")},
+		},
+	}
+
+	current := data.HealthSnapshot{
+		Time:      start.Add(2 * time.Hour),
+		Summaries: []data.ClusterHealthSummary{summary("a"), summary("b"), summary("new")},
+		Results: []data.HealthCheckResult{
+			unhealthy("a", "Deployment", "cart", "Ready 1/3"),
+			unhealthy("b", "Deployment", "checkout", "Ready 1/2"),
+			unhealthy("new", "Pod", "api", "Pending"),
+		},
+		Healthy: map[string][]string{"prod/eu-west-1/a": {"Pod/shop/worker"}},
+	}
+
+	changes := Compare(history, current)
+	require.Len(t, changes, 2)
+	assert.Equal(t, ChangeBroke, changes[0].Change)
+	assert.Equal(t, "cart", changes[0].Name)
+	assert.Equal(t, ChangeRecovered, changes[1].Change)
+	assert.Equal(t, "worker", changes[1].Name)
+	assert.Equal(t, "Was: CrashLoopBackOff", changes[1].Message)
+}
+
+func TestCompare_SkipsClustersWithErrors(t *testing.T) {
+	history := []data.HealthSnapshot{{
+		Time:      start,
+		Summaries: []data.ClusterHealthSummary{summary("a")},
+		Results:   []data.HealthCheckResult{unhealthy("a", "Pod", "worker", "CrashLoopBackOff")},
+	}}
+	current := data.HealthSnapshot{
+		Time:      start.Add(time.Hour),
+		Summaries: []data.ClusterHealthSummary{summary("a", "pods: connection refused")},
+	}
+
+	assert.Empty(t, Compare(history, current), "resources missing from a failed check did not recover")
+}
+
+func TestChangelog(t *testing.T) {
+	history := []data.HealthSnapshot{
+		{Time: start, Summaries: []data.ClusterHealthSummary{summary("a")}},
+		{Time: start.Add(time.Hour), Summaries: []data.ClusterHealthSummary{summary("a")}, Results: []data.HealthCheckResult{unhealthy("a", "Pod", "worker", "OOMKilled")}},
+		{Time: start.Add(2 * time.Hour), Summaries: []data.ClusterHealthSummary{summary("a")}, Healthy: map[string][]string{"prod/eu-west-1/a": {"Pod/shop/worker"}}},
+	}
+
+	changes := Changelog(history)
+	require.Len(t, changes, 2)
+	assert.Equal(t, ChangeBroke, changes[0].Change)
+	assert.True(t, changes[0].Time.Equal(start.Add(time.Hour)))
+	assert.Equal(t, ChangeRecovered, changes[1].Change)
+}
+
+func TestCompare_Gone(t *testing.T) {
+	history := []data.HealthSnapshot{{
+		Time:      start,
+		Summaries: []data.ClusterHealthSummary{summary("a")},
+		Results: []data.HealthCheckResult{
+			unhealthy("a", "Pod", "worker-1", "CrashLoopBackOff"),
+			unhealthy("a", "Pod", "worker-2", "CrashLoopBackOff"),
+		},
+	}}
+	healthyWorker := unhealthy("a", "Pod", "worker-1", "All containers ready")
+	healthyWorker.IsHealthy = true
+	current := NewSnapshot(start.Add(time.Hour), []data.HealthCheckResult{healthyWorker}, []data.ClusterHealthSummary{summary("a")})
+
+	changes := Compare(history, current)
+	require.Len(t, changes, 2)
+	assert.Equal(t, ChangeGone, changes[0].Change)
+	assert.Equal(t, "worker-2", changes[0].Name)
+	assert.Equal(t, "Deleted or not checked, was: CrashLoopBackOff", changes[0].Message)
+	assert.Equal(t, ChangeRecovered, changes[1].Change)
+	assert.Equal(t, "worker-1", changes[1].Name)
+}
@@ -0,0 +1,321 @@
+package healthpolicy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"gopkg.in/yaml.v3"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Policy is the health policy file given to mcheck --policy
+//
+//	ignore:
+//	  namespaces: ["^kube-", "-sandbox$"]
+//	  resources:
+//	    - kind: Pod
+//	      name: "^debug-"
+//	    - selector: "team=experiments"
+//	pods:
+//	  maxRestarts: 5
+//	  maxPendingAge: 10m
+//	requirePDB:
+//	  kinds: [Deployment, StatefulSet]
+//	  minReplicas: 2
+//	kinds:
+//	  Deployment:
+//	    minReadyPercent: 75
+//	  ReplicaSet:
+//	    skip: true
+type Policy struct {
+	Ignore     IgnoreRules          `yaml:"ignore"`
+	Pods       PodRules             `yaml:"pods"`
+	RequirePDB *PDBRule             `yaml:"requirePDB"`
+	Kinds      map[string]KindRules `yaml:"kinds"`
+
+	rules []rule
+}
+
+// IgnoreRules selects resources that are left out of the results
+type IgnoreRules struct {
+	// Namespaces are regular expressions matched against the namespace
+	Namespaces []string          `yaml:"namespaces"`
+	Resources  []ResourceMatcher `yaml:"resources"`
+}
+
+// ResourceMatcher matches resources by kind, namespace and name regular
+// expressions and by label selector. Empty fields match everything.
+type ResourceMatcher struct {
+	Kind      string `yaml:"kind"`
+	Namespace string `yaml:"namespace"`
+	Name      string `yaml:"name"`
+	Selector  string `yaml:"selector"`
+}
+
+// PodRules are extra expectations for pods
+type PodRules struct {
+	// MaxRestarts marks pods as unhealthy when a container restarted more
+	// times than this and last terminated within the mcheck --restart-window.
+	// mcheck uses it instead of the --max-restarts default.
+	MaxRestarts *int32 `yaml:"maxRestarts"`
+	// MaxPendingAge tolerates Pending pods younger than this that are only
+	// waiting to be scheduled or for their containers to be created
+	MaxPendingAge time.Duration `yaml:"maxPendingAge"`
+}
+
+// PDBRule requires a PodDisruptionBudget covering the workloads of the given
+// kinds that run at least MinReplicas replicas
+type PDBRule struct {
+	Kinds       []string `yaml:"kinds"`
+	MinReplicas int32    `yaml:"minReplicas"`
+}
+
+// KindRules are the expectations for a single kind
+type KindRules struct {
+	// Skip drops every result of the kind
+	Skip bool `yaml:"skip"`
+	// MinReadyPercent is the share of ready replicas considered healthy
+	MinReadyPercent *int `yaml:"minReadyPercent"`
+}
+
+// Load reads and validates a policy file
+func Load(path string) (*Policy, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(content)
+}
+
+// Parse validates a policy and compiles it into rules
+func Parse(content []byte) (*Policy, error) {
+	policy := &Policy{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(policy); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+
+	if err := policy.compile(); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+	return policy, nil
+}
+
+// RequiresPDBs reports whether Evaluate needs the PodDisruptionBudgets
+func (p *Policy) RequiresPDBs() bool {
+	return p.RequirePDB != nil
+}
+
+// Evaluate applies the policy to the results of a single cluster. Ignored
+// resources are removed; the health of the others is re-evaluated. pdbs are
+// the PodDisruptionBudgets of the cluster, only used with requirePDB.
+func (p *Policy) Evaluate(results []data.HealthCheckResult, pdbs []policyv1.PodDisruptionBudget, now time.Time) []data.HealthCheckResult {
+	env := &evalContext{pdbs: pdbs, now: now}
+
+	evaluated := []data.HealthCheckResult{}
+	for _, result := range results {
+		keep := true
+		for _, r := range p.rules {
+			if !r(&result, env) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			evaluated = append(evaluated, result)
+		}
+	}
+	return evaluated
+}
+
+// evalContext holds the cluster wide data the rules need
+type evalContext struct {
+	pdbs []policyv1.PodDisruptionBudget
+	now  time.Time
+}
+
+// rule updates a result in place. Returning false drops the result.
+type rule func(r *data.HealthCheckResult, env *evalContext) bool
+
+// compile turns the policy into rules: ignores first, then rules that can
+// only make a resource healthy, and last the ones that can only make it
+// unhealthy so stricter expectations always win.
+func (p *Policy) compile() error {
+	p.rules = nil
+
+	for _, expr := range p.Ignore.Namespaces {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("ignore.namespaces: %w", err)
+		}
+		p.rules = append(p.rules, func(r *data.HealthCheckResult, env *evalContext) bool {
+			return !re.MatchString(r.Namespace)
+		})
+	}
+
+	for i, matcher := range p.Ignore.Resources {
+		match, err := compileMatcher(matcher)
+		if err != nil {
+			return fmt.Errorf("ignore.resources[%d]: %w", i, err)
+		}
+		p.rules = append(p.rules, func(r *data.HealthCheckResult, env *evalContext) bool {
+			return !match(r)
+		})
+	}
+
+	kinds := make(map[string]KindRules)
+	for kind, kindRules := range p.Kinds {
+		kinds[strings.ToLower(kind)] = kindRules
+		if kindRules.MinReadyPercent != nil && (*kindRules.MinReadyPercent < 0 || *kindRules.MinReadyPercent > 100) {
+			return fmt.Errorf("kinds.%s.minReadyPercent must be between 0 and 100", kind)
+		}
+	}
+	if len(kinds) > 0 {
+		p.rules = append(p.rules, func(r *data.HealthCheckResult, env *evalContext) bool {
+			return !kinds[strings.ToLower(r.Kind)].Skip
+		})
+		p.rules = append(p.rules, minReadyPercentRule(kinds))
+	}
+
+	if p.Pods.MaxPendingAge > 0 {
+		p.rules = append(p.rules, maxPendingAgeRule(p.Pods.MaxPendingAge))
+	}
+
+	if p.Pods.MaxRestarts != nil {
+		p.rules = append(p.rules, maxRestartsRule(*p.Pods.MaxRestarts))
+	}
+
+	if p.RequirePDB != nil {
+		if len(p.RequirePDB.Kinds) == 0 {
+			return fmt.Errorf("requirePDB.kinds must list at least one kind")
+		}
+		p.rules = append(p.rules, requirePDBRule(*p.RequirePDB))
+	}
+
+	return nil
+}
+
+func compileMatcher(matcher ResourceMatcher) (func(r *data.HealthCheckResult) bool, error) {
+	var namespaceRe, nameRe *regexp.Regexp
+	var err error
+
+	if matcher.Namespace != "" {
+		if namespaceRe, err = regexp.Compile(matcher.Namespace); err != nil {
+			return nil, err
+		}
+	}
+	if matcher.Name != "" {
+		if nameRe, err = regexp.Compile(matcher.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	var selector labels.Selector
+	if matcher.Selector != "" {
+		if selector, err = labels.Parse(matcher.Selector); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(r *data.HealthCheckResult) bool {
+		if matcher.Kind != "" && !strings.EqualFold(matcher.Kind, r.Kind) {
+			return false
+		}
+		if namespaceRe != nil && !namespaceRe.MatchString(r.Namespace) {
+			return false
+		}
+		if nameRe != nil && !nameRe.MatchString(r.Name) {
+			return false
+		}
+		if selector != nil && !selector.Matches(labels.Set(r.Labels)) {
+			return false
+		}
+		return true
+	}, nil
+}
+
+func minReadyPercentRule(kinds map[string]KindRules) rule {
+	return func(r *data.HealthCheckResult, env *evalContext) bool {
+		kindRules := kinds[strings.ToLower(r.Kind)]
+		if kindRules.MinReadyPercent == nil || r.Kind == "Pod" || r.DesiredReplicas == 0 {
+			return true
+		}
+
+		percent := int(r.ReadyReplicas * 100 / r.DesiredReplicas)
+		if !r.IsHealthy && percent >= *kindRules.MinReadyPercent {
+			r.IsHealthy = true
+			r.Message = fmt.Sprintf("%d%% of replicas ready (policy minimum %d%%)", percent, *kindRules.MinReadyPercent)
+		}
+		return true
+	}
+}
+
+func maxPendingAgeRule(maxAge time.Duration) rule {
+	return func(r *data.HealthCheckResult, env *evalContext) bool {
+		if r.Kind != "Pod" || !r.Starting || r.CreatedAt.IsZero() {
+			return true
+		}
+
+		age := env.now.Sub(r.CreatedAt).Truncate(time.Second)
+		if age <= maxAge {
+			r.IsHealthy = true
+			r.Message = fmt.Sprintf("%s (pending for %s, allowed %s)", r.Message, age, maxAge)
+		} else {
+			r.Message = fmt.Sprintf("%s (pending for %s, more than %s)", r.Message, age, maxAge)
+		}
+		return true
+	}
+}
+
+func maxRestartsRule(maxRestarts int32) rule {
+	return func(r *data.HealthCheckResult, env *evalContext) bool {
+		if r.Kind != "Pod" || r.RecentRestarts <= maxRestarts {
+			return true
+		}
+		r.IsHealthy = false
+		r.Message = fmt.Sprintf("Restarting: container restarted %d times (policy maximum %d)", r.RecentRestarts, maxRestarts)
+		return true
+	}
+}
+
+func requirePDBRule(pdbRule PDBRule) rule {
+	return func(r *data.HealthCheckResult, env *evalContext) bool {
+		required := false
+		for _, kind := range pdbRule.Kinds {
+			if strings.EqualFold(kind, r.Kind) {
+				required = true
+				break
+			}
+		}
+		if !required || r.DesiredReplicas < pdbRule.MinReplicas {
+			return true
+		}
+
+		for _, pdb := range env.pdbs {
+			if pdb.Namespace != r.Namespace || pdb.Spec.Selector == nil {
+				continue
+			}
+			selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+			if err != nil || selector.Empty() {
+				continue
+			}
+			if selector.Matches(labels.Set(r.PodTemplateLabels)) {
+				return true
+			}
+		}
+
+		r.IsHealthy = false
+		r.Message = "No PodDisruptionBudget covers this workload"
+		return true
+	}
+}
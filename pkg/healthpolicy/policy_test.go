@@ -0,0 +1,143 @@
+package healthpolicy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func names(results []data.HealthCheckResult) []string {
+	out := []string{}
+	for _, r := range results {
+		out = append(out, r.Kind+"/"+r.Name)
+	}
+	return out
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := Parse([]byte("ignore:\n  namespaces: [\"(\"]\n"))
+	assert.ErrorContains(t, err, "ignore.namespaces")
+
+	_, err = Parse([]byte("pods:\n  maxRestart: 3\n"))
+	assert.Error(t, err, "unknown fields are rejected")
+
+	_, err = Parse([]byte("kinds:\n  Deployment:\n    minReadyPercent: 120\n"))
+	assert.ErrorContains(t, err, "between 0 and 100")
+
+	_, err = Parse([]byte("requirePDB:\n  minReplicas: 2\n"))
+	assert.ErrorContains(t, err, "requirePDB.kinds")
+
+	policy, err := Parse([]byte(""))
+	require.NoError(t, err)
+	assert.False(t, policy.RequiresPDBs())
+}
+
+func TestEvaluate_Ignore(t *testing.T) {
+	policy, err := Parse([]byte(`
+ignore:
+  namespaces: ["^kube-"]
+  resources:
+    - kind: pod
+      name: "^debug-"
+    - selector: "team=experiments"
+kinds:
+  ReplicaSet:
+    skip: true
+`))
+	require.NoError(t, err)
+
+	results := []data.HealthCheckResult{
+		{Kind: "Pod", Namespace: "kube-system", Name: "coredns"},
+		{Kind: "Pod", Namespace: "shop", Name: "debug-shell"},
+		{Kind: "Deployment", Namespace: "shop", Name: "debug-tools"},
+		{Kind: "Deployment", Namespace: "shop", Name: "labs", Labels: map[string]string{"team": "experiments"}},
+		{Kind: "ReplicaSet", Namespace: "shop", Name: "checkout-123"},
+		{Kind: "Deployment", Namespace: "shop", Name: "checkout"},
+	}
+
+	assert.Equal(t, []string{"Deployment/debug-tools", "Deployment/checkout"}, names(policy.Evaluate(results, nil, now)))
+}
+
+func TestEvaluate_Pods(t *testing.T) {
+	policy, err := Parse([]byte(`
+pods:
+  maxRestarts: 3
+  maxPendingAge: 10m
+`))
+	require.NoError(t, err)
+
+	results := policy.Evaluate([]data.HealthCheckResult{
+		{Kind: "Pod", Name: "young", Status: "Pending", Message: "Pending", Starting: true, CreatedAt: now.Add(-5 * time.Minute)},
+		{Kind: "Pod", Name: "old", Status: "Pending", Message: "Pending", Starting: true, CreatedAt: now.Add(-time.Hour)},
+		{Kind: "Pod", Name: "flapping", Status: "Running", IsHealthy: true, RecentRestarts: 7},
+		{Kind: "Pod", Name: "stable", Status: "Running", IsHealthy: true, RecentRestarts: 3},
+		{Kind: "Pod", Name: "bad-image", Status: "Pending", Message: "ImagePullBackOff: container app", CreatedAt: now.Add(-time.Minute)},
+	}, nil, now)
+
+	require.Len(t, results, 5)
+	assert.True(t, results[0].IsHealthy)
+	assert.Equal(t, "Pending (pending for 5m0s, allowed 10m0s)", results[0].Message)
+	assert.False(t, results[1].IsHealthy)
+	assert.False(t, results[2].IsHealthy)
+	assert.Equal(t, "Restarting: container restarted 7 times (policy maximum 3)", results[2].Message)
+	assert.True(t, results[3].IsHealthy)
+	assert.False(t, results[4].IsHealthy, "container errors are not tolerated while pending")
+	assert.Equal(t, "ImagePullBackOff: container app", results[4].Message)
+}
+
+func TestEvaluate_MinReadyPercent(t *testing.T) {
+	policy, err := Parse([]byte(`
+kinds:
+  Deployment:
+    minReadyPercent: 75
+`))
+	require.NoError(t, err)
+
+	results := policy.Evaluate([]data.HealthCheckResult{
+		{Kind: "Deployment", Name: "enough", DesiredReplicas: 4, ReadyReplicas: 3},
+		{Kind: "Deployment", Name: "too-few", DesiredReplicas: 4, ReadyReplicas: 2, Message: "Ready 2/4"},
+		{Kind: "StatefulSet", Name: "other-kind", DesiredReplicas: 4, ReadyReplicas: 3},
+	}, nil, now)
+
+	assert.True(t, results[0].IsHealthy)
+	assert.False(t, results[1].IsHealthy)
+	assert.Equal(t, "Ready 2/4", results[1].Message)
+	assert.False(t, results[2].IsHealthy)
+}
+
+func TestEvaluate_RequirePDB(t *testing.T) {
+	policy, err := Parse([]byte(`
+requirePDB:
+  kinds: [Deployment]
+  minReplicas: 2
+`))
+	require.NoError(t, err)
+	assert.True(t, policy.RequiresPDBs())
+
+	pdbs := []policyv1.PodDisruptionBudget{{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout"},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "checkout"}},
+		},
+	}}
+
+	results := policy.Evaluate([]data.HealthCheckResult{
+		{Kind: "Deployment", Namespace: "shop", Name: "checkout", IsHealthy: true, DesiredReplicas: 3, PodTemplateLabels: map[string]string{"app": "checkout"}},
+		{Kind: "Deployment", Namespace: "shop", Name: "cart", IsHealthy: true, DesiredReplicas: 3, PodTemplateLabels: map[string]string{"app": "cart"}},
+		{Kind: "Deployment", Namespace: "other", Name: "checkout", IsHealthy: true, DesiredReplicas: 3, PodTemplateLabels: map[string]string{"app": "checkout"}},
+		{Kind: "Deployment", Namespace: "shop", Name: "single", IsHealthy: true, DesiredReplicas: 1, PodTemplateLabels: map[string]string{"app": "single"}},
+	}, pdbs, now)
+
+	assert.True(t, results[0].IsHealthy)
+	assert.False(t, results[1].IsHealthy)
+	assert.Equal(t, "No PodDisruptionBudget covers this workload", results[1].Message)
+	assert.False(t, results[2].IsHealthy, "PDBs only cover workloads in their namespace")
+	assert.True(t, results[3].IsHealthy, "workloads below minReplicas do not need a PDB")
+}
@@ -0,0 +1,168 @@
+package k8s
+
+import (
+	"context"
+	"time"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	// EventsV1GVR is the events.k8s.io/v1 view of the events
+	EventsV1GVR = schema.GroupVersionResource{Group: "events.k8s.io", Version: "v1", Resource: "events"}
+	// CoreEventsGVR is the legacy core/v1 view of the same events
+	CoreEventsGVR = schema.GroupVersionResource{Version: "v1", Resource: "events"}
+)
+
+// ListEvents lists the events of the given namespace (all namespaces when
+// empty) through events.k8s.io/v1, falling back to core/v1 on clusters that
+// do not serve it. Namespaces that could not be read are returned as
+// inaccessible.
+func ListEvents(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]data.EventInfo, []string, error) {
+	events, inaccessible, err := ListNamespaced(ctx, clientset, namespace, func(ctx context.Context, ns string) ([]data.EventInfo, error) {
+		list, err := clientset.EventsV1().Events(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		infos := make([]data.EventInfo, 0, len(list.Items))
+		for i := range list.Items {
+			infos = append(infos, EventInfoFromV1(&list.Items[i]))
+		}
+		return infos, nil
+	})
+	if err == nil || !apierrors.IsNotFound(err) {
+		return events, inaccessible, err
+	}
+
+	return ListNamespaced(ctx, clientset, namespace, func(ctx context.Context, ns string) ([]data.EventInfo, error) {
+		list, err := clientset.CoreV1().Events(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		infos := make([]data.EventInfo, 0, len(list.Items))
+		for i := range list.Items {
+			infos = append(infos, EventInfoFromCore(&list.Items[i]))
+		}
+		return infos, nil
+	})
+}
+
+// EventInfoFromV1 converts an events.k8s.io/v1 event. The count and last
+// time come from the event series when there is one, otherwise from the
+// deprecated fields older recorders still fill.
+func EventInfoFromV1(event *eventsv1.Event) data.EventInfo {
+	count := event.DeprecatedCount
+	lastSeen := firstNonZeroTime(event.DeprecatedLastTimestamp.Time, event.EventTime.Time, event.CreationTimestamp.Time)
+	if event.Series != nil {
+		count = event.Series.Count
+		lastSeen = firstNonZeroTime(event.Series.LastObservedTime.Time, lastSeen)
+	}
+
+	return data.EventInfo{
+		Namespace:    event.Namespace,
+		FirstSeen:    firstNonZeroTime(event.DeprecatedFirstTimestamp.Time, event.EventTime.Time, event.CreationTimestamp.Time),
+		LastSeen:     lastSeen,
+		Type:         event.Type,
+		Reason:       event.Reason,
+		InvolvedKind: event.Regarding.Kind,
+		Object:       event.Regarding.Kind + "/" + event.Regarding.Name,
+		Message:      event.Note,
+		Count:        max(count, 1),
+	}
+}
+
+// EventInfoFromCore converts a core/v1 event
+func EventInfoFromCore(event *corev1.Event) data.EventInfo {
+	count := event.Count
+	lastSeen := firstNonZeroTime(event.LastTimestamp.Time, event.EventTime.Time, event.CreationTimestamp.Time)
+	if event.Series != nil {
+		count = event.Series.Count
+		lastSeen = firstNonZeroTime(event.Series.LastObservedTime.Time, lastSeen)
+	}
+
+	return data.EventInfo{
+		Namespace:    event.Namespace,
+		FirstSeen:    firstNonZeroTime(event.FirstTimestamp.Time, event.EventTime.Time, event.CreationTimestamp.Time),
+		LastSeen:     lastSeen,
+		Type:         event.Type,
+		Reason:       event.Reason,
+		InvolvedKind: event.InvolvedObject.Kind,
+		Object:       event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name,
+		Message:      event.Message,
+		Count:        max(count, 1),
+	}
+}
+
+// EventKey identifies repeated occurrences of the same event: the recorders
+// start a new Event object (or series) for an occurrence that was already
+// reported, e.g. after the previous one expired or a component restarted.
+type EventKey struct {
+	Profile     string
+	Region      string
+	ClusterName string
+	Namespace   string
+	Type        string
+	Reason      string
+	Object      string
+	Message     string
+}
+
+// KeyOf returns the deduplication key of an event
+func KeyOf(event data.EventInfo) EventKey {
+	return EventKey{
+		Profile:     event.Profile,
+		Region:      event.Region,
+		ClusterName: event.ClusterName,
+		Namespace:   event.Namespace,
+		Type:        event.Type,
+		Reason:      event.Reason,
+		Object:      event.Object,
+		Message:     event.Message,
+	}
+}
+
+// DedupeEvents merges the events that share a key, adding up their counts
+// and keeping the earliest first and latest last seen times
+func DedupeEvents(events []data.EventInfo) []data.EventInfo {
+	merged := []data.EventInfo{}
+	index := make(map[EventKey]int)
+
+	for _, event := range events {
+		key := KeyOf(event)
+		i, exists := index[key]
+		if !exists {
+			index[key] = len(merged)
+			merged = append(merged, event)
+			continue
+		}
+		merged[i] = mergeEvents(merged[i], event)
+	}
+
+	return merged
+}
+
+func mergeEvents(a, b data.EventInfo) data.EventInfo {
+	a.Count += b.Count
+	if b.LastSeen.After(a.LastSeen) {
+		a.LastSeen = b.LastSeen
+	}
+	if !b.FirstSeen.IsZero() && (a.FirstSeen.IsZero() || b.FirstSeen.Before(a.FirstSeen)) {
+		a.FirstSeen = b.FirstSeen
+	}
+	return a
+}
+
+func firstNonZeroTime(times ...time.Time) time.Time {
+	for _, t := range times {
+		if !t.IsZero() {
+			return t
+		}
+	}
+	return time.Time{}
+}
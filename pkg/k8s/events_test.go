@@ -0,0 +1,101 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var eventTestTime = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func TestEventInfoFromV1(t *testing.T) {
+	event := &eventsv1.Event{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "api.1"},
+		EventTime:  metav1.NewMicroTime(eventTestTime),
+		Series: &eventsv1.EventSeries{
+			Count:            7,
+			LastObservedTime: metav1.NewMicroTime(eventTestTime.Add(10 * time.Minute)),
+		},
+		Regarding: corev1.ObjectReference{Kind: "Pod", Name: "api"},
+		Reason:    "BackOff",
+		Type:      "Warning",
+		Note:      "Back-off restarting failed container",
+	}
+
+	info := EventInfoFromV1(event)
+	assert.Equal(t, int32(7), info.Count)
+	assert.Equal(t, eventTestTime, info.FirstSeen)
+	assert.Equal(t, eventTestTime.Add(10*time.Minute), info.LastSeen)
+	assert.Equal(t, "Pod", info.InvolvedKind)
+	assert.Equal(t, "Pod/api", info.Object)
+	assert.Equal(t, "Back-off restarting failed container", info.Message)
+
+	single := EventInfoFromV1(&eventsv1.Event{EventTime: metav1.NewMicroTime(eventTestTime)})
+	assert.Equal(t, int32(1), single.Count, "an event without series happened once")
+	assert.Equal(t, eventTestTime, single.LastSeen)
+}
+
+func TestEventInfoFromCore(t *testing.T) {
+	info := EventInfoFromCore(&corev1.Event{
+		FirstTimestamp: metav1.NewTime(eventTestTime),
+		LastTimestamp:  metav1.NewTime(eventTestTime.Add(time.Hour)),
+		Count:          3,
+		InvolvedObject: corev1.ObjectReference{Kind: "Node", Name: "ip-10-0-0-1"},
+		Reason:         "NodeNotReady",
+	})
+
+	assert.Equal(t, int32(3), info.Count)
+	assert.Equal(t, eventTestTime, info.FirstSeen)
+	assert.Equal(t, eventTestTime.Add(time.Hour), info.LastSeen)
+	assert.Equal(t, "Node/ip-10-0-0-1", info.Object)
+}
+
+func TestListEvents_FallsBackToCoreEvents(t *testing.T) {
+	clientset := fake.NewClientset(&corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "default", Name: "web.1"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web"},
+		Reason:         "Scheduled",
+		Count:          1,
+	})
+	clientset.PrependReactor("list", "events", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetResource().Group == "events.k8s.io" {
+			return true, nil, apierrors.NewNotFound(EventsV1GVR.GroupResource(), "")
+		}
+		return false, nil, nil
+	})
+
+	events, inaccessible, err := ListEvents(context.Background(), clientset, "")
+	require.NoError(t, err)
+	assert.Empty(t, inaccessible)
+	require.Len(t, events, 1)
+	assert.Equal(t, "Pod/web", events[0].Object)
+}
+
+func TestDedupeEvents(t *testing.T) {
+	backOff := data.EventInfo{ClusterName: "a", Namespace: "shop", Type: "Warning", Reason: "BackOff", Object: "Pod/api", Message: "Back-off"}
+
+	first := backOff
+	first.Count, first.FirstSeen, first.LastSeen = 5, eventTestTime, eventTestTime.Add(time.Minute)
+	second := backOff
+	second.Count, second.FirstSeen, second.LastSeen = 2, eventTestTime.Add(time.Hour), eventTestTime.Add(2*time.Hour)
+	otherCluster := backOff
+	otherCluster.ClusterName, otherCluster.Count = "b", 1
+
+	merged := DedupeEvents([]data.EventInfo{first, otherCluster, second})
+	require.Len(t, merged, 2, "the same event of another cluster is kept apart")
+	assert.Equal(t, int32(7), merged[0].Count)
+	assert.Equal(t, eventTestTime, merged[0].FirstSeen)
+	assert.Equal(t, eventTestTime.Add(2*time.Hour), merged[0].LastSeen)
+	assert.Equal(t, "b", merged[1].ClusterName)
+}
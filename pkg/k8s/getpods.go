@@ -37,7 +37,7 @@ type K8SClusterPodList struct {
 	Pods        []K8SPodInfo
 }
 
-func GetPods(awsRegion, region, clusterName, arn, version, namespace string, allNamespaces bool) (*K8SClusterPodList, error) {
+func GetPods(awsRegion, region, clusterName, arn, version, namespace string, allNamespaces bool, labelSelector string) (*K8SClusterPodList, error) {
 	podList := &K8SClusterPodList{
 		AWSProfile:  awsRegion,
 		Region:      region,
@@ -73,7 +73,7 @@ func GetPods(awsRegion, region, clusterName, arn, version, namespace string, all
 	}
 
 	// Pods
-	pods, err := clientset.CoreV1().Pods(queryNamespace).List(context.TODO(), metav1.ListOptions{})
+	pods, err := clientset.CoreV1().Pods(queryNamespace).List(context.TODO(), metav1.ListOptions{LabelSelector: labelSelector})
 	if err != nil {
 		return nil, err
 	}
@@ -9,51 +9,32 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 )
 
-func GetServiceAccountsWithIRSA(ctx context.Context, namespace string) ([]corev1.ServiceAccount, error) {
+// GetServiceAccountsWithIRSA returns the service accounts annotated with an
+// IRSA role, along with any namespaces that could not be listed.
+func GetServiceAccountsWithIRSA(ctx context.Context, namespace string) ([]corev1.ServiceAccount, []string, error) {
 	config, err := clientcmd.BuildConfigFromFlags("", clientcmd.RecommendedHomeFile)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	var serviceAccounts []corev1.ServiceAccount
-
-	if namespace == "" {
-		// Get service accounts from all namespaces
-		namespaceList, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
-		if err != nil {
-			return nil, err
-		}
+	saList, inaccessible, err := ListNamespaced(ctx, clientset, namespace, serviceAccountLister(clientset))
+	if err != nil {
+		return nil, nil, err
+	}
 
-		for _, ns := range namespaceList.Items {
-			saList, err := clientset.CoreV1().ServiceAccounts(ns.Name).List(ctx, metav1.ListOptions{})
-			if err != nil {
-				continue
-			}
-			for _, sa := range saList.Items {
-				if _, ok := sa.Annotations["eks.amazonaws.com/role-arn"]; ok {
-					serviceAccounts = append(serviceAccounts, sa)
-				}
-			}
-		}
-	} else {
-		// Get service accounts from specific namespace
-		saList, err := clientset.CoreV1().ServiceAccounts(namespace).List(ctx, metav1.ListOptions{})
-		if err != nil {
-			return nil, err
-		}
-		for _, sa := range saList.Items {
-			if _, ok := sa.Annotations["eks.amazonaws.com/role-arn"]; ok {
-				serviceAccounts = append(serviceAccounts, sa)
-			}
+	var serviceAccounts []corev1.ServiceAccount
+	for _, sa := range saList {
+		if _, ok := sa.Annotations["eks.amazonaws.com/role-arn"]; ok {
+			serviceAccounts = append(serviceAccounts, sa)
 		}
 	}
 
-	return serviceAccounts, nil
+	return serviceAccounts, inaccessible, nil
 }
 
 func GetPodsWithKube2IAM(ctx context.Context, namespace string) ([]corev1.Pod, error) {
@@ -96,95 +77,64 @@ func GetPodsWithKube2IAM(ctx context.Context, namespace string) ([]corev1.Pod, e
 	return pods, nil
 }
 
-func GetServiceAccountsWithPodIdentity(ctx context.Context, namespace string) ([]corev1.ServiceAccount, error) {
+// GetServiceAccountsWithPodIdentity returns the service accounts carrying any
+// IAM-related annotation or label, along with any namespaces that could not
+// be listed.
+func GetServiceAccountsWithPodIdentity(ctx context.Context, namespace string) ([]corev1.ServiceAccount, []string, error) {
 	config, err := clientcmd.BuildConfigFromFlags("", clientcmd.RecommendedHomeFile)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	saList, inaccessible, err := ListNamespaced(ctx, clientset, namespace, serviceAccountLister(clientset))
+	if err != nil {
+		return nil, nil, err
 	}
 
 	var serviceAccounts []corev1.ServiceAccount
+	for _, sa := range saList {
+		// Check for any IAM-related annotations/labels
+		hasIAM := false
 
-	if namespace == "" {
-		// Get service accounts from all namespaces
-		namespaceList, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
-		if err != nil {
-			return nil, err
+		// IRSA annotation
+		if _, ok := sa.Annotations["eks.amazonaws.com/role-arn"]; ok {
+			hasIAM = true
+		}
+		// EKS Pod Identity annotations
+		if _, ok := sa.Annotations["eks.amazonaws.com/service-account-role-arn"]; ok {
+			hasIAM = true
+		}
+		if _, ok := sa.Annotations["eks.amazonaws.com/pod-identity-association"]; ok {
+			hasIAM = true
+		}
+		// EKS Pod Identity labels
+		if _, ok := sa.Labels["eks.amazonaws.com/pod-identity-association"]; ok {
+			hasIAM = true
+		}
+		// Legacy IAM annotation
+		if _, ok := sa.Annotations["iam.amazonaws.com/role"]; ok {
+			hasIAM = true
 		}
 
-		for _, ns := range namespaceList.Items {
-			saList, err := clientset.CoreV1().ServiceAccounts(ns.Name).List(ctx, metav1.ListOptions{})
-			if err != nil {
-				continue
-			}
-			for _, sa := range saList.Items {
-				// Check for any IAM-related annotations/labels
-				hasIAM := false
-
-				// IRSA annotation
-				if _, ok := sa.Annotations["eks.amazonaws.com/role-arn"]; ok {
-					hasIAM = true
-				}
-				// EKS Pod Identity annotations
-				if _, ok := sa.Annotations["eks.amazonaws.com/service-account-role-arn"]; ok {
-					hasIAM = true
-				}
-				if _, ok := sa.Annotations["eks.amazonaws.com/pod-identity-association"]; ok {
-					hasIAM = true
-				}
-				// EKS Pod Identity labels
-				if _, ok := sa.Labels["eks.amazonaws.com/pod-identity-association"]; ok {
-					hasIAM = true
-				}
-				// Legacy IAM annotation
-				if _, ok := sa.Annotations["iam.amazonaws.com/role"]; ok {
-					hasIAM = true
-				}
-
-				if hasIAM {
-					serviceAccounts = append(serviceAccounts, sa)
-				}
-			}
+		if hasIAM {
+			serviceAccounts = append(serviceAccounts, sa)
 		}
-	} else {
-		// Get service accounts from specific namespace
+	}
+
+	return serviceAccounts, inaccessible, nil
+}
+
+func serviceAccountLister(clientset kubernetes.Interface) ListFunc[corev1.ServiceAccount] {
+	return func(ctx context.Context, namespace string) ([]corev1.ServiceAccount, error) {
 		saList, err := clientset.CoreV1().ServiceAccounts(namespace).List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return nil, err
 		}
-		for _, sa := range saList.Items {
-			// Check for any IAM-related annotations/labels
-			hasIAM := false
-
-			// IRSA annotation
-			if _, ok := sa.Annotations["eks.amazonaws.com/role-arn"]; ok {
-				hasIAM = true
-			}
-			// EKS Pod Identity annotations
-			if _, ok := sa.Annotations["eks.amazonaws.com/service-account-role-arn"]; ok {
-				hasIAM = true
-			}
-			if _, ok := sa.Annotations["eks.amazonaws.com/pod-identity-association"]; ok {
-				hasIAM = true
-			}
-			// EKS Pod Identity labels
-			if _, ok := sa.Labels["eks.amazonaws.com/pod-identity-association"]; ok {
-				hasIAM = true
-			}
-			// Legacy IAM annotation
-			if _, ok := sa.Annotations["iam.amazonaws.com/role"]; ok {
-				hasIAM = true
-			}
-
-			if hasIAM {
-				serviceAccounts = append(serviceAccounts, sa)
-			}
-		}
+		return saList.Items, nil
 	}
-
-	return serviceAccounts, nil
 }
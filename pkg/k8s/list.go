@@ -0,0 +1,55 @@
+package k8s
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ListFunc lists the items of a namespaced resource in a single namespace.
+// An empty namespace means all namespaces.
+type ListFunc[T any] func(ctx context.Context, namespace string) ([]T, error)
+
+// ListNamespaced lists a namespaced resource. When namespace is set only that
+// namespace is queried. Otherwise a single cluster-wide List is issued and,
+// only if RBAC forbids it, the namespaces are listed one by one. Namespaces
+// RBAC forbids during the fallback are returned as inaccessible; any other
+// error is returned.
+func ListNamespaced[T any](ctx context.Context, clientset kubernetes.Interface, namespace string, list ListFunc[T]) ([]T, []string, error) {
+	if namespace != "" {
+		items, err := list(ctx, namespace)
+		return items, nil, err
+	}
+
+	items, err := list(ctx, metav1.NamespaceAll)
+	if err == nil {
+		return items, nil, nil
+	}
+	if !apierrors.IsForbidden(err) {
+		return nil, nil, err
+	}
+
+	// Cluster-wide list denied, fall back to per-namespace iteration
+	namespaceList, nsErr := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if nsErr != nil {
+		return nil, nil, err
+	}
+
+	items = nil
+	inaccessible := []string{}
+	for _, ns := range namespaceList.Items {
+		nsItems, err := list(ctx, ns.Name)
+		if apierrors.IsForbidden(err) {
+			inaccessible = append(inaccessible, ns.Name)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		items = append(items, nsItems...)
+	}
+
+	return items, inaccessible, nil
+}
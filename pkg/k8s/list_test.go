@@ -0,0 +1,130 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newNamespace(name string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+func newServiceAccount(namespace, name string) *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+}
+
+// denyServiceAccountList makes listing service accounts forbidden for the
+// given namespaces ("" being the cluster-wide list).
+func denyServiceAccountList(clientset *fake.Clientset, namespaces ...string) {
+	denied := make(map[string]bool)
+	for _, ns := range namespaces {
+		denied[ns] = true
+	}
+	clientset.PrependReactor("list", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if denied[action.GetNamespace()] {
+			gr := schema.GroupResource{Resource: "serviceaccounts"}
+			return true, nil, apierrors.NewForbidden(gr, "", errors.New("denied"))
+		}
+		return false, nil, nil
+	})
+}
+
+func TestListNamespaced_ClusterWide(t *testing.T) {
+	clientset := fake.NewClientset(
+		newNamespace("a"), newNamespace("b"),
+		newServiceAccount("a", "sa1"), newServiceAccount("b", "sa2"),
+	)
+
+	calls := 0
+	items, inaccessible, err := ListNamespaced(context.Background(), clientset, "", func(ctx context.Context, ns string) ([]corev1.ServiceAccount, error) {
+		calls++
+		return serviceAccountLister(clientset)(ctx, ns)
+	})
+
+	require.NoError(t, err)
+	assert.Len(t, items, 2)
+	assert.Empty(t, inaccessible)
+	assert.Equal(t, 1, calls, "a single cluster-wide list is expected")
+}
+
+func TestListNamespaced_SingleNamespace(t *testing.T) {
+	clientset := fake.NewClientset(
+		newNamespace("a"), newNamespace("b"),
+		newServiceAccount("a", "sa1"), newServiceAccount("b", "sa2"),
+	)
+
+	items, inaccessible, err := ListNamespaced(context.Background(), clientset, "b", serviceAccountLister(clientset))
+
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "sa2", items[0].Name)
+	assert.Empty(t, inaccessible)
+}
+
+func TestListNamespaced_FallbackOnForbidden(t *testing.T) {
+	clientset := fake.NewClientset(
+		newNamespace("a"), newNamespace("b"), newNamespace("c"),
+		newServiceAccount("a", "sa1"), newServiceAccount("b", "sa2"), newServiceAccount("c", "sa3"),
+	)
+	denyServiceAccountList(clientset, "", "b")
+
+	items, inaccessible, err := ListNamespaced(context.Background(), clientset, "", serviceAccountLister(clientset))
+
+	require.NoError(t, err)
+	names := []string{}
+	for _, sa := range items {
+		names = append(names, sa.Name)
+	}
+	assert.ElementsMatch(t, []string{"sa1", "sa3"}, names)
+	assert.Equal(t, []string{"b"}, inaccessible)
+}
+
+func TestListNamespaced_NamespacesForbidden(t *testing.T) {
+	clientset := fake.NewClientset(newNamespace("a"))
+	denyServiceAccountList(clientset, "")
+	clientset.PrependReactor("list", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, "", errors.New("denied"))
+	})
+
+	_, _, err := ListNamespaced(context.Background(), clientset, "", serviceAccountLister(clientset))
+
+	require.Error(t, err)
+	assert.True(t, apierrors.IsForbidden(err))
+}
+
+func TestListNamespaced_OtherErrorIsReturned(t *testing.T) {
+	clientset := fake.NewClientset(newNamespace("a"))
+
+	_, _, err := ListNamespaced(context.Background(), clientset, "", func(ctx context.Context, ns string) ([]corev1.ServiceAccount, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	assert.EqualError(t, err, "connection refused")
+}
+
+func TestListNamespaced_FallbackOtherErrorIsReturned(t *testing.T) {
+	clientset := fake.NewClientset(newNamespace("a"), newNamespace("b"), newServiceAccount("a", "sa1"))
+	denyServiceAccountList(clientset, "")
+	clientset.PrependReactor("list", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == "b" {
+			return true, nil, apierrors.NewServiceUnavailable("etcd timeout")
+		}
+		return false, nil, nil
+	})
+
+	_, _, err := ListNamespaced(context.Background(), clientset, "", serviceAccountLister(clientset))
+
+	require.Error(t, err, "only RBAC denials make a namespace inaccessible")
+	assert.True(t, apierrors.IsServiceUnavailable(err))
+}
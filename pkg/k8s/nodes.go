@@ -12,6 +12,9 @@ import (
 	"k8s.io/client-go/rest"
 )
 
+// nodeGroupLabel names the managed node group of a node
+const nodeGroupLabel = "eks.amazonaws.com/nodegroup"
+
 func GetNodesWithConfig(restConfig *rest.Config) ([]data.NodeInfo, error) {
 	clientset, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
@@ -258,3 +261,31 @@ func getRunningPodsByNode(clientset *kubernetes.Clientset) (map[string]int, erro
 
 	return runningPodsByNode, nil
 }
+
+// NodeGroupNodes counts the nodes of a managed node group
+type NodeGroupNodes struct {
+	Total int
+	Ready int
+}
+
+// CountNodeGroupNodes counts the nodes, and how many are Ready, of each
+// managed node group by their eks.amazonaws.com/nodegroup label
+func CountNodeGroupNodes(ctx context.Context, clientset kubernetes.Interface) (map[string]NodeGroupNodes, error) {
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: nodeGroupLabel})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	counts := make(map[string]NodeGroupNodes)
+	for _, node := range nodes.Items {
+		nodeGroup := node.Labels[nodeGroupLabel]
+		count := counts[nodeGroup]
+		count.Total++
+		if getNodeConditionStatus(node, corev1.NodeReady) == string(corev1.ConditionTrue) {
+			count.Ready++
+		}
+		counts[nodeGroup] = count
+	}
+
+	return counts, nil
+}
@@ -1,10 +1,13 @@
 package k8s
 
 import (
+	"context"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestGetNodeStatus(t *testing.T) {
@@ -163,3 +166,39 @@ func TestGetNodeResourceUsed(t *testing.T) {
 		t.Fatalf("missing resource used = %q, want %q", got, "-")
 	}
 }
+
+func TestCountNodeGroupNodes(t *testing.T) {
+	node := func(name, nodeGroup string, ready corev1.ConditionStatus) *corev1.Node {
+		labels := map[string]string{}
+		if nodeGroup != "" {
+			labels[nodeGroupLabel] = nodeGroup
+		}
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+			},
+		}
+	}
+
+	clientset := fake.NewClientset(
+		node("a", "workers", corev1.ConditionTrue),
+		node("b", "workers", corev1.ConditionFalse),
+		node("c", "system", corev1.ConditionTrue),
+		node("karpenter", "", corev1.ConditionTrue),
+	)
+
+	counts, err := CountNodeGroupNodes(context.Background(), clientset)
+	if err != nil {
+		t.Fatalf("CountNodeGroupNodes() error = %v", err)
+	}
+	if len(counts) != 2 {
+		t.Fatalf("counted %d node groups, want 2: %v", len(counts), counts)
+	}
+	if got := counts["workers"]; got != (NodeGroupNodes{Total: 2, Ready: 1}) {
+		t.Fatalf("workers = %+v, want 2 nodes, 1 ready", got)
+	}
+	if got := counts["system"]; got != (NodeGroupNodes{Total: 1, Ready: 1}) {
+		t.Fatalf("system = %+v, want 1 node, 1 ready", got)
+	}
+}
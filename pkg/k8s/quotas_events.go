@@ -9,73 +9,24 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 )
 
-func GetResourceQuotas(ctx context.Context, namespace string) ([]corev1.ResourceQuota, error) {
+// GetResourceQuotas returns the ResourceQuotas in the given namespace (all
+// namespaces when empty), along with any namespaces that could not be listed.
+func GetResourceQuotas(ctx context.Context, namespace string) ([]corev1.ResourceQuota, []string, error) {
 	config, err := clientcmd.BuildConfigFromFlags("", clientcmd.RecommendedHomeFile)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	var quotas []corev1.ResourceQuota
-
-	if namespace == "" {
-		// Get quotas from all namespaces
-		namespaceList, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	return ListNamespaced(ctx, clientset, namespace, func(ctx context.Context, ns string) ([]corev1.ResourceQuota, error) {
+		quotaList, err := clientset.CoreV1().ResourceQuotas(ns).List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return nil, err
 		}
-
-		for _, ns := range namespaceList.Items {
-			quotaList, err := clientset.CoreV1().ResourceQuotas(ns.Name).List(ctx, metav1.ListOptions{})
-			if err != nil {
-				continue
-			}
-			quotas = append(quotas, quotaList.Items...)
-		}
-	} else {
-		// Get quotas from specific namespace
-		quotaList, err := clientset.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{})
-		if err != nil {
-			return nil, err
-		}
-		quotas = quotaList.Items
-	}
-
-	return quotas, nil
-}
-
-func GetEvents(ctx context.Context, namespace string) ([]corev1.Event, error) {
-	config, err := clientcmd.BuildConfigFromFlags("", clientcmd.RecommendedHomeFile)
-	if err != nil {
-		return nil, err
-	}
-
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return nil, err
-	}
-
-	var events []corev1.Event
-
-	if namespace == "" {
-		// Get events from all namespaces
-		eventList, err := clientset.CoreV1().Events("").List(ctx, metav1.ListOptions{})
-		if err != nil {
-			return nil, err
-		}
-		events = eventList.Items
-	} else {
-		// Get events from specific namespace
-		eventList, err := clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
-		if err != nil {
-			return nil, err
-		}
-		events = eventList.Items
-	}
-
-	return events, nil
+		return quotaList.Items, nil
+	})
 }
@@ -2,11 +2,13 @@ package k8s
 
 import (
 	"context"
-	"log"
+	"fmt"
 	"os"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
@@ -22,8 +24,46 @@ type K8Sstats struct {
 	PodsNotRunning        int
 	NamespaceCount        int
 	PodsWithRestartsCount int
+
+	// Workloads by kind
+	Deployments  int
+	StatefulSets int
+	DaemonSets   int
+	Jobs         int
+	CronJobs     int
+
+	// Pods by QoS class
+	PodsGuaranteed int
+	PodsBurstable  int
+	PodsBestEffort int
+
+	// Pods with a container missing CPU or memory requests / limits
+	PodsWithoutRequests int
+	PodsWithoutLimits   int
+
+	// Scheduled pods by compute type
+	FargatePods int
+	EC2Pods     int
+
+	PVCCount         int
+	PVCCapacityBytes int64
+
+	// Services by type
+	ServicesClusterIP    int
+	ServicesNodePort     int
+	ServicesLoadBalancer int
+	ServicesExternalName int
+
+	// Errors lists what could not be collected
+	Errors []string
 }
 
+// fargateComputeTypeLabel marks Fargate nodes
+const fargateComputeTypeLabel = "eks.amazonaws.com/compute-type"
+
+// GetK8sStats collects the stats of the cluster of the current kubeconfig
+// context. Only failing to build a client returns an error; resources that
+// cannot be listed are recorded in the stats Errors.
 func GetK8sStats(awsRegion, region, clusterName, arn, version string) (*K8Sstats, error) {
 	stats := &K8Sstats{
 		AWSProfile:  awsRegion,
@@ -41,60 +81,251 @@ func GetK8sStats(awsRegion, region, clusterName, arn, version string) (*K8Sstats
 	// Load config
 	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
 	if err != nil {
-		log.Fatalf("Error loading kubeconfig from %s: %v", kubeconfig, err)
+		return nil, fmt.Errorf("error loading kubeconfig from %s: %w", kubeconfig, err)
 	}
 
+	return collectK8sStatsWithConfig(config, stats)
+}
+
+// GetK8sStatsWithConfig collects the stats of the cluster behind restConfig,
+// without reading the kubeconfig
+func GetK8sStatsWithConfig(restConfig *rest.Config, awsProfile, region, clusterName, arn, version string) (*K8Sstats, error) {
+	return collectK8sStatsWithConfig(restConfig, &K8Sstats{
+		AWSProfile:  awsProfile,
+		Region:      region,
+		ClusterName: clusterName,
+		Arn:         arn,
+		Version:     version,
+	})
+}
+
+func collectK8sStatsWithConfig(restConfig *rest.Config, stats *K8Sstats) (*K8Sstats, error) {
 	// Create Kubernetes client
-	clientset, err := kubernetes.NewForConfig(config)
+	clientset, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
-		log.Fatalf("Error creating clientset: %v", err)
+		return nil, fmt.Errorf("error creating clientset: %w", err)
+	}
+
+	CollectK8sStats(context.TODO(), clientset, stats)
+	return stats, nil
+}
+
+// CollectK8sStats fills stats from the cluster behind clientset. Every
+// resource is listed independently, failures are appended to stats.Errors.
+func CollectK8sStats(ctx context.Context, clientset kubernetes.Interface, stats *K8Sstats) {
+	recordErr := func(resource string, err error) {
+		stats.Errors = append(stats.Errors, fmt.Sprintf("%s: %v", resource, err))
+	}
+
+	// Nodes
+	fargateNodes := make(map[string]bool)
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		recordErr("nodes", err)
+	} else {
+		stats.NodeCount = len(nodes.Items)
+		for _, node := range nodes.Items {
+			if node.Labels[fargateComputeTypeLabel] == "fargate" {
+				fargateNodes[node.Name] = true
+			}
+			for _, condition := range node.Status.Conditions {
+				if condition.Type == corev1.NodeReady && condition.Status != corev1.ConditionTrue {
+					stats.NodesNotReady++
+				}
+			}
+		}
 	}
 
 	// Pods
-	pods, err := clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return nil, err
+		recordErr("pods", err)
+	} else {
+		for _, pod := range pods.Items {
+			addPodStats(stats, pod, fargateNodes)
+		}
 	}
 
-	for _, pod := range pods.Items {
-		stats.PodCount++
-		if pod.Status.Phase != "Running" {
-			stats.PodsNotRunning++
+	// Namespaces
+	namespaces, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		recordErr("namespaces", err)
+	} else {
+		stats.NamespaceCount = len(namespaces.Items)
+	}
+
+	// Workloads
+	if list, err := clientset.AppsV1().Deployments("").List(ctx, metav1.ListOptions{}); err != nil {
+		recordErr("deployments", err)
+	} else {
+		stats.Deployments = len(list.Items)
+	}
+	if list, err := clientset.AppsV1().StatefulSets("").List(ctx, metav1.ListOptions{}); err != nil {
+		recordErr("statefulsets", err)
+	} else {
+		stats.StatefulSets = len(list.Items)
+	}
+	if list, err := clientset.AppsV1().DaemonSets("").List(ctx, metav1.ListOptions{}); err != nil {
+		recordErr("daemonsets", err)
+	} else {
+		stats.DaemonSets = len(list.Items)
+	}
+	if list, err := clientset.BatchV1().Jobs("").List(ctx, metav1.ListOptions{}); err != nil {
+		recordErr("jobs", err)
+	} else {
+		stats.Jobs = len(list.Items)
+	}
+	if list, err := clientset.BatchV1().CronJobs("").List(ctx, metav1.ListOptions{}); err != nil {
+		recordErr("cronjobs", err)
+	} else {
+		stats.CronJobs = len(list.Items)
+	}
+
+	// PersistentVolumeClaims
+	if list, err := clientset.CoreV1().PersistentVolumeClaims("").List(ctx, metav1.ListOptions{}); err != nil {
+		recordErr("persistentvolumeclaims", err)
+	} else {
+		stats.PVCCount = len(list.Items)
+		for _, pvc := range list.Items {
+			// Bound claims report their actual size, others what they request
+			capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]
+			if !ok {
+				capacity = pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+			}
+			stats.PVCCapacityBytes += capacity.Value()
 		}
+	}
 
-		// Container restarts
-		for _, container := range pod.Status.ContainerStatuses {
-			if container.RestartCount > 0 {
-				stats.PodsWithRestartsCount++
-				break
+	// Services
+	if list, err := clientset.CoreV1().Services("").List(ctx, metav1.ListOptions{}); err != nil {
+		recordErr("services", err)
+	} else {
+		for _, svc := range list.Items {
+			switch svc.Spec.Type {
+			case corev1.ServiceTypeNodePort:
+				stats.ServicesNodePort++
+			case corev1.ServiceTypeLoadBalancer:
+				stats.ServicesLoadBalancer++
+			case corev1.ServiceTypeExternalName:
+				stats.ServicesExternalName++
+			default:
+				stats.ServicesClusterIP++
 			}
 		}
 	}
+}
 
-	// Nodes
-	nodes, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, err
+func addPodStats(stats *K8Sstats, pod corev1.Pod, fargateNodes map[string]bool) {
+	stats.PodCount++
+	if pod.Status.Phase != corev1.PodRunning {
+		stats.PodsNotRunning++
 	}
 
-	stats.NodeCount = len(nodes.Items)
+	// Container restarts
+	for _, container := range pod.Status.ContainerStatuses {
+		if container.RestartCount > 0 {
+			stats.PodsWithRestartsCount++
+			break
+		}
+	}
 
-	for _, node := range nodes.Items {
-		if node.Status.Conditions != nil {
-			for _, condition := range node.Status.Conditions {
-				if condition.Type == "Ready" && condition.Status != "True" {
-					stats.NodesNotReady++
-				}
+	switch podQOSClass(pod) {
+	case corev1.PodQOSGuaranteed:
+		stats.PodsGuaranteed++
+	case corev1.PodQOSBurstable:
+		stats.PodsBurstable++
+	default:
+		stats.PodsBestEffort++
+	}
+
+	missingRequests, missingLimits := false, false
+	for _, container := range pod.Spec.Containers {
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			if _, ok := container.Resources.Requests[name]; !ok {
+				missingRequests = true
+			}
+			if _, ok := container.Resources.Limits[name]; !ok {
+				missingLimits = true
 			}
 		}
 	}
+	if missingRequests {
+		stats.PodsWithoutRequests++
+	}
+	if missingLimits {
+		stats.PodsWithoutLimits++
+	}
 
-	// Namespaces
-	namespaces, err := clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, err
+	if pod.Spec.NodeName != "" {
+		if fargateNodes[pod.Spec.NodeName] {
+			stats.FargatePods++
+		} else {
+			stats.EC2Pods++
+		}
 	}
-	stats.NamespaceCount = len(namespaces.Items)
+}
 
-	return stats, nil
+// podQOSClass returns the QoS class reported by the kubelet or, for pods that
+// do not have one yet, derives it from the container resources
+func podQOSClass(pod corev1.Pod) corev1.PodQOSClass {
+	if pod.Status.QOSClass != "" {
+		return pod.Status.QOSClass
+	}
+
+	hasResources := false
+	guaranteed := true
+	for _, container := range pod.Spec.Containers {
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			request, hasRequest := container.Resources.Requests[name]
+			limit, hasLimit := container.Resources.Limits[name]
+			if hasRequest || hasLimit {
+				hasResources = true
+			}
+			// Requests default to the limits when only limits are set
+			if !hasLimit || (hasRequest && request.Cmp(limit) != 0) {
+				guaranteed = false
+			}
+		}
+	}
+
+	switch {
+	case !hasResources:
+		return corev1.PodQOSBestEffort
+	case guaranteed:
+		return corev1.PodQOSGuaranteed
+	default:
+		return corev1.PodQOSBurstable
+	}
+}
+
+// SumK8sStats adds up the stats of several clusters into a totals row
+func SumK8sStats(statsList []K8Sstats) K8Sstats {
+	total := K8Sstats{AWSProfile: "-", Region: "-", ClusterName: "TOTAL", Arn: "-", Version: "-"}
+	for _, s := range statsList {
+		total.PodCount += s.PodCount
+		total.NodeCount += s.NodeCount
+		total.NodesNotReady += s.NodesNotReady
+		total.PodsNotRunning += s.PodsNotRunning
+		total.NamespaceCount += s.NamespaceCount
+		total.PodsWithRestartsCount += s.PodsWithRestartsCount
+		total.Deployments += s.Deployments
+		total.StatefulSets += s.StatefulSets
+		total.DaemonSets += s.DaemonSets
+		total.Jobs += s.Jobs
+		total.CronJobs += s.CronJobs
+		total.PodsGuaranteed += s.PodsGuaranteed
+		total.PodsBurstable += s.PodsBurstable
+		total.PodsBestEffort += s.PodsBestEffort
+		total.PodsWithoutRequests += s.PodsWithoutRequests
+		total.PodsWithoutLimits += s.PodsWithoutLimits
+		total.FargatePods += s.FargatePods
+		total.EC2Pods += s.EC2Pods
+		total.PVCCount += s.PVCCount
+		total.PVCCapacityBytes += s.PVCCapacityBytes
+		total.ServicesClusterIP += s.ServicesClusterIP
+		total.ServicesNodePort += s.ServicesNodePort
+		total.ServicesLoadBalancer += s.ServicesLoadBalancer
+		total.ServicesExternalName += s.ServicesExternalName
+	}
+	return total
 }
@@ -0,0 +1,109 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func resources(cpu, memory string) corev1.ResourceList {
+	list := corev1.ResourceList{}
+	if cpu != "" {
+		list[corev1.ResourceCPU] = resource.MustParse(cpu)
+	}
+	if memory != "" {
+		list[corev1.ResourceMemory] = resource.MustParse(memory)
+	}
+	return list
+}
+
+func podWithResources(name, node string, requests, limits corev1.ResourceList) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: corev1.PodSpec{
+			NodeName:   node,
+			Containers: []corev1.Container{{Name: "app", Resources: corev1.ResourceRequirements{Requests: requests, Limits: limits}}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func TestCollectK8sStats(t *testing.T) {
+	clientset := fake.NewClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "ip-10-0-0-1"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "fargate-ip-10-0-0-2", Labels: map[string]string{"eks.amazonaws.com/compute-type": "fargate"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		podWithResources("guaranteed", "ip-10-0-0-1", resources("1", "1Gi"), resources("1", "1Gi")),
+		podWithResources("burstable", "fargate-ip-10-0-0-2", resources("100m", ""), nil),
+		podWithResources("besteffort", "", nil, nil),
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api"}},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "bound"},
+			Status:     corev1.PersistentVolumeClaimStatus{Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pending"},
+			Spec: corev1.PersistentVolumeClaimSpec{Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("5Gi")},
+			}},
+		},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "kubernetes"}, Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "lb"}, Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer}},
+	)
+	clientset.PrependReactor("list", "cronjobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("forbidden")
+	})
+
+	stats := &K8Sstats{ClusterName: "test"}
+	CollectK8sStats(context.Background(), clientset, stats)
+
+	assert.Equal(t, 2, stats.NodeCount)
+	assert.Equal(t, 3, stats.PodCount)
+	assert.Equal(t, 1, stats.Deployments)
+	assert.Equal(t, 1, stats.PodsGuaranteed)
+	assert.Equal(t, 1, stats.PodsBurstable)
+	assert.Equal(t, 1, stats.PodsBestEffort)
+	assert.Equal(t, 2, stats.PodsWithoutRequests)
+	assert.Equal(t, 2, stats.PodsWithoutLimits)
+	assert.Equal(t, 1, stats.FargatePods)
+	assert.Equal(t, 1, stats.EC2Pods, "unscheduled pods are neither Fargate nor EC2")
+	assert.Equal(t, 2, stats.PVCCount)
+	assert.Equal(t, int64(15*1024*1024*1024), stats.PVCCapacityBytes)
+	assert.Equal(t, 1, stats.ServicesClusterIP)
+	assert.Equal(t, 1, stats.ServicesLoadBalancer)
+	require.Len(t, stats.Errors, 1, "a failing list does not stop the collection")
+	assert.Contains(t, stats.Errors[0], "cronjobs")
+}
+
+func TestPodQOSClass(t *testing.T) {
+	assert.Equal(t, corev1.PodQOSGuaranteed, podQOSClass(*podWithResources("a", "", nil, resources("1", "1Gi"))), "requests default to limits")
+	assert.Equal(t, corev1.PodQOSBurstable, podQOSClass(*podWithResources("b", "", resources("1", "1Gi"), resources("2", "1Gi"))))
+	assert.Equal(t, corev1.PodQOSBestEffort, podQOSClass(*podWithResources("c", "", nil, nil)))
+
+	reported := podWithResources("d", "", nil, nil)
+	reported.Status.QOSClass = corev1.PodQOSBurstable
+	assert.Equal(t, corev1.PodQOSBurstable, podQOSClass(*reported), "the kubelet's class wins")
+}
+
+func TestSumK8sStats(t *testing.T) {
+	total := SumK8sStats([]K8Sstats{
+		{ClusterName: "a", PodCount: 3, FargatePods: 1, PVCCapacityBytes: 10},
+		{ClusterName: "b", PodCount: 2, EC2Pods: 2, PVCCapacityBytes: 5, Errors: []string{"pods: forbidden"}},
+	})
+
+	assert.Equal(t, "TOTAL", total.ClusterName)
+	assert.Equal(t, 5, total.PodCount)
+	assert.Equal(t, 1, total.FargatePods)
+	assert.Equal(t, 2, total.EC2Pods)
+	assert.Equal(t, int64(15), total.PVCCapacityBytes)
+}
@@ -0,0 +1,250 @@
+package karpenter
+
+import (
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Labels and annotations of Karpenter releases before v0.32
+const (
+	provisionerNameLabel       = "karpenter.sh/provisioner-name"
+	instanceAMIIDLabel         = "karpenter.k8s.aws/instance-ami-id"
+	legacyDoNotEvictAnnotation = "karpenter.sh/do-not-evict"
+	legacyDisruptionTaintKey   = "karpenter.sh/disruption"
+)
+
+// convertV1beta1NodePool moves the fields v1 relocated or renamed:
+// expireAfter moved to the template and WhenUnderutilized became
+// WhenEmptyOrUnderutilized
+func convertV1beta1NodePool(np *unstructured.Unstructured) {
+	np.SetAPIVersion(nodePoolGVR.GroupVersion().String())
+
+	convertNodeClassRef(np.Object, "spec", "template", "spec", "nodeClassRef")
+
+	if policy, _, _ := unstructured.NestedString(np.Object, "spec", "disruption", "consolidationPolicy"); policy == "WhenUnderutilized" {
+		_ = unstructured.SetNestedField(np.Object, "WhenEmptyOrUnderutilized", "spec", "disruption", "consolidationPolicy")
+	}
+	if expireAfter, found, _ := unstructured.NestedString(np.Object, "spec", "disruption", "expireAfter"); found {
+		_ = unstructured.SetNestedField(np.Object, expireAfter, "spec", "template", "spec", "expireAfter")
+		unstructured.RemoveNestedField(np.Object, "spec", "disruption", "expireAfter")
+	}
+}
+
+func convertV1beta1NodeClaim(nc *unstructured.Unstructured) {
+	nc.SetAPIVersion(nodeClaimGVR.GroupVersion().String())
+	convertNodeClassRef(nc.Object, "spec", "nodeClassRef")
+}
+
+// convertV1beta1NodeClass turns an amiFamily without amiSelectorTerms, which
+// meant the latest AMI of the family, into the equivalent v1 alias
+func convertV1beta1NodeClass(nc *unstructured.Unstructured) {
+	nc.SetAPIVersion(ec2NodeClassGVR.GroupVersion().String())
+	setAMIFamilyAlias(nc.Object)
+}
+
+// convertProvisioner builds the v1 NodePool equivalent to a v1alpha5
+// Provisioner
+func convertProvisioner(p *unstructured.Unstructured) {
+	spec, _, _ := unstructured.NestedMap(p.Object, "spec")
+	status, _, _ := unstructured.NestedMap(p.Object, "status")
+
+	templateSpec := map[string]interface{}{}
+	for _, field := range []string{"requirements", "taints", "startupTaints", "kubelet"} {
+		if value, ok := spec[field]; ok {
+			templateSpec[field] = value
+		}
+	}
+	if providerRef, ok := spec["providerRef"].(map[string]interface{}); ok {
+		templateSpec["nodeClassRef"] = nodeClassRefFrom(providerRef, "AWSNodeTemplate")
+	}
+	if ttl := quantityString(spec["ttlSecondsUntilExpired"]); ttl != "" {
+		templateSpec["expireAfter"] = ttl + "s"
+	}
+
+	templateMetadata := map[string]interface{}{}
+	for _, field := range []string{"labels", "annotations"} {
+		if value, ok := spec[field]; ok {
+			templateMetadata[field] = value
+		}
+	}
+
+	// Provisioners replaced nodes one at a time, there were no budgets
+	disruption := map[string]interface{}{
+		"budgets": []interface{}{map[string]interface{}{"nodes": "1"}},
+	}
+	if enabled, _, _ := unstructured.NestedBool(spec, "consolidation", "enabled"); enabled {
+		disruption["consolidationPolicy"] = "WhenEmptyOrUnderutilized"
+	} else if ttl := quantityString(spec["ttlSecondsAfterEmpty"]); ttl != "" {
+		disruption["consolidationPolicy"] = "WhenEmpty"
+		disruption["consolidateAfter"] = ttl + "s"
+	}
+
+	nodePoolSpec := map[string]interface{}{
+		"template": map[string]interface{}{
+			"metadata": templateMetadata,
+			"spec":     templateSpec,
+		},
+		"disruption": disruption,
+	}
+	if limits, ok, _ := unstructured.NestedMap(spec, "limits", "resources"); ok {
+		nodePoolSpec["limits"] = limits
+	}
+	if weight, ok := spec["weight"]; ok {
+		nodePoolSpec["weight"] = weight
+	}
+
+	p.Object["spec"] = nodePoolSpec
+	if status != nil {
+		p.Object["status"] = status
+	}
+	p.SetAPIVersion(nodePoolGVR.GroupVersion().String())
+	p.SetKind("NodePool")
+}
+
+// convertMachine builds the v1 NodeClaim equivalent to a v1alpha5 Machine:
+// the Provisioner label names the NodePool, conditions lose their Machine
+// prefix and the AMI comes from the node labels
+func convertMachine(m *unstructured.Unstructured) {
+	labels := m.GetLabels()
+	if provisioner := labels[provisionerNameLabel]; provisioner != "" && labels[nodePoolLabel] == "" {
+		labels[nodePoolLabel] = provisioner
+		m.SetLabels(labels)
+	}
+
+	if templateRef, ok, _ := unstructured.NestedMap(m.Object, "spec", "machineTemplateRef"); ok {
+		_ = unstructured.SetNestedMap(m.Object, nodeClassRefFrom(templateRef, "AWSNodeTemplate"), "spec", "nodeClassRef")
+		unstructured.RemoveNestedField(m.Object, "spec", "machineTemplateRef")
+	}
+
+	if conditions, ok, _ := unstructured.NestedSlice(m.Object, "status", "conditions"); ok {
+		for _, c := range conditions {
+			if condition, ok := c.(map[string]interface{}); ok {
+				conditionType, _ := condition["type"].(string)
+				condition["type"] = strings.TrimPrefix(conditionType, "Machine")
+			}
+		}
+		_ = unstructured.SetNestedSlice(m.Object, conditions, "status", "conditions")
+	}
+
+	if imageID, _, _ := unstructured.NestedString(m.Object, "status", "imageID"); imageID == "" && labels[instanceAMIIDLabel] != "" {
+		_ = unstructured.SetNestedField(m.Object, labels[instanceAMIIDLabel], "status", "imageID")
+	}
+
+	m.SetAPIVersion(nodeClaimGVR.GroupVersion().String())
+	m.SetKind("NodeClaim")
+}
+
+// convertAWSNodeTemplate builds the v1 EC2NodeClass equivalent to a
+// v1alpha1 AWSNodeTemplate, turning its selector maps into selector terms
+func convertAWSNodeTemplate(t *unstructured.Unstructured) {
+	spec, _, _ := unstructured.NestedMap(t.Object, "spec")
+
+	for from, to := range map[string]string{
+		"amiSelector":           "amiSelectorTerms",
+		"subnetSelector":        "subnetSelectorTerms",
+		"securityGroupSelector": "securityGroupSelectorTerms",
+	} {
+		if selector, ok := spec[from].(map[string]interface{}); ok {
+			spec[to] = selectorMapTerms(selector)
+			delete(spec, from)
+		}
+	}
+
+	t.Object["spec"] = spec
+	setAMIFamilyAlias(t.Object)
+
+	t.SetAPIVersion(ec2NodeClassGVR.GroupVersion().String())
+	t.SetKind("EC2NodeClass")
+}
+
+// selectorMapTerms converts a v1alpha1 selector map into selector terms.
+// The aws::ids (or aws-ids) key lists ids, each one a term of its own, and
+// aws::name and aws::owners select AMIs by name and owner; any other key is
+// a tag.
+func selectorMapTerms(selector map[string]interface{}) []interface{} {
+	terms := []interface{}{}
+
+	keys := make([]string, 0, len(selector))
+	for key := range selector {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	term := map[string]interface{}{}
+	tags := map[string]interface{}{}
+	for _, key := range keys {
+		value, _ := selector[key].(string)
+		switch key {
+		case "aws::ids", "aws-ids":
+			for _, id := range strings.Split(value, ",") {
+				if id = strings.TrimSpace(id); id != "" {
+					terms = append(terms, map[string]interface{}{"id": id})
+				}
+			}
+		case "aws::name":
+			term["name"] = value
+		case "aws::owners":
+			term["owner"] = value
+		default:
+			tags[key] = value
+		}
+	}
+	if len(tags) > 0 {
+		term["tags"] = tags
+	}
+	if len(term) > 0 {
+		terms = append(terms, term)
+	}
+
+	return terms
+}
+
+// setAMIFamilyAlias selects the latest AMI of the amiFamily, as older APIs
+// did when no AMI selector was set, through the v1 alias
+func setAMIFamilyAlias(nodeClass map[string]interface{}) {
+	terms, _, _ := unstructured.NestedSlice(nodeClass, "spec", "amiSelectorTerms")
+	family, _, _ := unstructured.NestedString(nodeClass, "spec", "amiFamily")
+	if len(terms) > 0 || family == "" || family == "Custom" {
+		return
+	}
+	_ = unstructured.SetNestedSlice(nodeClass, []interface{}{
+		map[string]interface{}{"alias": strings.ToLower(family) + "@latest"},
+	}, "spec", "amiSelectorTerms")
+}
+
+// convertNodeClassRef replaces the apiVersion of a v1beta1 nodeClassRef with
+// the group v1 uses
+func convertNodeClassRef(obj map[string]interface{}, fields ...string) {
+	ref, ok, _ := unstructured.NestedMap(obj, fields...)
+	if !ok {
+		return
+	}
+	_ = unstructured.SetNestedMap(obj, nodeClassRefFrom(ref, "EC2NodeClass"), fields...)
+}
+
+// nodeClassRefFrom builds a v1 nodeClassRef from a reference carrying an
+// apiVersion, like v1beta1 nodeClassRefs and v1alpha5 providerRefs
+func nodeClassRefFrom(ref map[string]interface{}, defaultKind string) map[string]interface{} {
+	result := map[string]interface{}{
+		"group": ec2NodeClassGVR.Group,
+		"kind":  defaultKind,
+	}
+	if apiVersion, _ := ref["apiVersion"].(string); apiVersion != "" {
+		if gv, err := schema.ParseGroupVersion(apiVersion); err == nil {
+			result["group"] = gv.Group
+		}
+	}
+	if group, _ := ref["group"].(string); group != "" {
+		result["group"] = group
+	}
+	if kind, _ := ref["kind"].(string); kind != "" {
+		result["kind"] = kind
+	}
+	if name, _ := ref["name"].(string); name != "" {
+		result["name"] = name
+	}
+	return result
+}
@@ -1,42 +1,182 @@
 package karpenter
 
 import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
 	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/jordiprats/kubectl-eks/pkg/ec2"
 )
 
-func GetAMIUsage(profile, region, clusterName, eksVersion string) ([]data.KarpenterAMIUsageInfo, error) {
+// GetAMIUsage returns the AMIs in use per NodePool. With describe set, the
+// AMIs are described on EC2 and compared with the newest AMI selected by the
+// EC2NodeClass for the cluster version; AMIs older than maxAgeDays are
+// flagged as outdated.
+func GetAMIUsage(profile, region, clusterName, eksVersion string, describe bool, maxAgeDays int) ([]data.KarpenterAMIUsageInfo, error) {
 	// Get NodeClaims to find current AMIs
 	nodeClaims, err := GetNodeClaims(profile, region, clusterName)
 	if err != nil {
 		return nil, err
 	}
 
-	// Build map of NodePool -> AMIs in use
-	nodePoolAMIs := make(map[string]map[string]int)
-	for _, nc := range nodeClaims {
-		if nc.NodePoolName != "" && nc.AMI != "" {
-			if nodePoolAMIs[nc.NodePoolName] == nil {
-				nodePoolAMIs[nc.NodePoolName] = make(map[string]int)
+	result := groupAMIUsage(profile, region, clusterName, nodeClaims)
+	if !describe {
+		return result, nil
+	}
+
+	selected, err := getNodeClassAMIs()
+	if err != nil {
+		return nil, err
+	}
+
+	// Every AMI in use or selected is described in a single call
+	seen := make(map[string]bool)
+	ids := []string{}
+	for _, usage := range result {
+		if !seen[usage.CurrentAMI] {
+			seen[usage.CurrentAMI] = true
+			ids = append(ids, usage.CurrentAMI)
+		}
+	}
+	for _, amis := range selected {
+		for _, ami := range amis {
+			if !seen[ami] {
+				seen[ami] = true
+				ids = append(ids, ami)
 			}
-			nodePoolAMIs[nc.NodePoolName][nc.AMI]++
 		}
 	}
 
-	var result []data.KarpenterAMIUsageInfo
+	images, err := ec2.DescribeAMIs(profile, region, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	enrichAMIUsage(result, selected, images, eksVersion, maxAgeDays, time.Now())
 
-	for nodePoolName, amis := range nodePoolAMIs {
-		for ami, count := range amis {
-			info := data.KarpenterAMIUsageInfo{
-				Profile:      profile,
-				Region:       region,
-				ClusterName:  clusterName,
-				NodePoolName: nodePoolName,
-				CurrentAMI:   ami,
-				NodeCount:    count,
+	return result, nil
+}
+
+// groupAMIUsage counts the NodeClaims running each AMI per NodePool
+func groupAMIUsage(profile, region, clusterName string, nodeClaims []data.KarpenterNodeClaimInfo) []data.KarpenterAMIUsageInfo {
+	type usageKey struct {
+		nodePool  string
+		nodeClass string
+		ami       string
+	}
+
+	usage := make(map[usageKey]*data.KarpenterAMIUsageInfo)
+	for _, nc := range nodeClaims {
+		if nc.NodePoolName == "" || nc.AMI == "" {
+			continue
+		}
+		key := usageKey{nc.NodePoolName, nc.NodeClassName, nc.AMI}
+		info, ok := usage[key]
+		if !ok {
+			info = &data.KarpenterAMIUsageInfo{
+				Profile:       profile,
+				Region:        region,
+				ClusterName:   clusterName,
+				NodePoolName:  nc.NodePoolName,
+				NodeClassName: nc.NodeClassName,
+				CurrentAMI:    nc.AMI,
 			}
-			result = append(result, info)
+			usage[key] = info
 		}
+		info.NodeCount++
+		if nc.NodeName != "" {
+			info.NodeNames = append(info.NodeNames, nc.NodeName)
+		}
+	}
+
+	result := []data.KarpenterAMIUsageInfo{}
+	for _, info := range usage {
+		sort.Strings(info.NodeNames)
+		result = append(result, *info)
+	}
+
+	return result
+}
+
+// getNodeClassAMIs returns the AMIs resolved by the amiSelectorTerms of each
+// EC2NodeClass
+func getNodeClassAMIs() (map[string][]string, error) {
+	dynamicClient, _, api, err := newClients()
+	if err != nil {
+		return nil, err
+	}
+
+	nodeClasses, err := listNodeClasses(context.TODO(), dynamicClient, api)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]string)
+	for i := range nodeClasses {
+		result[nodeClasses[i].GetName()] = statusIDs(&nodeClasses[i], "amis")
 	}
 
 	return result, nil
 }
+
+// enrichAMIUsage fills in the AMI details, its age and the latest AMI the
+// EC2NodeClass selects for the same architecture
+func enrichAMIUsage(usage []data.KarpenterAMIUsageInfo, selected map[string][]string, images map[string]data.AMIInfo, eksVersion string, maxAgeDays int, now time.Time) {
+	for i := range usage {
+		image, ok := images[usage[i].CurrentAMI]
+		if !ok {
+			continue
+		}
+
+		usage[i].AMIName = image.Name
+		usage[i].Architecture = image.Architecture
+		usage[i].DeprecationTime = image.DeprecationTime
+		if created, err := time.Parse(time.RFC3339, image.CreationDate); err == nil {
+			usage[i].CreationDate = created
+			usage[i].AgeDays = int(now.Sub(created).Hours() / 24)
+			usage[i].Outdated = maxAgeDays > 0 && usage[i].AgeDays > maxAgeDays
+		}
+
+		usage[i].LatestAMI = latestAMI(selected[usage[i].NodeClassName], images, image.Architecture, eksVersion)
+	}
+}
+
+// latestAMI picks the newest of the candidate AMIs for the given
+// architecture. When some of them are built for the cluster version (EKS
+// optimized AMI names carry it, e.g. amazon-eks-node-al2023-x86_64-standard-1.30-v20240605)
+// only those are considered.
+func latestAMI(candidates []string, images map[string]data.AMIInfo, architecture, eksVersion string) string {
+	var matching []data.AMIInfo
+	for _, id := range candidates {
+		image, ok := images[id]
+		if ok && image.Architecture == architecture {
+			matching = append(matching, image)
+		}
+	}
+
+	if eksVersion != "" {
+		var forVersion []data.AMIInfo
+		for _, image := range matching {
+			if strings.Contains(image.Name, "-"+eksVersion+"-") {
+				forVersion = append(forVersion, image)
+			}
+		}
+		if len(forVersion) > 0 {
+			matching = forVersion
+		}
+	}
+
+	latest := ""
+	latestCreated := time.Time{}
+	for _, image := range matching {
+		created, _ := time.Parse(time.RFC3339, image.CreationDate)
+		if latest == "" || created.After(latestCreated) {
+			latest = image.ID
+			latestCreated = created
+		}
+	}
+
+	return latest
+}
@@ -0,0 +1,78 @@
+package karpenter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupAMIUsage(t *testing.T) {
+	usage := groupAMIUsage("prod", "eu-west-1", "main", []data.KarpenterNodeClaimInfo{
+		{NodePoolName: "general", NodeClassName: "default", AMI: "ami-1", NodeName: "node-b"},
+		{NodePoolName: "general", NodeClassName: "default", AMI: "ami-1", NodeName: "node-a"},
+		{NodePoolName: "general", NodeClassName: "default", AMI: "ami-2"},
+		{NodePoolName: "general", NodeClassName: "default"},
+	})
+
+	require.Len(t, usage, 2)
+	byAMI := map[string]data.KarpenterAMIUsageInfo{}
+	for _, u := range usage {
+		byAMI[u.CurrentAMI] = u
+	}
+	assert.Equal(t, 2, byAMI["ami-1"].NodeCount)
+	assert.Equal(t, []string{"node-a", "node-b"}, byAMI["ami-1"].NodeNames)
+	assert.Equal(t, "default", byAMI["ami-1"].NodeClassName)
+	assert.Equal(t, 1, byAMI["ami-2"].NodeCount)
+	assert.Empty(t, byAMI["ami-2"].NodeNames, "NodeClaims without a node yet are only counted")
+}
+
+func TestEnrichAMIUsage(t *testing.T) {
+	now := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	images := map[string]data.AMIInfo{
+		"ami-old": {ID: "ami-old", Name: "amazon-eks-node-al2023-x86_64-standard-1.30-v20240501", Architecture: "x86_64", CreationDate: "2024-05-01T10:00:00.000Z", DeprecationTime: "2026-05-01T10:00:00.000Z"},
+		"ami-new": {ID: "ami-new", Name: "amazon-eks-node-al2023-x86_64-standard-1.30-v20240815", Architecture: "x86_64", CreationDate: "2024-08-15T10:00:00.000Z"},
+		// Newer, but built for another Kubernetes version
+		"ami-131": {ID: "ami-131", Name: "amazon-eks-node-al2023-x86_64-standard-1.31-v20240820", Architecture: "x86_64", CreationDate: "2024-08-20T10:00:00.000Z"},
+		"ami-arm": {ID: "ami-arm", Name: "amazon-eks-node-al2023-arm64-standard-1.30-v20240815", Architecture: "arm64", CreationDate: "2024-08-15T10:00:00.000Z"},
+	}
+	selected := map[string][]string{"default": {"ami-arm", "ami-new", "ami-131"}}
+
+	usage := []data.KarpenterAMIUsageInfo{
+		{NodeClassName: "default", CurrentAMI: "ami-old", NodeNames: []string{"node-a"}},
+		{NodeClassName: "default", CurrentAMI: "ami-arm"},
+		{NodeClassName: "missing", CurrentAMI: "ami-new"},
+		{NodeClassName: "default", CurrentAMI: "ami-deregistered"},
+	}
+
+	enrichAMIUsage(usage, selected, images, "1.30", 90, now)
+
+	assert.Equal(t, "amazon-eks-node-al2023-x86_64-standard-1.30-v20240501", usage[0].AMIName)
+	assert.Equal(t, "x86_64", usage[0].Architecture)
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), usage[0].CreationDate)
+	assert.Equal(t, "2026-05-01T10:00:00.000Z", usage[0].DeprecationTime)
+	assert.Equal(t, 122, usage[0].AgeDays)
+	assert.True(t, usage[0].Outdated)
+	assert.Equal(t, "ami-new", usage[0].LatestAMI)
+
+	assert.Equal(t, "ami-arm", usage[1].LatestAMI, "the latest AMI is picked for the same architecture")
+	assert.False(t, usage[1].Outdated)
+
+	assert.Empty(t, usage[2].LatestAMI, "no AMIs known for the EC2NodeClass")
+
+	assert.Empty(t, usage[3].AMIName)
+	assert.True(t, usage[3].CreationDate.IsZero())
+	assert.False(t, usage[3].Outdated)
+}
+
+func TestLatestAMI_NoVersionMatch(t *testing.T) {
+	images := map[string]data.AMIInfo{
+		"ami-1": {ID: "ami-1", Name: "custom-1", Architecture: "x86_64", CreationDate: "2024-01-01T00:00:00.000Z"},
+		"ami-2": {ID: "ami-2", Name: "custom-2", Architecture: "x86_64", CreationDate: "2024-02-01T00:00:00.000Z"},
+	}
+
+	assert.Equal(t, "ami-2", latestAMI([]string{"ami-1", "ami-2"}, images, "x86_64", "1.30"), "custom AMI names without the version are all considered")
+}
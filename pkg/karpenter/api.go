@@ -0,0 +1,180 @@
+package karpenter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// karpenterAPI is the set of resources served by a Karpenter API version.
+// Objects of older versions are converted to the karpenter.sh/v1 shape when
+// listed, so the rest of the package only deals with NodePools, NodeClaims
+// and EC2NodeClasses.
+type karpenterAPI struct {
+	Version     string
+	NodePools   schema.GroupVersionResource
+	NodeClaims  schema.GroupVersionResource
+	NodeClasses schema.GroupVersionResource
+}
+
+var karpenterV1 = karpenterAPI{
+	Version:     "v1",
+	NodePools:   nodePoolGVR,
+	NodeClaims:  nodeClaimGVR,
+	NodeClasses: ec2NodeClassGVR,
+}
+
+var karpenterV1beta1 = karpenterAPI{
+	Version:     "v1beta1",
+	NodePools:   schema.GroupVersionResource{Group: "karpenter.sh", Version: "v1beta1", Resource: "nodepools"},
+	NodeClaims:  schema.GroupVersionResource{Group: "karpenter.sh", Version: "v1beta1", Resource: "nodeclaims"},
+	NodeClasses: schema.GroupVersionResource{Group: "karpenter.k8s.aws", Version: "v1beta1", Resource: "ec2nodeclasses"},
+}
+
+// karpenterV1alpha5 is the API of Karpenter releases before v0.32, where
+// Provisioners, Machines and AWSNodeTemplates play the role of NodePools,
+// NodeClaims and EC2NodeClasses
+var karpenterV1alpha5 = karpenterAPI{
+	Version:     "v1alpha5",
+	NodePools:   schema.GroupVersionResource{Group: "karpenter.sh", Version: "v1alpha5", Resource: "provisioners"},
+	NodeClaims:  schema.GroupVersionResource{Group: "karpenter.sh", Version: "v1alpha5", Resource: "machines"},
+	NodeClasses: schema.GroupVersionResource{Group: "karpenter.k8s.aws", Version: "v1alpha1", Resource: "awsnodetemplates"},
+}
+
+// karpenterAPIs in order of preference; clusters being upgraded serve more
+// than one of them
+var karpenterAPIs = []karpenterAPI{karpenterV1, karpenterV1beta1, karpenterV1alpha5}
+
+var errKarpenterNotFound = errors.New("no Karpenter API found in the cluster")
+
+// discoverAPIs returns the Karpenter APIs the cluster serves, newest first
+func discoverAPIs(discoveryClient discovery.DiscoveryInterface) ([]karpenterAPI, error) {
+	var served []karpenterAPI
+	for _, api := range karpenterAPIs {
+		resources, err := discoveryClient.ServerResourcesForGroupVersion(api.NodePools.GroupVersion().String())
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover %s resources: %w", api.NodePools.GroupVersion(), err)
+		}
+		for _, resource := range resources.APIResources {
+			if resource.Name == api.NodePools.Resource {
+				served = append(served, api)
+				break
+			}
+		}
+	}
+	return served, nil
+}
+
+// discoverAPI returns the newest Karpenter API the cluster serves
+func discoverAPI(discoveryClient discovery.DiscoveryInterface) (karpenterAPI, error) {
+	served, err := discoverAPIs(discoveryClient)
+	if err != nil {
+		return karpenterAPI{}, err
+	}
+	if len(served) == 0 {
+		return karpenterAPI{}, errKarpenterNotFound
+	}
+	return served[0], nil
+}
+
+// newClients builds the clients for the current kubeconfig context and
+// discovers which Karpenter API the cluster serves
+func newClients() (dynamic.Interface, kubernetes.Interface, karpenterAPI, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", clientcmd.RecommendedHomeFile)
+	if err != nil {
+		return nil, nil, karpenterAPI{}, fmt.Errorf("failed to build kubeconfig: %w", err)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, nil, karpenterAPI{}, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, nil, karpenterAPI{}, fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	api, err := discoverAPI(clientset.Discovery())
+	if err != nil {
+		return nil, nil, karpenterAPI{}, err
+	}
+
+	return dynamicClient, clientset, api, nil
+}
+
+// listNodePools lists the NodePools, or the Provisioners of v1alpha5, in
+// the karpenter.sh/v1 shape
+func listNodePools(ctx context.Context, dynamicClient dynamic.Interface, api karpenterAPI) ([]unstructured.Unstructured, error) {
+	list, err := dynamicClient.Resource(api.NodePools).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list NodePools: %w", err)
+	}
+
+	for i := range list.Items {
+		switch api.Version {
+		case karpenterV1beta1.Version:
+			convertV1beta1NodePool(&list.Items[i])
+		case karpenterV1alpha5.Version:
+			convertProvisioner(&list.Items[i])
+		}
+	}
+
+	return list.Items, nil
+}
+
+// listNodeClaims lists the NodeClaims, or the Machines of v1alpha5, in the
+// karpenter.sh/v1 shape
+func listNodeClaims(ctx context.Context, dynamicClient dynamic.Interface, api karpenterAPI) ([]unstructured.Unstructured, error) {
+	list, err := dynamicClient.Resource(api.NodeClaims).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		// Machines only exist since v0.27, older releases track nodes alone
+		if api.Version == karpenterV1alpha5.Version && apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list NodeClaims: %w", err)
+	}
+
+	for i := range list.Items {
+		switch api.Version {
+		case karpenterV1beta1.Version:
+			convertV1beta1NodeClaim(&list.Items[i])
+		case karpenterV1alpha5.Version:
+			convertMachine(&list.Items[i])
+		}
+	}
+
+	return list.Items, nil
+}
+
+// listNodeClasses lists the EC2NodeClasses, or the AWSNodeTemplates of
+// v1alpha5, in the karpenter.k8s.aws/v1 shape
+func listNodeClasses(ctx context.Context, dynamicClient dynamic.Interface, api karpenterAPI) ([]unstructured.Unstructured, error) {
+	list, err := dynamicClient.Resource(api.NodeClasses).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list EC2NodeClasses: %w", err)
+	}
+
+	for i := range list.Items {
+		switch api.Version {
+		case karpenterV1beta1.Version:
+			convertV1beta1NodeClass(&list.Items[i])
+		case karpenterV1alpha5.Version:
+			convertAWSNodeTemplate(&list.Items[i])
+		}
+	}
+
+	return list.Items, nil
+}
@@ -0,0 +1,198 @@
+package karpenter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newFakeClientsetServing(objects []runtime.Object, apis ...karpenterAPI) *fake.Clientset {
+	clientset := fake.NewClientset(objects...)
+	discovery := clientset.Discovery().(*fakediscovery.FakeDiscovery)
+	for _, api := range apis {
+		discovery.Resources = append(discovery.Resources, &metav1.APIResourceList{
+			GroupVersion: api.NodePools.GroupVersion().String(),
+			APIResources: []metav1.APIResource{{Name: api.NodePools.Resource}, {Name: api.NodeClaims.Resource}},
+		})
+	}
+	return clientset
+}
+
+func TestDiscoverAPI(t *testing.T) {
+	api, err := discoverAPI(newFakeClientsetServing(nil, karpenterV1beta1, karpenterV1).Discovery())
+	require.NoError(t, err)
+	assert.Equal(t, "v1", api.Version, "the newest API is preferred while upgrading")
+
+	api, err = discoverAPI(newFakeClientsetServing(nil, karpenterV1alpha5).Discovery())
+	require.NoError(t, err)
+	assert.Equal(t, "provisioners", api.NodePools.Resource)
+
+	_, err = discoverAPI(newFakeClientsetServing(nil).Discovery())
+	assert.ErrorIs(t, err, errKarpenterNotFound)
+}
+
+func newLegacyDynamicClient(api karpenterAPI, objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		api.NodePools:   "NodePoolList",
+		api.NodeClaims:  "NodeClaimList",
+		api.NodeClasses: "NodeClassList",
+	}, objects...)
+}
+
+func TestListV1beta1(t *testing.T) {
+	nodePool := newKarpenterObject("karpenter.sh/v1beta1", "NodePool", "general", nil, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"nodeClassRef": map[string]interface{}{"apiVersion": "karpenter.k8s.aws/v1beta1", "kind": "EC2NodeClass", "name": "default"},
+				},
+			},
+			"disruption": map[string]interface{}{"consolidationPolicy": "WhenUnderutilized", "expireAfter": "720h"},
+		},
+	})
+	nodeClass := newKarpenterObject("karpenter.k8s.aws/v1beta1", "EC2NodeClass", "default", nil, map[string]interface{}{
+		"spec": map[string]interface{}{"amiFamily": "AL2023"},
+	})
+	dynamicClient := newLegacyDynamicClient(karpenterV1beta1, nodePool, nodeClass)
+
+	nodePools, err := listNodePools(context.Background(), dynamicClient, karpenterV1beta1)
+	require.NoError(t, err)
+	require.Len(t, nodePools, 1)
+
+	info := parseNodePools("prod", "eu-west-1", "a", nodePools, nil)[0]
+	assert.Equal(t, "default", info.NodeClassName)
+	assert.Equal(t, "WhenEmptyOrUnderutilized", info.ConsolidationMode)
+	assert.Equal(t, "720h", info.ExpireAfter)
+	group, _, _ := unstructured.NestedString(nodePools[0].Object, "spec", "template", "spec", "nodeClassRef", "group")
+	assert.Equal(t, "karpenter.k8s.aws", group)
+
+	nodeClasses, err := listNodeClasses(context.Background(), dynamicClient, karpenterV1beta1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alias=al2023@latest"}, parseNodeClass(nodeClasses[0]).AMISelectorTerms)
+}
+
+func TestListV1alpha5(t *testing.T) {
+	provisioner := newKarpenterObject("karpenter.sh/v1alpha5", "Provisioner", "default", nil, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"requirements": []interface{}{
+				map[string]interface{}{"key": "karpenter.sh/capacity-type", "operator": "In", "values": []interface{}{"spot"}},
+			},
+			"providerRef":            map[string]interface{}{"name": "default"},
+			"limits":                 map[string]interface{}{"resources": map[string]interface{}{"cpu": "100"}},
+			"ttlSecondsAfterEmpty":   int64(30),
+			"ttlSecondsUntilExpired": int64(2592000),
+			"weight":                 int64(10),
+		},
+		"status": map[string]interface{}{"resources": map[string]interface{}{"cpu": "50"}},
+	})
+	machine := newKarpenterObject("karpenter.sh/v1alpha5", "Machine", "default-abc", nil, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"machineTemplateRef": map[string]interface{}{"apiVersion": "karpenter.k8s.aws/v1alpha1", "kind": "AWSNodeTemplate", "name": "default"},
+		},
+		"status": map[string]interface{}{
+			"nodeName": "node-a",
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "MachineDrifted", "status": "True"},
+				map[string]interface{}{"type": "Ready", "status": "True"},
+			},
+		},
+	})
+	machine.SetLabels(map[string]string{provisionerNameLabel: "default", capacityTypeLabel: "spot", instanceAMIIDLabel: "ami-1"})
+	template := newKarpenterObject("karpenter.k8s.aws/v1alpha1", "AWSNodeTemplate", "default", nil, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"amiFamily":             "Bottlerocket",
+			"subnetSelector":        map[string]interface{}{"karpenter.sh/discovery": "prod"},
+			"securityGroupSelector": map[string]interface{}{"aws-ids": "sg-1, sg-2"},
+		},
+	})
+	dynamicClient := newLegacyDynamicClient(karpenterV1alpha5, provisioner, machine, template)
+
+	nodePools, err := listNodePools(context.Background(), dynamicClient, karpenterV1alpha5)
+	require.NoError(t, err)
+	nodeClaims, err := listNodeClaims(context.Background(), dynamicClient, karpenterV1alpha5)
+	require.NoError(t, err)
+	require.Len(t, nodeClaims, 1)
+
+	info := parseNodePools("prod", "eu-west-1", "a", nodePools, nodeClaims)[0]
+	assert.Equal(t, "default", info.NodeClassName)
+	assert.Equal(t, []string{"spot"}, info.CapacityTypes)
+	assert.Equal(t, "100", info.CPULimit)
+	assert.Equal(t, 50.0, info.CPUPercent)
+	assert.Equal(t, "WhenEmpty", info.ConsolidationMode)
+	assert.Equal(t, "2592000s", info.ExpireAfter)
+	assert.Equal(t, int32(10), info.Weight)
+	assert.Equal(t, map[string]int{"spot": 1}, info.NodesByCapacityType)
+
+	claim := nodeClaims[0]
+	assert.Equal(t, "default", nodePoolOf(&claim))
+	assert.True(t, conditionTrue(claim.Object, "Drifted"))
+	imageID, _, _ := unstructured.NestedString(claim.Object, "status", "imageID")
+	assert.Equal(t, "ami-1", imageID)
+	nodeClassName, _, _ := unstructured.NestedString(claim.Object, "spec", "nodeClassRef", "name")
+	assert.Equal(t, "default", nodeClassName)
+
+	nodeClasses, err := listNodeClasses(context.Background(), dynamicClient, karpenterV1alpha5)
+	require.NoError(t, err)
+	nodeClass := parseNodeClass(nodeClasses[0])
+	assert.Equal(t, []string{"alias=bottlerocket@latest"}, nodeClass.AMISelectorTerms)
+	assert.Equal(t, []string{"karpenter.sh/discovery=prod"}, nodeClass.SubnetSelectorTerms)
+	assert.Equal(t, []string{"id=sg-1", "id=sg-2"}, nodeClass.SecurityGroupSelectorTerms)
+
+	budgets := parseBudgets(nodePools[0].Object, metav1.Now().Time)
+	assert.Equal(t, "1", budgets[0].Nodes, "Provisioners disrupt one node at a time")
+}
+
+func TestListV1alpha5_NoMachines(t *testing.T) {
+	// Releases before v0.27 do not serve Machines
+	dynamicClient := newLegacyDynamicClient(karpenterV1alpha5)
+	dynamicClient.PrependReactor("list", "machines", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(karpenterV1alpha5.NodeClaims.GroupResource(), "")
+	})
+
+	nodeClaims, err := listNodeClaims(context.Background(), dynamicClient, karpenterV1alpha5)
+	require.NoError(t, err)
+	assert.Empty(t, nodeClaims)
+
+	_, err = listNodeClaims(context.Background(), dynamicClient, karpenterAPI{Version: "v1", NodeClaims: karpenterV1alpha5.NodeClaims})
+	assert.Error(t, err, "a missing resource is only expected for v1alpha5")
+}
+
+func TestAnalyzeVersion(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "karpenter", Name: "karpenter", Labels: map[string]string{"app.kubernetes.io/name": "karpenter"}},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "controller", Image: "public.ecr.aws/karpenter/controller:0.37.0@sha256:abc"},
+		}}}},
+	}
+
+	info, err := analyzeVersion(context.Background(), newFakeClientsetServing([]runtime.Object{deployment}, karpenterV1beta1, karpenterV1))
+	require.NoError(t, err)
+	assert.Equal(t, "v1", info.APIVersion)
+	assert.Equal(t, []string{"v1", "v1beta1"}, info.ServedAPIs)
+	assert.Equal(t, "nodepools/nodeclaims/ec2nodeclasses", info.Resources)
+	assert.Equal(t, "0.37.0", info.ControllerVersion)
+
+	info, err = analyzeVersion(context.Background(), newFakeClientsetServing(nil))
+	require.NoError(t, err)
+	assert.Empty(t, info.APIVersion)
+	assert.Empty(t, info.ControllerVersion)
+}
+
+func TestImageTag(t *testing.T) {
+	assert.Equal(t, "1.0.5", imageTag("public.ecr.aws/karpenter/controller:1.0.5"))
+	assert.Equal(t, "v0.31.0", imageTag("registry:5000/karpenter/controller:v0.31.0@sha256:abc"))
+	assert.Equal(t, "", imageTag("registry:5000/karpenter/controller"))
+}
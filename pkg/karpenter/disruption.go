@@ -0,0 +1,259 @@
+package karpenter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const disruptedTaintKey = "karpenter.sh/disrupted"
+
+// disruptionReasons are the reasons a budget can be limited to
+var disruptionReasons = []string{"Empty", "Drifted", "Underutilized"}
+
+// defaultBudget is the budget Karpenter applies when a NodePool has none
+var defaultBudget = data.KarpenterBudgetInfo{Nodes: "10%", Active: true}
+
+func GetDisruption(profile, region, clusterName string) ([]data.KarpenterDisruptionInfo, []data.KarpenterDisruptionNodeInfo, error) {
+	dynamicClient, clientset, api, err := newClients()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pools, nodes, err := analyzeDisruption(context.TODO(), dynamicClient, clientset, api, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range pools {
+		pools[i].Profile = profile
+		pools[i].Region = region
+		pools[i].ClusterName = clusterName
+	}
+	for i := range nodes {
+		nodes[i].Profile = profile
+		nodes[i].Region = region
+		nodes[i].ClusterName = clusterName
+	}
+
+	return pools, nodes, nil
+}
+
+// analyzeDisruption evaluates the disruption settings and budgets of every
+// NodePool at the given time and finds the NodeClaims that are being
+// disrupted, could be consolidated or cannot be drained
+func analyzeDisruption(ctx context.Context, dynamicClient dynamic.Interface, clientset kubernetes.Interface, api karpenterAPI, now time.Time) ([]data.KarpenterDisruptionInfo, []data.KarpenterDisruptionNodeInfo, error) {
+	nodePools, err := listNodePools(ctx, dynamicClient, api)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nodeClaims, err := listNodeClaims(ctx, dynamicClient, api)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nodeList, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	nodesByName := make(map[string]*corev1.Node)
+	for i := range nodeList.Items {
+		nodesByName[nodeList.Items[i].Name] = &nodeList.Items[i]
+	}
+
+	podList, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	podsByNode := make(map[string][]corev1.Pod)
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName != "" {
+			podsByNode[pod.Spec.NodeName] = append(podsByNode[pod.Spec.NodeName], pod)
+		}
+	}
+
+	pdbList, err := clientset.PolicyV1().PodDisruptionBudgets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list PodDisruptionBudgets: %w", err)
+	}
+
+	pools := make(map[string]*data.KarpenterDisruptionInfo)
+	for _, np := range nodePools {
+		info := &data.KarpenterDisruptionInfo{NodePoolName: np.GetName()}
+		info.ConsolidationPolicy, _, _ = unstructured.NestedString(np.Object, "spec", "disruption", "consolidationPolicy")
+		info.ConsolidateAfter, _, _ = unstructured.NestedString(np.Object, "spec", "disruption", "consolidateAfter")
+		info.Budgets = parseBudgets(np.Object, now)
+		pools[np.GetName()] = info
+	}
+
+	nodes := []data.KarpenterDisruptionNodeInfo{}
+	for i := range nodeClaims {
+		nc := &nodeClaims[i]
+		pool, ok := pools[nodePoolOf(nc)]
+		if !ok {
+			continue
+		}
+		pool.NodeClaims++
+
+		nodeName, _, _ := unstructured.NestedString(nc.Object, "status", "nodeName")
+		node := nodesByName[nodeName]
+
+		state := ""
+		switch {
+		case nc.GetDeletionTimestamp() != nil || hasDisruptedTaint(node):
+			state = "Disrupting"
+			pool.Disrupting++
+		case conditionTrue(nc.Object, "Consolidatable"):
+			state = "Consolidatable"
+			pool.Candidates++
+		case conditionTrue(nc.Object, "Drifted"):
+			state = "Drifted"
+		}
+
+		blockers := disruptionBlockers(nc, node, podsByNode[nodeName], pdbList.Items)
+		if len(blockers) > 0 {
+			pool.Blocked++
+		}
+
+		if state == "" && len(blockers) == 0 {
+			continue
+		}
+		if state == "" {
+			state = "-"
+		}
+		nodes = append(nodes, data.KarpenterDisruptionNodeInfo{
+			NodePoolName:  pool.NodePoolName,
+			NodeClaimName: nc.GetName(),
+			NodeName:      nodeName,
+			State:         state,
+			BlockedBy:     blockers,
+		})
+	}
+
+	result := []data.KarpenterDisruptionInfo{}
+	for _, pool := range pools {
+		pool.AllowedDisruptions = allowedDisruptions(pool.Budgets, pool.NodeClaims, pool.Disrupting)
+		result = append(result, *pool)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].NodePoolName < result[j].NodePoolName
+	})
+
+	return result, nodes, nil
+}
+
+// parseBudgets reads the disruption budgets of a NodePool and evaluates
+// whether each one is active at the given time
+func parseBudgets(nodePool map[string]interface{}, now time.Time) []data.KarpenterBudgetInfo {
+	budgets, found, _ := unstructured.NestedSlice(nodePool, "spec", "disruption", "budgets")
+	if !found {
+		return []data.KarpenterBudgetInfo{defaultBudget}
+	}
+
+	result := []data.KarpenterBudgetInfo{}
+	for _, b := range budgets {
+		budget, ok := b.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		info := data.KarpenterBudgetInfo{Active: true}
+		info.Nodes, _ = budget["nodes"].(string)
+		if info.Nodes == "" {
+			info.Nodes = defaultBudget.Nodes
+		}
+		info.Schedule, _ = budget["schedule"].(string)
+		info.Duration, _ = budget["duration"].(string)
+		if reasons, ok := budget["reasons"].([]interface{}); ok {
+			for _, r := range reasons {
+				if reason, ok := r.(string); ok {
+					info.Reasons = append(info.Reasons, reason)
+				}
+			}
+		}
+		if info.Schedule != "" {
+			info.Active = budgetActive(info.Schedule, info.Duration, now)
+		}
+		result = append(result, info)
+	}
+
+	return result
+}
+
+// budgetActive reports whether a scheduled budget applies at the given time,
+// the way Karpenter does: the schedule (in UTC) must have fired within the
+// last duration. Budgets that cannot be parsed are considered inactive.
+func budgetActive(schedule, duration string, now time.Time) bool {
+	parsed, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return false
+	}
+	window, err := time.ParseDuration(duration)
+	if err != nil {
+		return false
+	}
+	nextHit := parsed.Next(now.UTC().Add(-window))
+	return !nextHit.After(now.UTC())
+}
+
+// allowedDisruptions returns, for each disruption reason, how many more
+// nodes the most restrictive active budget allows to disrupt
+func allowedDisruptions(budgets []data.KarpenterBudgetInfo, nodeClaims, disrupting int) map[string]int {
+	allowed := make(map[string]int)
+	for _, reason := range disruptionReasons {
+		limit := nodeClaims
+		for _, budget := range budgets {
+			if !budget.Active || !budgetAppliesTo(budget, reason) {
+				continue
+			}
+			nodes := intstr.Parse(budget.Nodes)
+			value, err := intstr.GetScaledValueFromIntOrPercent(&nodes, nodeClaims, true)
+			if err != nil {
+				continue
+			}
+			limit = min(limit, value)
+		}
+		allowed[reason] = max(limit-disrupting, 0)
+	}
+	return allowed
+}
+
+func budgetAppliesTo(budget data.KarpenterBudgetInfo, reason string) bool {
+	if len(budget.Reasons) == 0 {
+		return true
+	}
+	for _, r := range budget.Reasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+func hasDisruptedTaint(node *corev1.Node) bool {
+	if node == nil {
+		return false
+	}
+	for _, taint := range node.Spec.Taints {
+		// Before v1 the taint was karpenter.sh/disruption=disrupting
+		if taint.Key == disruptedTaintKey || taint.Key == legacyDisruptionTaintKey {
+			return true
+		}
+	}
+	return false
+}
+
+func conditionTrue(obj map[string]interface{}, conditionType string) bool {
+	condition := findCondition(obj, conditionType)
+	return condition != nil && condition["status"] == "True"
+}
@@ -0,0 +1,109 @@
+package karpenter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestBudgetActive(t *testing.T) {
+	// Monday 2024-06-03
+	monday10 := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+
+	assert.True(t, budgetActive("0 9 * * 1-5", "8h", monday10))
+	assert.False(t, budgetActive("0 9 * * 1-5", "8h", monday10.Add(8*time.Hour)), "window is over at 17:00")
+	assert.False(t, budgetActive("0 9 * * 1-5", "8h", monday10.Add(-2*time.Hour)), "not started yet at 08:00")
+	assert.True(t, budgetActive("@daily", "1h", time.Date(2024, 6, 3, 0, 30, 0, 0, time.UTC)))
+	assert.False(t, budgetActive("not a schedule", "1h", monday10))
+}
+
+func TestAllowedDisruptions(t *testing.T) {
+	budgets := []data.KarpenterBudgetInfo{
+		{Nodes: "20%", Active: true},
+		{Nodes: "0", Reasons: []string{"Drifted"}, Active: true},
+		{Nodes: "0", Active: false},
+	}
+
+	allowed := allowedDisruptions(budgets, 10, 1)
+	assert.Equal(t, map[string]int{"Empty": 1, "Underutilized": 1, "Drifted": 0}, allowed)
+
+	// Percentages are rounded up
+	assert.Equal(t, 1, allowedDisruptions([]data.KarpenterBudgetInfo{defaultBudget}, 3, 0)["Empty"])
+}
+
+func TestAnalyzeDisruption(t *testing.T) {
+	now := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+
+	nodePool := newKarpenterObject("karpenter.sh/v1", "NodePool", "general", nil, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"disruption": map[string]interface{}{
+				"consolidationPolicy": "WhenEmptyOrUnderutilized",
+				"consolidateAfter":    "1m",
+				"budgets": []interface{}{
+					map[string]interface{}{"nodes": "50%"},
+					map[string]interface{}{"nodes": "0", "schedule": "0 9 * * 1-5", "duration": "8h", "reasons": []interface{}{"Underutilized"}},
+				},
+			},
+		},
+	})
+
+	claim := func(name, nodeName string, conditions ...interface{}) *unstructured.Unstructured {
+		nc := newKarpenterObject("karpenter.sh/v1", "NodeClaim", name, nil, map[string]interface{}{
+			"status": map[string]interface{}{"nodeName": nodeName, "conditions": conditions},
+		})
+		nc.SetLabels(map[string]string{nodePoolLabel: "general"})
+		return nc
+	}
+
+	disrupting := claim("general-a", "node-a")
+	consolidatable := claim("general-b", "node-b", map[string]interface{}{"type": "Consolidatable", "status": "True"})
+	blocked := claim("general-c", "node-c")
+	idle := claim("general-d", "node-d")
+
+	dynamicClient := newDriftTestDynamicClient(nodePool, disrupting, consolidatable, blocked, idle)
+	clientset := fake.NewClientset(
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: disruptedTaintKey, Effect: corev1.TaintEffectNoSchedule}}},
+		},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-b"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-c"}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "batch", Name: "job-1", Annotations: map[string]string{doNotDisruptAnnotation: "true"}},
+			Spec:       corev1.PodSpec{NodeName: "node-c"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	)
+
+	pools, nodes, err := analyzeDisruption(context.Background(), dynamicClient, clientset, karpenterV1, now)
+	require.NoError(t, err)
+	require.Len(t, pools, 1)
+
+	pool := pools[0]
+	assert.Equal(t, "WhenEmptyOrUnderutilized", pool.ConsolidationPolicy)
+	assert.Equal(t, "1m", pool.ConsolidateAfter)
+	require.Len(t, pool.Budgets, 2)
+	assert.True(t, pool.Budgets[1].Active)
+	assert.Equal(t, 4, pool.NodeClaims)
+	assert.Equal(t, 1, pool.Disrupting)
+	assert.Equal(t, 1, pool.Candidates)
+	assert.Equal(t, 1, pool.Blocked)
+	assert.Equal(t, map[string]int{"Empty": 1, "Drifted": 1, "Underutilized": 0}, pool.AllowedDisruptions)
+
+	require.Len(t, nodes, 3, "NodeClaims with nothing to report are not listed")
+	states := map[string]data.KarpenterDisruptionNodeInfo{}
+	for _, n := range nodes {
+		states[n.NodeClaimName] = n
+	}
+	assert.Equal(t, "Disrupting", states["general-a"].State)
+	assert.Equal(t, "Consolidatable", states["general-b"].State)
+	assert.Equal(t, []string{"do-not-disrupt: pod batch/job-1"}, states["general-c"].BlockedBy)
+}
@@ -1,35 +1,355 @@
 package karpenter
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/jordiprats/kubectl-eks/pkg/data"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 )
 
-func GetDriftedResources(profile, region, clusterName string) ([]data.KarpenterDriftInfo, error) {
-	nodeClaims, err := GetNodeClaims(profile, region, clusterName)
+const (
+	nodePoolLabel           = "karpenter.sh/nodepool"
+	capacityTypeLabel       = "karpenter.sh/capacity-type"
+	doNotDisruptAnnotation  = "karpenter.sh/do-not-disrupt"
+	nodePoolHashAnnotation  = "karpenter.sh/nodepool-hash"
+	nodeClassHashAnnotation = "karpenter.k8s.aws/ec2nodeclass-hash"
+)
+
+func GetDriftedResources(profile, region, clusterName string) ([]data.KarpenterDriftInfo, []data.KarpenterDriftSummary, error) {
+	dynamicClient, clientset, api, err := newClients()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	drifted, summaries, err := analyzeDrift(context.TODO(), dynamicClient, clientset, api)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range drifted {
+		drifted[i].Profile = profile
+		drifted[i].Region = region
+		drifted[i].ClusterName = clusterName
+	}
+	for i := range summaries {
+		summaries[i].Profile = profile
+		summaries[i].Region = region
+		summaries[i].ClusterName = clusterName
+	}
+
+	return drifted, summaries, nil
+}
+
+// analyzeDrift finds the drifted NodeClaims, explains what changed in their
+// NodePool or EC2NodeClass and what blocks their replacement, and counts
+// them per NodePool
+func analyzeDrift(ctx context.Context, dynamicClient dynamic.Interface, clientset kubernetes.Interface, api karpenterAPI) ([]data.KarpenterDriftInfo, []data.KarpenterDriftSummary, error) {
+	nodeClaims, err := listNodeClaims(ctx, dynamicClient, api)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get NodeClaims: %w", err)
-	}
-
-	var result []data.KarpenterDriftInfo
-
-	for _, nc := range nodeClaims {
-		if nc.Drifted {
-			info := data.KarpenterDriftInfo{
-				Profile:      profile,
-				Region:       region,
-				ClusterName:  clusterName,
-				ResourceType: "NodeClaim",
-				Name:         nc.Name,
-				NodeName:     nc.NodeName,
-				NodePoolName: nc.NodePoolName,
-				DriftedSince: nc.Age, // Simplified - would need to check condition timestamp
-				Reason:       "Configuration drift detected",
+		return nil, nil, err
+	}
+
+	nodePoolList, err := listNodePools(ctx, dynamicClient, api)
+	if err != nil {
+		return nil, nil, err
+	}
+	nodePools := make(map[string]*unstructured.Unstructured)
+	for i := range nodePoolList {
+		nodePools[nodePoolList[i].GetName()] = &nodePoolList[i]
+	}
+
+	nodeClassList, err := listNodeClasses(ctx, dynamicClient, api)
+	if err != nil {
+		return nil, nil, err
+	}
+	nodeClasses := make(map[string]*unstructured.Unstructured)
+	for i := range nodeClassList {
+		nodeClasses[nodeClassList[i].GetName()] = &nodeClassList[i]
+	}
+
+	var pdbs []policyv1.PodDisruptionBudget
+
+	summaries := make(map[string]*data.KarpenterDriftSummary)
+	for name := range nodePools {
+		summaries[name] = &data.KarpenterDriftSummary{NodePoolName: name, Reasons: map[string]int{}}
+	}
+
+	result := []data.KarpenterDriftInfo{}
+	for i := range nodeClaims {
+		nc := &nodeClaims[i]
+		nodePoolName := nodePoolOf(nc)
+
+		summary, ok := summaries[nodePoolName]
+		if !ok {
+			summary = &data.KarpenterDriftSummary{NodePoolName: nodePoolName, Reasons: map[string]int{}}
+			summaries[nodePoolName] = summary
+		}
+		summary.NodeClaims++
+
+		condition := findCondition(nc.Object, "Drifted")
+		if condition == nil || condition["status"] != "True" {
+			continue
+		}
+
+		nodeClassName, _, _ := unstructured.NestedString(nc.Object, "spec", "nodeClassRef", "name")
+		nodeName, _, _ := unstructured.NestedString(nc.Object, "status", "nodeName")
+
+		info := data.KarpenterDriftInfo{
+			ResourceType:  "NodeClaim",
+			Name:          nc.GetName(),
+			NodeName:      nodeName,
+			NodePoolName:  nodePoolName,
+			NodeClassName: nodeClassName,
+		}
+		info.Reason, _ = condition["reason"].(string)
+		info.Message, _ = condition["message"].(string)
+		if transition, ok := condition["lastTransitionTime"].(string); ok {
+			if t, err := time.Parse(time.RFC3339, transition); err == nil {
+				info.DriftedSince = t
+			}
+		}
+		// Inferred reasons are kept, so they are counted in the summary
+		info.Reason, info.Cause = driftCause(info.Reason, nc, nodePools[nodePoolName], nodePoolName, nodeClasses[nodeClassName], nodeClassName)
+
+		// PodDisruptionBudgets are only needed once something drifted
+		if pdbs == nil {
+			pdbList, err := clientset.PolicyV1().PodDisruptionBudgets("").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to list PodDisruptionBudgets: %w", err)
+			}
+			pdbs = pdbList.Items
+		}
+
+		var node *corev1.Node
+		var pods []corev1.Pod
+		if nodeName != "" {
+			if n, err := clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{}); err == nil {
+				node = n
+			}
+			podList, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+				FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+			})
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to list pods of node %s: %w", nodeName, err)
 			}
-			result = append(result, info)
+			pods = podList.Items
 		}
+		info.BlockedBy = disruptionBlockers(nc, node, pods, pdbs)
+
+		summary.Drifted++
+		summary.Reasons[info.Reason]++
+		if len(info.BlockedBy) > 0 {
+			summary.Blocked++
+		}
+
+		result = append(result, info)
 	}
 
-	return result, nil
+	summaryList := []data.KarpenterDriftSummary{}
+	for _, summary := range summaries {
+		summaryList = append(summaryList, *summary)
+	}
+	sort.Slice(summaryList, func(i, j int) bool {
+		return summaryList[i].NodePoolName < summaryList[j].NodePoolName
+	})
+
+	return result, summaryList, nil
+}
+
+// nodePoolOf returns the NodePool a NodeClaim belongs to, from its
+// karpenter.sh/nodepool label or, failing that, its owner reference
+func nodePoolOf(nc *unstructured.Unstructured) string {
+	if name := nc.GetLabels()[nodePoolLabel]; name != "" {
+		return name
+	}
+	for _, owner := range nc.GetOwnerReferences() {
+		if owner.Kind == "NodePool" {
+			return owner.Name
+		}
+	}
+	return ""
+}
+
+func findCondition(obj map[string]interface{}, conditionType string) map[string]interface{} {
+	conditions, _, _ := unstructured.NestedSlice(obj, "status", "conditions")
+	for _, c := range conditions {
+		if condition, ok := c.(map[string]interface{}); ok && condition["type"] == conditionType {
+			return condition
+		}
+	}
+	return nil
+}
+
+// driftCause relates the drift reason to what changed in the NodePool or
+// EC2NodeClass and returns the reason along with it. Without a reason (older
+// Karpenter versions) the hashes and the AMI are compared to find it.
+func driftCause(reason string, nc, nodePool *unstructured.Unstructured, nodePoolName string, nodeClass *unstructured.Unstructured, nodeClassName string) (string, string) {
+	if reason == "" {
+		switch {
+		case nodePool != nil && hashChanged(nc, nodePool, nodePoolHashAnnotation):
+			reason = "NodePoolDrifted"
+		case nodeClass != nil && hashChanged(nc, nodeClass, nodeClassHashAnnotation):
+			reason = "NodeClassDrift"
+		case nodeClass != nil && !amiSelected(nc, nodeClass):
+			reason = "AMIDrift"
+		}
+	}
+
+	return reason, describeDrift(reason, nc, nodePool, nodePoolName, nodeClass, nodeClassName)
+}
+
+// describeDrift explains a drift reason. The NodePool reasons come from
+// Karpenter itself, the *Drift ones from the AWS provider.
+func describeDrift(reason string, nc, nodePool *unstructured.Unstructured, nodePoolName string, nodeClass *unstructured.Unstructured, nodeClassName string) string {
+	switch reason {
+	case "NodePoolDrifted", "RequirementsDrifted":
+		if nodePool == nil {
+			return fmt.Sprintf("NodePool %s no longer exists", nodePoolName)
+		}
+		if reason == "RequirementsDrifted" {
+			return fmt.Sprintf("Labels no longer satisfy the requirements of NodePool %s", nodePoolName)
+		}
+		return fmt.Sprintf("NodePool %s template changed (hash %s, now %s)", nodePoolName,
+			orDash(nc.GetAnnotations()[nodePoolHashAnnotation]), orDash(nodePool.GetAnnotations()[nodePoolHashAnnotation]))
+	}
+
+	if nodeClass == nil {
+		if strings.HasSuffix(reason, "Drift") {
+			return fmt.Sprintf("EC2NodeClass %s no longer exists", nodeClassName)
+		}
+		return "-"
+	}
+
+	switch reason {
+	case "NodeClassDrift":
+		return fmt.Sprintf("EC2NodeClass %s spec changed (hash %s, now %s)", nodeClassName,
+			orDash(nc.GetAnnotations()[nodeClassHashAnnotation]), orDash(nodeClass.GetAnnotations()[nodeClassHashAnnotation]))
+	case "AMIDrift":
+		imageID, _, _ := unstructured.NestedString(nc.Object, "status", "imageID")
+		return fmt.Sprintf("AMI %s is no longer selected by EC2NodeClass %s (now %s)", orDash(imageID), nodeClassName,
+			orDash(strings.Join(statusIDs(nodeClass, "amis"), ",")))
+	case "SubnetDrift":
+		return fmt.Sprintf("Subnet is no longer selected by EC2NodeClass %s (now %s)", nodeClassName,
+			orDash(strings.Join(statusIDs(nodeClass, "subnets"), ",")))
+	case "SecurityGroupDrift":
+		return fmt.Sprintf("Security groups no longer match EC2NodeClass %s (now %s)", nodeClassName,
+			orDash(strings.Join(statusIDs(nodeClass, "securityGroups"), ",")))
+	}
+
+	return "-"
+}
+
+// hashChanged reports whether the hash a NodeClaim was created with differs
+// from the current one of its NodePool or EC2NodeClass
+func hashChanged(nc, owner *unstructured.Unstructured, annotation string) bool {
+	claimHash := nc.GetAnnotations()[annotation]
+	currentHash := owner.GetAnnotations()[annotation]
+	return claimHash != "" && currentHash != "" && claimHash != currentHash
+}
+
+func amiSelected(nc, nodeClass *unstructured.Unstructured) bool {
+	imageID, _, _ := unstructured.NestedString(nc.Object, "status", "imageID")
+	amis := statusIDs(nodeClass, "amis")
+	if imageID == "" || len(amis) == 0 {
+		return true
+	}
+	for _, id := range amis {
+		if id == imageID {
+			return true
+		}
+	}
+	return false
+}
+
+// statusIDs returns the unique ids of a resolved list in an EC2NodeClass status
+func statusIDs(nodeClass *unstructured.Unstructured, field string) []string {
+	items, _, _ := unstructured.NestedSlice(nodeClass.Object, "status", field)
+	ids := []string{}
+	seen := make(map[string]bool)
+	for _, item := range items {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if id, _ := entry["id"].(string); id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// disruptionBlockers lists what keeps Karpenter from replacing a node:
+// do-not-disrupt annotations on the NodeClaim, the node or its pods, and
+// PodDisruptionBudgets covering its pods that allow no disruptions
+func disruptionBlockers(nc *unstructured.Unstructured, node *corev1.Node, pods []corev1.Pod, pdbs []policyv1.PodDisruptionBudget) []string {
+	blockers := []string{}
+
+	if nc.GetAnnotations()[doNotDisruptAnnotation] == "true" {
+		blockers = append(blockers, "do-not-disrupt: nodeclaim")
+	}
+	if node != nil && node.Annotations[doNotDisruptAnnotation] == "true" {
+		blockers = append(blockers, "do-not-disrupt: node")
+	}
+
+	blockingPDBs := make(map[string]bool)
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if pod.Annotations[doNotDisruptAnnotation] == "true" || pod.Annotations[legacyDoNotEvictAnnotation] == "true" {
+			blockers = append(blockers, fmt.Sprintf("do-not-disrupt: pod %s/%s", pod.Namespace, pod.Name))
+		}
+		if !isEvictable(pod) {
+			continue
+		}
+		for _, pdb := range pdbs {
+			if pdb.Namespace != pod.Namespace || pdb.Status.DisruptionsAllowed > 0 || pdb.Spec.Selector == nil {
+				continue
+			}
+			selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+			if err != nil || !selector.Matches(labels.Set(pod.Labels)) {
+				continue
+			}
+			key := pdb.Namespace + "/" + pdb.Name
+			if !blockingPDBs[key] {
+				blockingPDBs[key] = true
+				blockers = append(blockers, "pdb: "+key)
+			}
+		}
+	}
+
+	return blockers
+}
+
+// isEvictable reports whether a pod is evicted when its node is drained;
+// DaemonSet and static pods are left alone
+func isEvictable(pod corev1.Pod) bool {
+	if _, mirror := pod.Annotations[corev1.MirrorPodAnnotationKey]; mirror {
+		return false
+	}
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "DaemonSet" {
+			return false
+		}
+	}
+	return true
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
 }
@@ -0,0 +1,152 @@
+package karpenter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newKarpenterObject(apiVersion, kind, name string, annotations map[string]string, fields map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: fields}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetName(name)
+	obj.SetAnnotations(annotations)
+	return obj
+}
+
+func newDriftedNodeClaim(name, nodePool, nodeName, reason string, annotations map[string]string) *unstructured.Unstructured {
+	nc := newKarpenterObject("karpenter.sh/v1", "NodeClaim", name, annotations, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"nodeClassRef": map[string]interface{}{"group": "karpenter.k8s.aws", "kind": "EC2NodeClass", "name": "default"},
+		},
+		"status": map[string]interface{}{
+			"nodeName": nodeName,
+			"imageID":  "ami-old",
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "True"},
+				map[string]interface{}{"type": "Drifted", "status": "True", "reason": reason, "lastTransitionTime": "2024-06-01T12:00:00Z"},
+			},
+		},
+	})
+	nc.SetLabels(map[string]string{nodePoolLabel: nodePool})
+	return nc
+}
+
+func newDriftTestDynamicClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		nodeClaimGVR:    "NodeClaimList",
+		nodePoolGVR:     "NodePoolList",
+		ec2NodeClassGVR: "EC2NodeClassList",
+	}, objects...)
+}
+
+func TestAnalyzeDrift(t *testing.T) {
+	nodePool := newKarpenterObject("karpenter.sh/v1", "NodePool", "general", map[string]string{nodePoolHashAnnotation: "222"}, map[string]interface{}{})
+	idlePool := newKarpenterObject("karpenter.sh/v1", "NodePool", "idle", nil, map[string]interface{}{})
+	nodeClass := newKarpenterObject("karpenter.k8s.aws/v1", "EC2NodeClass", "default", nil, map[string]interface{}{
+		"status": map[string]interface{}{
+			"amis": []interface{}{
+				map[string]interface{}{"id": "ami-new"},
+				map[string]interface{}{"id": "ami-new"},
+			},
+		},
+	})
+
+	amiDrift := newDriftedNodeClaim("general-ami", "general", "node-ami", "AMIDrift", nil)
+	// Older Karpenter versions do not set a reason, the hashes tell what changed
+	hashDrift := newDriftedNodeClaim("general-hash", "general", "", "", map[string]string{nodePoolHashAnnotation: "111", doNotDisruptAnnotation: "true"})
+	healthy := newKarpenterObject("karpenter.sh/v1", "NodeClaim", "general-ok", nil, map[string]interface{}{})
+	healthy.SetLabels(map[string]string{nodePoolLabel: "general"})
+
+	dynamicClient := newDriftTestDynamicClient(nodePool, idlePool, nodeClass, amiDrift, hashDrift, healthy)
+
+	apiSelector := metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}}
+	clientset := fake.NewClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-ami"}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "api-1", Labels: map[string]string{"app": "api"}},
+			Spec:       corev1.PodSpec{NodeName: "node-ami"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "kube-system", Name: "aws-node-x", Labels: map[string]string{"app": "api"},
+				OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: "aws-node"}},
+			},
+			Spec:   corev1.PodSpec{NodeName: "node-ami"},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "api"},
+			Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &apiSelector},
+			Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 0},
+		},
+	)
+
+	drifted, summaries, err := analyzeDrift(context.Background(), dynamicClient, clientset, karpenterV1)
+	require.NoError(t, err)
+	require.Len(t, drifted, 2)
+
+	byName := map[string]int{}
+	for i, d := range drifted {
+		byName[d.Name] = i
+	}
+
+	ami := drifted[byName["general-ami"]]
+	assert.Equal(t, "AMIDrift", ami.Reason)
+	assert.Equal(t, "2024-06-01T12:00:00Z", ami.DriftedSince.UTC().Format("2006-01-02T15:04:05Z"))
+	assert.Equal(t, "AMI ami-old is no longer selected by EC2NodeClass default (now ami-new)", ami.Cause)
+	assert.Equal(t, []string{"pdb: shop/api"}, ami.BlockedBy, "DaemonSet pods are not evicted")
+
+	hash := drifted[byName["general-hash"]]
+	assert.Equal(t, "NodePool general template changed (hash 111, now 222)", hash.Cause)
+	assert.Equal(t, []string{"do-not-disrupt: nodeclaim"}, hash.BlockedBy)
+
+	require.Len(t, summaries, 2)
+	assert.Equal(t, "general", summaries[0].NodePoolName)
+	assert.Equal(t, 3, summaries[0].NodeClaims)
+	assert.Equal(t, 2, summaries[0].Drifted)
+	assert.Equal(t, 2, summaries[0].Blocked)
+	assert.Equal(t, "NodePoolDrifted", hash.Reason, "the inferred reason is kept")
+	assert.Equal(t, map[string]int{"AMIDrift": 1, "NodePoolDrifted": 1}, summaries[0].Reasons)
+	assert.Equal(t, "idle", summaries[1].NodePoolName)
+	assert.Equal(t, 0, summaries[1].NodeClaims)
+}
+
+func TestDriftCause_NodeClass(t *testing.T) {
+	nodeClass := newKarpenterObject("karpenter.k8s.aws/v1", "EC2NodeClass", "default", map[string]string{nodeClassHashAnnotation: "bbb"}, map[string]interface{}{})
+	nc := newDriftedNodeClaim("general-class", "general", "", "NodeClassDrift", map[string]string{nodeClassHashAnnotation: "aaa"})
+
+	reason, cause := driftCause("NodeClassDrift", nc, nil, "general", nodeClass, "default")
+	assert.Equal(t, "NodeClassDrift", reason)
+	assert.Equal(t, "EC2NodeClass default spec changed (hash aaa, now bbb)", cause)
+
+	nodePool := newKarpenterObject("karpenter.sh/v1", "NodePool", "general", nil, map[string]interface{}{})
+	reason, cause = driftCause("", nc, nodePool, "general", nodeClass, "default")
+	assert.Equal(t, "NodeClassDrift", reason, "inferred from the EC2NodeClass hash")
+	assert.Equal(t, "EC2NodeClass default spec changed (hash aaa, now bbb)", cause)
+
+	_, cause = driftCause("NodeClassDrift", nc, nodePool, "general", nil, "default")
+	assert.Equal(t, "EC2NodeClass default no longer exists", cause)
+}
+
+func TestNodePoolOf(t *testing.T) {
+	labelled := newKarpenterObject("karpenter.sh/v1", "NodeClaim", "a", nil, map[string]interface{}{})
+	labelled.SetLabels(map[string]string{nodePoolLabel: "general"})
+	assert.Equal(t, "general", nodePoolOf(labelled))
+
+	owned := newKarpenterObject("karpenter.sh/v1", "NodeClaim", "b", nil, map[string]interface{}{})
+	owned.SetOwnerReferences([]metav1.OwnerReference{{Kind: "NodePool", Name: "batch"}})
+	assert.Equal(t, "batch", nodePoolOf(owned))
+}
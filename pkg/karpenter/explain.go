@@ -0,0 +1,477 @@
+package karpenter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	placementSelected   = "Selected"
+	placementCompatible = "Compatible"
+	placementExcluded   = "Excluded"
+)
+
+// defaultRequirements are applied by Karpenter to NodePools that do not
+// constrain these labels
+var defaultRequirements = []data.KarpenterRequirement{
+	{Key: capacityTypeLabel, Operator: "In", Values: []string{"on-demand"}},
+	{Key: corev1.LabelArchStable, Operator: "In", Values: []string{"amd64"}},
+	{Key: corev1.LabelOSStable, Operator: "In", Values: []string{"linux"}},
+}
+
+// wellKnownLabels are set by Karpenter from the instance type it launches,
+// so a NodePool that does not constrain them can satisfy any value
+var wellKnownLabels = map[string]bool{
+	corev1.LabelArchStable:              true,
+	corev1.LabelOSStable:                true,
+	corev1.LabelInstanceTypeStable:      true,
+	corev1.LabelTopologyZone:            true,
+	corev1.LabelTopologyRegion:          true,
+	corev1.LabelHostname:                true,
+	corev1.LabelWindowsBuild:            true,
+	capacityTypeLabel:                   true,
+	nodePoolLabel:                       true,
+	"topology.k8s.aws/zone-id":          true,
+	"karpenter.k8s.aws/instance-ami-id": true,
+}
+
+// ExplainPod simulates how Karpenter picks a NodePool for a pod: NodePools
+// whose taints the pod does not tolerate, whose requirements and labels
+// conflict with its node selector or required node affinity, or whose limits
+// leave no room for its requests are excluded. Preferred node affinity is
+// honoured while some NodePool satisfies it, relaxing the lightest terms
+// first, and the NodePool with the highest weight wins.
+//
+// Instance type offerings and DaemonSet overhead are not evaluated, so a
+// Compatible NodePool may still fail to launch a node.
+func ExplainPod(pod *corev1.Pod, nodePools []data.KarpenterNodePoolInfo) []data.KarpenterPodPlacementInfo {
+	required := podRequiredTerms(pod)
+	preferred := podPreferredTerms(pod)
+	requests := podRequests(pod)
+
+	result := make([]data.KarpenterPodPlacementInfo, len(nodePools))
+	compatible := []int{}
+	for i, pool := range nodePools {
+		result[i] = data.KarpenterPodPlacementInfo{
+			Profile:      pool.Profile,
+			Region:       pool.Region,
+			ClusterName:  pool.ClusterName,
+			NodePoolName: pool.Name,
+			Weight:       pool.Weight,
+			Result:       placementExcluded,
+		}
+
+		reasons := untoleratedTaints(pod, pool)
+		reasons = append(reasons, exceededLimits(requests, pool)...)
+		reasons = append(reasons, unmetRequirements(required, pool)...)
+		if len(reasons) > 0 {
+			result[i].Reasons = reasons
+			continue
+		}
+
+		result[i].Result = placementCompatible
+		compatible = append(compatible, i)
+	}
+
+	// Karpenter treats preferences as requirements and relaxes them, lightest
+	// first, until a NodePool can be used
+	candidates := []int{}
+	honoured := len(preferred)
+	for ; honoured >= 0; honoured-- {
+		for _, i := range compatible {
+			if len(unmetRequirements(withPreferences(required, preferred[:honoured]), nodePools[i])) == 0 {
+				candidates = append(candidates, i)
+			}
+		}
+		if len(candidates) > 0 {
+			break
+		}
+	}
+
+	if len(candidates) > 0 {
+		sort.SliceStable(candidates, func(a, b int) bool {
+			if nodePools[candidates[a]].Weight != nodePools[candidates[b]].Weight {
+				return nodePools[candidates[a]].Weight > nodePools[candidates[b]].Weight
+			}
+			return nodePools[candidates[a]].Name < nodePools[candidates[b]].Name
+		})
+		selected := candidates[0]
+		result[selected].Result = placementSelected
+		if honoured < len(preferred) {
+			result[selected].Reasons = []string{fmt.Sprintf("preferred node affinity relaxed: %d of %d terms ignored", len(preferred)-honoured, len(preferred))}
+		}
+
+		isCandidate := make(map[int]bool)
+		for _, i := range candidates {
+			isCandidate[i] = true
+		}
+		for _, i := range compatible {
+			switch {
+			case i == selected:
+			case isCandidate[i]:
+				result[i].Reasons = []string{fmt.Sprintf("NodePool %s is preferred (weight %d, this one %d)", nodePools[selected].Name, nodePools[selected].Weight, nodePools[i].Weight)}
+			default:
+				result[i].Reasons = []string{"does not match the preferred node affinity"}
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Weight != result[j].Weight {
+			return result[i].Weight > result[j].Weight
+		}
+		return result[i].NodePoolName < result[j].NodePoolName
+	})
+
+	return result
+}
+
+// podRequiredTerms returns the alternative sets of requirements a node must
+// meet: the node selector combined with each required node affinity term
+func podRequiredTerms(pod *corev1.Pod) [][]corev1.NodeSelectorRequirement {
+	base := []corev1.NodeSelectorRequirement{}
+	keys := make([]string, 0, len(pod.Spec.NodeSelector))
+	for key := range pod.Spec.NodeSelector {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		base = append(base, corev1.NodeSelectorRequirement{
+			Key:      key,
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   []string{pod.Spec.NodeSelector[key]},
+		})
+	}
+
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return [][]corev1.NodeSelectorRequirement{base}
+	}
+
+	terms := [][]corev1.NodeSelectorRequirement{}
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		combined := append(append([]corev1.NodeSelectorRequirement{}, base...), term.MatchExpressions...)
+		terms = append(terms, combined)
+	}
+	if len(terms) == 0 {
+		return [][]corev1.NodeSelectorRequirement{base}
+	}
+	return terms
+}
+
+// podPreferredTerms returns the preferred node affinity terms, heaviest first
+func podPreferredTerms(pod *corev1.Pod) []corev1.PreferredSchedulingTerm {
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil {
+		return nil
+	}
+	preferred := append([]corev1.PreferredSchedulingTerm{}, affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution...)
+	sort.SliceStable(preferred, func(i, j int) bool {
+		return preferred[i].Weight > preferred[j].Weight
+	})
+	return preferred
+}
+
+func withPreferences(required [][]corev1.NodeSelectorRequirement, preferred []corev1.PreferredSchedulingTerm) [][]corev1.NodeSelectorRequirement {
+	result := [][]corev1.NodeSelectorRequirement{}
+	for _, term := range required {
+		combined := append([]corev1.NodeSelectorRequirement{}, term...)
+		for _, p := range preferred {
+			combined = append(combined, p.Preference.MatchExpressions...)
+		}
+		result = append(result, combined)
+	}
+	return result
+}
+
+// podRequests returns the resources the pod needs on a node: its containers
+// and sidecars, or its largest init container if bigger, plus its overhead
+func podRequests(pod *corev1.Pod) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	add := func(list corev1.ResourceList, resources corev1.ResourceList) {
+		for name, quantity := range resources {
+			total := list[name]
+			total.Add(quantity)
+			list[name] = total
+		}
+	}
+
+	for _, container := range pod.Spec.Containers {
+		add(requests, container.Resources.Requests)
+	}
+
+	sidecars := corev1.ResourceList{}
+	for _, container := range pod.Spec.InitContainers {
+		if container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			add(requests, container.Resources.Requests)
+			add(sidecars, container.Resources.Requests)
+			continue
+		}
+		// Init containers run one at a time, next to the sidecars started before
+		for name, quantity := range container.Resources.Requests {
+			needed := sidecars[name]
+			needed.Add(quantity)
+			if current := requests[name]; needed.Cmp(current) > 0 {
+				requests[name] = needed
+			}
+		}
+	}
+
+	add(requests, pod.Spec.Overhead)
+
+	return requests
+}
+
+// untoleratedTaints lists the NoSchedule and NoExecute taints of the
+// NodePool the pod does not tolerate
+func untoleratedTaints(pod *corev1.Pod, pool data.KarpenterNodePoolInfo) []string {
+	reasons := []string{}
+	for _, t := range pool.Taints {
+		taint := corev1.Taint{Key: t.Key, Value: t.Value, Effect: corev1.TaintEffect(t.Effect)}
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for i := range pod.Spec.Tolerations {
+			if tolerates(pod.Spec.Tolerations[i], taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			reasons = append(reasons, "does not tolerate taint "+taint.ToString())
+		}
+	}
+	return reasons
+}
+
+// tolerates matches a toleration against a taint: an empty key with Exists
+// tolerates everything and an empty effect any effect
+func tolerates(toleration corev1.Toleration, taint corev1.Taint) bool {
+	if toleration.Effect != "" && toleration.Effect != taint.Effect {
+		return false
+	}
+	if toleration.Key != "" && toleration.Key != taint.Key {
+		return false
+	}
+	switch toleration.Operator {
+	case corev1.TolerationOpExists:
+		return true
+	case corev1.TolerationOpEqual, "":
+		return toleration.Key != "" && toleration.Value == taint.Value
+	}
+	return false
+}
+
+// exceededLimits lists the NodePool limits the pod requests do not fit in
+func exceededLimits(requests corev1.ResourceList, pool data.KarpenterNodePoolInfo) []string {
+	reasons := []string{}
+	for _, limit := range []struct {
+		name  corev1.ResourceName
+		limit string
+		usage string
+	}{
+		{corev1.ResourceCPU, pool.CPULimit, pool.CPUUsage},
+		{corev1.ResourceMemory, pool.MemoryLimit, pool.MemoryUsage},
+	} {
+		request, ok := requests[limit.name]
+		if !ok || limit.limit == "" {
+			continue
+		}
+		limitQuantity, err := resource.ParseQuantity(limit.limit)
+		if err != nil {
+			continue
+		}
+		remaining := limitQuantity.DeepCopy()
+		if limit.usage != "" {
+			if usage, err := resource.ParseQuantity(limit.usage); err == nil {
+				remaining.Sub(usage)
+			}
+		}
+		if request.Cmp(remaining) > 0 {
+			reasons = append(reasons, fmt.Sprintf("%s limit reached: requests %s, %s left of %s", limit.name, request.String(), remaining.String(), limit.limit))
+		}
+	}
+	return reasons
+}
+
+// unmetRequirements checks the alternative requirement sets of a pod
+// against the requirements and labels of a NodePool; nothing is returned
+// when any of the alternatives is met
+func unmetRequirements(terms [][]corev1.NodeSelectorRequirement, pool data.KarpenterNodePoolInfo) []string {
+	poolRequirements := nodePoolRequirements(pool)
+
+	reasons := []string{}
+	for i, term := range terms {
+		termReasons := []string{}
+		for _, podRequirement := range term {
+			termReasons = append(termReasons, unmetRequirement(podRequirement, poolRequirements)...)
+		}
+		if len(termReasons) == 0 {
+			return nil
+		}
+		if len(terms) > 1 {
+			for j := range termReasons {
+				termReasons[j] = fmt.Sprintf("nodeSelectorTerm %d: %s", i, termReasons[j])
+			}
+		}
+		reasons = append(reasons, termReasons...)
+	}
+	return reasons
+}
+
+func unmetRequirement(podRequirement corev1.NodeSelectorRequirement, poolRequirements map[string][]corev1.NodeSelectorRequirement) []string {
+	podValues := newValueSet(podRequirement)
+
+	constraints, ok := poolRequirements[podRequirement.Key]
+	if !ok {
+		if wellKnownLabels[podRequirement.Key] || strings.HasPrefix(podRequirement.Key, "karpenter.k8s.aws/instance-") || podValues.allowsAbsent {
+			return nil
+		}
+		return []string{fmt.Sprintf("requires %s, a label the NodePool does not set", formatRequirement(podRequirement))}
+	}
+
+	reasons := []string{}
+	for _, constraint := range constraints {
+		if !podValues.compatible(newValueSet(constraint)) {
+			reasons = append(reasons, fmt.Sprintf("requires %s, NodePool has %s", formatRequirement(podRequirement), formatRequirement(constraint)))
+		}
+	}
+	return reasons
+}
+
+// nodePoolRequirements returns the requirements of the nodes a NodePool
+// creates: its requirements, its labels, its own name and the defaults for
+// what it does not constrain
+func nodePoolRequirements(pool data.KarpenterNodePoolInfo) map[string][]corev1.NodeSelectorRequirement {
+	result := make(map[string][]corev1.NodeSelectorRequirement)
+	add := func(key, operator string, values []string) {
+		result[key] = append(result[key], corev1.NodeSelectorRequirement{
+			Key:      key,
+			Operator: corev1.NodeSelectorOperator(operator),
+			Values:   values,
+		})
+	}
+
+	for _, r := range pool.Requirements {
+		add(r.Key, r.Operator, r.Values)
+	}
+	for key, value := range pool.Labels {
+		add(key, "In", []string{value})
+	}
+	add(nodePoolLabel, "In", []string{pool.Name})
+	for _, r := range defaultRequirements {
+		if _, ok := result[r.Key]; !ok {
+			add(r.Key, r.Operator, r.Values)
+		}
+	}
+
+	return result
+}
+
+func formatRequirement(r corev1.NodeSelectorRequirement) string {
+	switch r.Operator {
+	case corev1.NodeSelectorOpExists, corev1.NodeSelectorOpDoesNotExist:
+		return fmt.Sprintf("%s %s", r.Key, r.Operator)
+	case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+		return fmt.Sprintf("%s %s %s", r.Key, r.Operator, strings.Join(r.Values, ","))
+	}
+	return fmt.Sprintf("%s %s [%s]", r.Key, r.Operator, strings.Join(r.Values, ","))
+}
+
+// valueSet is the set of label values a requirement accepts: the listed
+// values, or all values but the listed ones when complement is set,
+// optionally bounded by Gt and Lt
+type valueSet struct {
+	complement   bool
+	values       map[string]bool
+	greaterThan  *int64
+	lessThan     *int64
+	allowsAbsent bool
+}
+
+func newValueSet(r corev1.NodeSelectorRequirement) valueSet {
+	set := valueSet{values: make(map[string]bool)}
+	for _, v := range r.Values {
+		set.values[v] = true
+	}
+
+	switch r.Operator {
+	case corev1.NodeSelectorOpNotIn:
+		set.complement = true
+		set.allowsAbsent = true
+	case corev1.NodeSelectorOpExists:
+		set.complement = true
+	case corev1.NodeSelectorOpDoesNotExist:
+		set.allowsAbsent = true
+	case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+		set.complement = true
+		set.values = map[string]bool{}
+		if len(r.Values) == 1 {
+			if bound, err := strconv.ParseInt(r.Values[0], 10, 64); err == nil {
+				if r.Operator == corev1.NodeSelectorOpGt {
+					set.greaterThan = &bound
+				} else {
+					set.lessThan = &bound
+				}
+			}
+		}
+	}
+
+	return set
+}
+
+// compatible reports whether a node could meet both requirements: there is
+// a value both accept, or both accept the label not being set
+func (s valueSet) compatible(other valueSet) bool {
+	if s.allowsAbsent && other.allowsAbsent {
+		return true
+	}
+
+	greaterThan := s.greaterThan
+	if other.greaterThan != nil && (greaterThan == nil || *other.greaterThan > *greaterThan) {
+		greaterThan = other.greaterThan
+	}
+	lessThan := s.lessThan
+	if other.lessThan != nil && (lessThan == nil || *other.lessThan < *lessThan) {
+		lessThan = other.lessThan
+	}
+	inBounds := func(value string) bool {
+		if greaterThan == nil && lessThan == nil {
+			return true
+		}
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false
+		}
+		return (greaterThan == nil || number > *greaterThan) && (lessThan == nil || number < *lessThan)
+	}
+
+	switch {
+	case !s.complement && !other.complement:
+		for value := range s.values {
+			if other.values[value] && inBounds(value) {
+				return true
+			}
+		}
+		return false
+	case !s.complement:
+		for value := range s.values {
+			if !other.values[value] && inBounds(value) {
+				return true
+			}
+		}
+		return false
+	case !other.complement:
+		return other.compatible(s)
+	}
+
+	// Both sets are infinite unless the bounds leave no integer in between
+	return greaterThan == nil || lessThan == nil || *lessThan-*greaterThan > 1
+}
@@ -0,0 +1,153 @@
+package karpenter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// loadNodePoolFixtures parses the NodePools of testdata/explain/nodepools.yaml
+func loadNodePoolFixtures(t *testing.T) []data.KarpenterNodePoolInfo {
+	file, err := os.Open(filepath.Join("testdata", "explain", "nodepools.yaml"))
+	require.NoError(t, err)
+	defer file.Close()
+
+	var nodePools []unstructured.Unstructured
+	decoder := yaml.NewYAMLOrJSONDecoder(file, 4096)
+	for {
+		// Decoded as the dynamic client does, with integers kept as int64
+		raw := runtime.RawExtension{}
+		if err := decoder.Decode(&raw); err != nil {
+			break
+		}
+		obj := unstructured.Unstructured{}
+		require.NoError(t, obj.UnmarshalJSON(raw.Raw))
+		nodePools = append(nodePools, obj)
+	}
+	require.Len(t, nodePools, 4)
+
+	return parseNodePools("prod", "eu-west-1", "main", nodePools, nil)
+}
+
+func explainFixture(t *testing.T, manifest string) map[string]data.KarpenterPodPlacementInfo {
+	pod, err := LoadPodManifest(filepath.Join("testdata", "explain", manifest))
+	require.NoError(t, err)
+
+	placements := ExplainPod(pod, loadNodePoolFixtures(t))
+	require.Len(t, placements, 4)
+
+	byName := map[string]data.KarpenterPodPlacementInfo{}
+	for _, p := range placements {
+		byName[p.NodePoolName] = p
+	}
+	return byName
+}
+
+func TestExplainPod_Deployment(t *testing.T) {
+	placements := explainFixture(t, "web-deployment.yaml")
+
+	assert.Equal(t, "Selected", placements["batch"].Result, "highest weight among the compatible NodePools")
+	assert.Empty(t, placements["batch"].Reasons)
+	assert.Equal(t, "Compatible", placements["default"].Result)
+	assert.Equal(t, []string{"NodePool batch is preferred (weight 20, this one 0)"}, placements["default"].Reasons)
+	assert.Equal(t, "Compatible", placements["arm"].Result)
+	assert.Equal(t, "Excluded", placements["gpu"].Result)
+	assert.Equal(t, []string{"does not tolerate taint nvidia.com/gpu=true:NoSchedule"}, placements["gpu"].Reasons)
+}
+
+func TestExplainPod_Job(t *testing.T) {
+	placements := explainFixture(t, "gpu-job.yaml")
+
+	assert.Equal(t, "Selected", placements["gpu"].Result)
+	assert.Equal(t, "Excluded", placements["default"].Result)
+	assert.Equal(t, []string{
+		"cpu limit reached: requests 4, 2 left of 100",
+		"requires workload In [gpu], a label the NodePool does not set",
+	}, placements["default"].Reasons)
+	assert.Equal(t, []string{"requires workload In [gpu], a label the NodePool does not set"}, placements["batch"].Reasons)
+}
+
+func TestExplainPod_PreferredAffinity(t *testing.T) {
+	placements := explainFixture(t, "arm-pod.json")
+
+	assert.Equal(t, "Selected", placements["default"].Result, "spot is preferred, arm only offers the on-demand default")
+	assert.Equal(t, "Compatible", placements["arm"].Result)
+	assert.Equal(t, []string{"does not match the preferred node affinity"}, placements["arm"].Reasons)
+	assert.Equal(t, []string{"requires kubernetes.io/arch In [arm64], NodePool has kubernetes.io/arch In [amd64]"}, placements["batch"].Reasons)
+}
+
+func TestExplainPod_RelaxedPreferences(t *testing.T) {
+	pod := &corev1.Pod{Spec: corev1.PodSpec{Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{
+			{Weight: 1, Preference: corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "team", Operator: corev1.NodeSelectorOpIn, Values: []string{"web"}}}}},
+		},
+	}}}}
+
+	placements := ExplainPod(pod, []data.KarpenterNodePoolInfo{{Name: "default"}})
+	require.Len(t, placements, 1)
+	assert.Equal(t, "Selected", placements[0].Result)
+	assert.Equal(t, []string{"preferred node affinity relaxed: 1 of 1 terms ignored"}, placements[0].Reasons)
+}
+
+func TestValueSetCompatible(t *testing.T) {
+	req := func(operator corev1.NodeSelectorOperator, values ...string) valueSet {
+		return newValueSet(corev1.NodeSelectorRequirement{Key: "k", Operator: operator, Values: values})
+	}
+
+	assert.True(t, req(corev1.NodeSelectorOpIn, "a", "b").compatible(req(corev1.NodeSelectorOpIn, "b")))
+	assert.False(t, req(corev1.NodeSelectorOpIn, "a").compatible(req(corev1.NodeSelectorOpIn, "b")))
+	assert.False(t, req(corev1.NodeSelectorOpIn, "a").compatible(req(corev1.NodeSelectorOpNotIn, "a")))
+	assert.True(t, req(corev1.NodeSelectorOpNotIn, "a").compatible(req(corev1.NodeSelectorOpDoesNotExist)), "both accept the label not being set")
+	assert.False(t, req(corev1.NodeSelectorOpExists).compatible(req(corev1.NodeSelectorOpDoesNotExist)))
+	assert.True(t, req(corev1.NodeSelectorOpGt, "4").compatible(req(corev1.NodeSelectorOpIn, "8", "2")))
+	assert.False(t, req(corev1.NodeSelectorOpGt, "4").compatible(req(corev1.NodeSelectorOpLt, "5")))
+	assert.True(t, req(corev1.NodeSelectorOpGt, "4").compatible(req(corev1.NodeSelectorOpLt, "6")))
+}
+
+func TestPodRequests(t *testing.T) {
+	always := corev1.ContainerRestartPolicyAlways
+	cpu := func(value string) corev1.ResourceRequirements {
+		return corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(value)}}
+	}
+
+	pod := &corev1.Pod{Spec: corev1.PodSpec{
+		InitContainers: []corev1.Container{
+			{Name: "sidecar", Resources: cpu("500m"), RestartPolicy: &always},
+			{Name: "migrate", Resources: cpu("3")},
+		},
+		Containers: []corev1.Container{{Name: "app", Resources: cpu("1")}, {Name: "log", Resources: cpu("250m")}},
+		Overhead:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+	}}
+
+	// The init container next to the sidecar (3.5) needs more than the
+	// containers and the sidecar (1.75)
+	requests := podRequests(pod)
+	cpuRequest := requests[corev1.ResourceCPU]
+	assert.Equal(t, "3600m", cpuRequest.String())
+}
+
+func TestTolerates(t *testing.T) {
+	taint := corev1.Taint{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}
+
+	assert.True(t, tolerates(corev1.Toleration{Key: "dedicated", Value: "gpu"}, taint))
+	assert.True(t, tolerates(corev1.Toleration{Operator: corev1.TolerationOpExists}, taint))
+	assert.False(t, tolerates(corev1.Toleration{Key: "dedicated", Value: "cpu"}, taint))
+	assert.False(t, tolerates(corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute}, taint))
+}
+
+func TestLoadPodManifest_NoPod(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cm.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: x\n"), 0o644))
+
+	_, err := LoadPodManifest(path)
+	assert.ErrorContains(t, err, "no Pod or workload with a pod template found")
+}
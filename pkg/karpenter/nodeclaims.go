@@ -2,14 +2,10 @@ package karpenter
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/jordiprats/kubectl-eks/pkg/data"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/tools/clientcmd"
 )
 
 var nodeClaimGVR = schema.GroupVersionResource{
@@ -19,30 +15,28 @@ var nodeClaimGVR = schema.GroupVersionResource{
 }
 
 func GetNodeClaims(profile, region, clusterName string) ([]data.KarpenterNodeClaimInfo, error) {
-	config, err := clientcmd.BuildConfigFromFlags("", clientcmd.RecommendedHomeFile)
+	dynamicClient, _, api, err := newClients()
 	if err != nil {
-		return nil, fmt.Errorf("failed to build kubeconfig: %w", err)
+		return nil, err
 	}
 
-	dynamicClient, err := dynamic.NewForConfig(config)
+	nodeClaims, err := listNodeClaims(context.TODO(), dynamicClient, api)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
-	}
-
-	nodeClaims, err := dynamicClient.Resource(nodeClaimGVR).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to list NodeClaims: %w", err)
+		return nil, err
 	}
 
 	var result []data.KarpenterNodeClaimInfo
 
-	for _, nc := range nodeClaims.Items {
+	for _, nc := range nodeClaims {
 		info := data.KarpenterNodeClaimInfo{
-			Profile:     profile,
-			Region:      region,
-			ClusterName: clusterName,
-			Name:        nc.GetName(),
-			Age:         nc.GetCreationTimestamp().Time,
+			Profile:      profile,
+			Region:       region,
+			ClusterName:  clusterName,
+			Name:         nc.GetName(),
+			NodePoolName: nodePoolOf(&nc),
+			InstanceType: instanceTypeOf(&nc),
+			CapacityType: nc.GetLabels()[capacityTypeLabel],
+			Age:          nc.GetCreationTimestamp().Time,
 		}
 
 		// Extract spec fields
@@ -51,9 +45,10 @@ func GetNodeClaims(profile, region, clusterName string) ([]data.KarpenterNodeCla
 			continue
 		}
 
+		// nodeClassRef points at the EC2NodeClass, not the NodePool
 		if nodeClassRef, ok := spec["nodeClassRef"].(map[string]interface{}); ok {
 			if name, ok := nodeClassRef["name"].(string); ok {
-				info.NodePoolName = name
+				info.NodeClassName = name
 			}
 		}
 
@@ -67,18 +62,13 @@ func GetNodeClaims(profile, region, clusterName string) ([]data.KarpenterNodeCla
 				info.NodeName = nodeName
 			}
 
-			// Instance type
-			if instanceType, ok := status["instanceType"].(string); ok {
-				info.InstanceType = instanceType
-			}
-
 			// Zone
 			if zone, ok := status["zone"].(string); ok {
 				info.Zone = zone
 			}
 
-			// Capacity type
-			if capacityType, ok := status["capacityType"].(string); ok {
+			// Capacity type, for versions that do not label it
+			if capacityType, ok := status["capacityType"].(string); ok && info.CapacityType == "" {
 				info.CapacityType = capacityType
 			}
 
@@ -0,0 +1,212 @@
+package karpenter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var ec2NodeClassGVR = schema.GroupVersionResource{
+	Group:    "karpenter.k8s.aws",
+	Version:  "v1",
+	Resource: "ec2nodeclasses",
+}
+
+func GetNodeClasses(profile, region, clusterName string) ([]data.KarpenterNodeClassInfo, error) {
+	dynamicClient, _, api, err := newClients()
+	if err != nil {
+		return nil, err
+	}
+
+	nodeClasses, err := listNodeClasses(context.TODO(), dynamicClient, api)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []data.KarpenterNodeClassInfo
+	for _, nc := range nodeClasses {
+		info := parseNodeClass(nc)
+		info.Profile = profile
+		info.Region = region
+		info.ClusterName = clusterName
+		result = append(result, info)
+	}
+
+	return result, nil
+}
+
+// parseNodeClass extracts the selectors, what they resolved to and the
+// readiness of an EC2NodeClass
+func parseNodeClass(nc unstructured.Unstructured) data.KarpenterNodeClassInfo {
+	info := data.KarpenterNodeClassInfo{
+		Name: nc.GetName(),
+		Age:  nc.GetCreationTimestamp().Time,
+	}
+
+	// Spec
+	info.AMISelectorTerms = selectorTerms(nc.Object, "amiSelectorTerms")
+	info.SubnetSelectorTerms = selectorTerms(nc.Object, "subnetSelectorTerms")
+	info.SecurityGroupSelectorTerms = selectorTerms(nc.Object, "securityGroupSelectorTerms")
+	info.Role, _, _ = unstructured.NestedString(nc.Object, "spec", "role")
+	info.InstanceProfile, _, _ = unstructured.NestedString(nc.Object, "spec", "instanceProfile")
+
+	mappings, _, _ := unstructured.NestedSlice(nc.Object, "spec", "blockDeviceMappings")
+	for _, m := range mappings {
+		if mapping, ok := m.(map[string]interface{}); ok {
+			info.BlockDeviceMappings = append(info.BlockDeviceMappings, formatBlockDeviceMapping(mapping))
+		}
+	}
+
+	// Status
+	if info.InstanceProfile == "" {
+		info.InstanceProfile, _, _ = unstructured.NestedString(nc.Object, "status", "instanceProfile")
+	}
+
+	amis, _, _ := unstructured.NestedSlice(nc.Object, "status", "amis")
+	seenAMIs := make(map[string]bool)
+	for _, a := range amis {
+		ami, ok := a.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := ami["id"].(string)
+		// The same AMI is listed once per set of requirements it satisfies
+		if id == "" || seenAMIs[id] {
+			continue
+		}
+		seenAMIs[id] = true
+		entry := id
+		if name, _ := ami["name"].(string); name != "" {
+			entry += " (" + name + ")"
+		}
+		if deprecated, _ := ami["deprecated"].(bool); deprecated {
+			entry += " [deprecated]"
+		}
+		info.AMIs = append(info.AMIs, entry)
+	}
+
+	subnets, _, _ := unstructured.NestedSlice(nc.Object, "status", "subnets")
+	for _, s := range subnets {
+		subnet, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := subnet["id"].(string)
+		if zone, _ := subnet["zone"].(string); zone != "" {
+			id += " (" + zone + ")"
+		}
+		info.Subnets = append(info.Subnets, id)
+	}
+
+	securityGroups, _, _ := unstructured.NestedSlice(nc.Object, "status", "securityGroups")
+	for _, g := range securityGroups {
+		group, ok := g.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := group["id"].(string)
+		if name, _ := group["name"].(string); name != "" {
+			id += " (" + name + ")"
+		}
+		info.SecurityGroups = append(info.SecurityGroups, id)
+	}
+
+	info.Ready = "Unknown"
+	conditions, _, _ := unstructured.NestedSlice(nc.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		condType, _ := condition["type"].(string)
+		condStatus, _ := condition["status"].(string)
+		if condType == "Ready" {
+			info.Ready = condStatus
+		}
+		if condStatus != "True" {
+			failing := condType
+			if reason, _ := condition["reason"].(string); reason != "" {
+				failing += ": " + reason
+			}
+			info.FailingConditions = append(info.FailingConditions, failing)
+		}
+	}
+
+	return info
+}
+
+// selectorTerms formats the selector terms of the given spec field. Each
+// term is rendered as its space separated key=value pairs (tags included),
+// sorted so the output is stable.
+func selectorTerms(obj map[string]interface{}, field string) []string {
+	terms, _, _ := unstructured.NestedSlice(obj, "spec", field)
+
+	var result []string
+	for _, t := range terms {
+		term, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		parts := []string{}
+		for key, value := range term {
+			switch v := value.(type) {
+			case string:
+				parts = append(parts, key+"="+v)
+			case map[string]interface{}:
+				// tags
+				for tagKey, tagValue := range v {
+					parts = append(parts, fmt.Sprintf("%s=%v", tagKey, tagValue))
+				}
+			}
+		}
+		sort.Strings(parts)
+		result = append(result, strings.Join(parts, " "))
+	}
+
+	return result
+}
+
+// formatBlockDeviceMapping renders a block device mapping as
+// device:type:size with its notable options, e.g. /dev/xvda:gp3:100Gi(root,encrypted)
+func formatBlockDeviceMapping(mapping map[string]interface{}) string {
+	deviceName, _ := mapping["deviceName"].(string)
+	ebs, _ := mapping["ebs"].(map[string]interface{})
+
+	volumeType, _ := ebs["volumeType"].(string)
+	if volumeType == "" {
+		volumeType = "-"
+	}
+	volumeSize := "-"
+	switch size := ebs["volumeSize"].(type) {
+	case string:
+		volumeSize = size
+	case int64:
+		volumeSize = fmt.Sprintf("%d", size)
+	}
+
+	options := []string{}
+	if root, _ := mapping["rootVolume"].(bool); root {
+		options = append(options, "root")
+	}
+	if encrypted, _ := ebs["encrypted"].(bool); encrypted {
+		options = append(options, "encrypted")
+	}
+	if iops, ok := ebs["iops"].(int64); ok {
+		options = append(options, fmt.Sprintf("iops=%d", iops))
+	}
+	if throughput, ok := ebs["throughput"].(int64); ok {
+		options = append(options, fmt.Sprintf("throughput=%d", throughput))
+	}
+
+	formatted := fmt.Sprintf("%s:%s:%s", deviceName, volumeType, volumeSize)
+	if len(options) > 0 {
+		formatted += "(" + strings.Join(options, ",") + ")"
+	}
+	return formatted
+}
@@ -0,0 +1,82 @@
+package karpenter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestParseNodeClass(t *testing.T) {
+	nc := unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "karpenter.k8s.aws/v1",
+		"kind":       "EC2NodeClass",
+		"metadata":   map[string]interface{}{"name": "default"},
+		"spec": map[string]interface{}{
+			"role": "KarpenterNodeRole-prod",
+			"amiSelectorTerms": []interface{}{
+				map[string]interface{}{"alias": "al2023@latest"},
+			},
+			"subnetSelectorTerms": []interface{}{
+				map[string]interface{}{"tags": map[string]interface{}{"karpenter.sh/discovery": "prod"}},
+				map[string]interface{}{"id": "subnet-3"},
+			},
+			"securityGroupSelectorTerms": []interface{}{
+				map[string]interface{}{"tags": map[string]interface{}{"karpenter.sh/discovery": "prod", "tier": "nodes"}},
+			},
+			"blockDeviceMappings": []interface{}{
+				map[string]interface{}{
+					"deviceName": "/dev/xvda",
+					"rootVolume": true,
+					"ebs":        map[string]interface{}{"volumeSize": "100Gi", "volumeType": "gp3", "encrypted": true, "iops": int64(3000)},
+				},
+			},
+		},
+		"status": map[string]interface{}{
+			"instanceProfile": "prod_1234",
+			"amis": []interface{}{
+				map[string]interface{}{"id": "ami-arm", "name": "al2023-arm64"},
+				map[string]interface{}{"id": "ami-x86", "name": "al2023-x86_64", "deprecated": true},
+				map[string]interface{}{"id": "ami-x86", "name": "al2023-x86_64", "deprecated": true},
+			},
+			"subnets": []interface{}{
+				map[string]interface{}{"id": "subnet-1", "zone": "eu-west-1a"},
+				map[string]interface{}{"id": "subnet-2", "zone": "eu-west-1b"},
+			},
+			"securityGroups": []interface{}{
+				map[string]interface{}{"id": "sg-1", "name": "nodes"},
+			},
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "AMIsReady", "status": "True"},
+				map[string]interface{}{"type": "SubnetsReady", "status": "False", "reason": "SubnetsNotFound"},
+				map[string]interface{}{"type": "Ready", "status": "False", "reason": "ConditionsNotReady"},
+			},
+		},
+	}}
+
+	info := parseNodeClass(nc)
+
+	assert.Equal(t, "default", info.Name)
+	assert.Equal(t, []string{"alias=al2023@latest"}, info.AMISelectorTerms)
+	assert.Equal(t, []string{"karpenter.sh/discovery=prod", "id=subnet-3"}, info.SubnetSelectorTerms)
+	assert.Equal(t, []string{"karpenter.sh/discovery=prod tier=nodes"}, info.SecurityGroupSelectorTerms)
+	assert.Equal(t, []string{"ami-arm (al2023-arm64)", "ami-x86 (al2023-x86_64) [deprecated]"}, info.AMIs, "AMIs listed for several requirements are shown once")
+	assert.Equal(t, []string{"subnet-1 (eu-west-1a)", "subnet-2 (eu-west-1b)"}, info.Subnets)
+	assert.Equal(t, []string{"sg-1 (nodes)"}, info.SecurityGroups)
+	assert.Equal(t, "KarpenterNodeRole-prod", info.Role)
+	assert.Equal(t, "prod_1234", info.InstanceProfile, "the instance profile created for the role comes from the status")
+	assert.Equal(t, []string{"/dev/xvda:gp3:100Gi(root,encrypted,iops=3000)"}, info.BlockDeviceMappings)
+	assert.Equal(t, "False", info.Ready)
+	assert.Equal(t, []string{"SubnetsReady: SubnetsNotFound", "Ready: ConditionsNotReady"}, info.FailingConditions)
+}
+
+func TestParseNodeClass_NoStatus(t *testing.T) {
+	info := parseNodeClass(unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "new"},
+		"spec":     map[string]interface{}{"instanceProfile": "custom-profile"},
+	}})
+
+	assert.Equal(t, "Unknown", info.Ready)
+	assert.Equal(t, "custom-profile", info.InstanceProfile)
+	assert.Empty(t, info.AMIs)
+}
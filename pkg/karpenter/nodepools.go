@@ -5,11 +5,9 @@ import (
 	"fmt"
 
 	"github.com/jordiprats/kubectl-eks/pkg/data"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/tools/clientcmd"
 )
 
 var nodePoolGVR = schema.GroupVersionResource{
@@ -19,30 +17,59 @@ var nodePoolGVR = schema.GroupVersionResource{
 }
 
 func GetNodePools(profile, region, clusterName string) ([]data.KarpenterNodePoolInfo, error) {
-	config, err := clientcmd.BuildConfigFromFlags("", clientcmd.RecommendedHomeFile)
+	dynamicClient, _, api, err := newClients()
 	if err != nil {
-		return nil, fmt.Errorf("failed to build kubeconfig: %w", err)
+		return nil, err
 	}
 
-	dynamicClient, err := dynamic.NewForConfig(config)
+	nodePools, err := listNodePools(context.TODO(), dynamicClient, api)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+		return nil, err
 	}
 
-	nodePools, err := dynamicClient.Resource(nodePoolGVR).List(context.TODO(), metav1.ListOptions{})
+	nodeClaims, err := listNodeClaims(context.TODO(), dynamicClient, api)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list NodePools: %w", err)
+		return nil, err
+	}
+
+	return parseNodePools(profile, region, clusterName, nodePools, nodeClaims), nil
+}
+
+// parseNodePools extracts the settings and the utilization of the NodePools,
+// counting their NodeClaims by capacity type
+func parseNodePools(profile, region, clusterName string, nodePools, nodeClaims []unstructured.Unstructured) []data.KarpenterNodePoolInfo {
+	nodesByPool := make(map[string]map[string]int)
+	for i := range nodeClaims {
+		pool := nodePoolOf(&nodeClaims[i])
+		if nodesByPool[pool] == nil {
+			nodesByPool[pool] = make(map[string]int)
+		}
+		capacityType := nodeClaims[i].GetLabels()[capacityTypeLabel]
+		if capacityType == "" {
+			capacityType = "unknown"
+		}
+		nodesByPool[pool][capacityType]++
 	}
 
 	var result []data.KarpenterNodePoolInfo
 
-	for _, np := range nodePools.Items {
+	for _, np := range nodePools {
 		info := data.KarpenterNodePoolInfo{
-			Profile:     profile,
-			Region:      region,
-			ClusterName: clusterName,
-			Name:        np.GetName(),
+			Profile:             profile,
+			Region:              region,
+			ClusterName:         clusterName,
+			Name:                np.GetName(),
+			CPUPercent:          -1,
+			MemoryPercent:       -1,
+			NodesByCapacityType: nodesByPool[np.GetName()],
 		}
+		for _, count := range info.NodesByCapacityType {
+			info.NodeCount += count
+		}
+
+		// Resources provisioned so far
+		info.CPUUsage, _, _ = unstructured.NestedString(np.Object, "status", "resources", "cpu")
+		info.MemoryUsage, _, _ = unstructured.NestedString(np.Object, "status", "resources", "memory")
 
 		// Extract spec fields
 		spec, found, err := unstructured.NestedMap(np.Object, "spec")
@@ -66,6 +93,15 @@ func GetNodePools(profile, region, clusterName string) ([]data.KarpenterNodePool
 							key, _ := reqMap["key"].(string)
 							values, _ := reqMap["values"].([]interface{})
 
+							requirement := data.KarpenterRequirement{Key: key}
+							requirement.Operator, _ = reqMap["operator"].(string)
+							for _, v := range values {
+								if str, ok := v.(string); ok {
+									requirement.Values = append(requirement.Values, str)
+								}
+							}
+							info.Requirements = append(info.Requirements, requirement)
+
 							switch key {
 							case "node.kubernetes.io/instance-type":
 								for _, v := range values {
@@ -92,24 +128,38 @@ func GetNodePools(profile, region, clusterName string) ([]data.KarpenterNodePool
 			}
 		}
 
+		// Labels and taints nodes are created with
+		if labels, ok, _ := unstructured.NestedStringMap(spec, "template", "metadata", "labels"); ok {
+			info.Labels = labels
+		}
+		if taints, ok, _ := unstructured.NestedSlice(spec, "template", "spec", "taints"); ok {
+			for _, t := range taints {
+				if taintMap, ok := t.(map[string]interface{}); ok {
+					taint := data.KarpenterTaint{}
+					taint.Key, _ = taintMap["key"].(string)
+					taint.Value, _ = taintMap["value"].(string)
+					taint.Effect, _ = taintMap["effect"].(string)
+					info.Taints = append(info.Taints, taint)
+				}
+			}
+		}
+
 		// Limits
 		if limits, ok := spec["limits"].(map[string]interface{}); ok {
-			if cpu, ok := limits["cpu"].(string); ok {
-				info.CPULimit = cpu
-			}
-			if memory, ok := limits["memory"].(string); ok {
-				info.MemoryLimit = memory
-			}
+			info.CPULimit = quantityString(limits["cpu"])
+			info.MemoryLimit = quantityString(limits["memory"])
 		}
+		info.CPUPercent = limitPercent(info.CPUUsage, info.CPULimit)
+		info.MemoryPercent = limitPercent(info.MemoryUsage, info.MemoryLimit)
 
 		// Disruption settings
+		if expireAfter, ok, _ := unstructured.NestedString(spec, "template", "spec", "expireAfter"); ok {
+			info.ExpireAfter = expireAfter
+		}
 		if disruption, ok := spec["disruption"].(map[string]interface{}); ok {
 			if consolidation, ok := disruption["consolidationPolicy"].(string); ok {
 				info.ConsolidationMode = consolidation
 			}
-			if expireAfter, ok := disruption["expireAfter"].(string); ok {
-				info.ExpireAfter = expireAfter
-			}
 		}
 
 		// Weight
@@ -120,5 +170,39 @@ func GetNodePools(profile, region, clusterName string) ([]data.KarpenterNodePool
 		result = append(result, info)
 	}
 
-	return result, nil
+	return result
+}
+
+// quantityString returns a limit as a string; small CPU limits may be
+// written as plain numbers
+func quantityString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int64:
+		return fmt.Sprintf("%d", v)
+	case float64:
+		return fmt.Sprintf("%g", v)
+	}
+	return ""
+}
+
+// limitPercent returns the percentage of limit consumed by usage, or -1 when
+// either is missing or cannot be parsed
+func limitPercent(usage, limit string) float64 {
+	if limit == "" {
+		return -1
+	}
+	limitQuantity, err := resource.ParseQuantity(limit)
+	if err != nil || limitQuantity.IsZero() {
+		return -1
+	}
+	usageQuantity := resource.Quantity{}
+	if usage != "" {
+		usageQuantity, err = resource.ParseQuantity(usage)
+		if err != nil {
+			return -1
+		}
+	}
+	return usageQuantity.AsApproximateFloat64() / limitQuantity.AsApproximateFloat64() * 100
 }
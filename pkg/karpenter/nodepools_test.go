@@ -0,0 +1,51 @@
+package karpenter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestParseNodePools(t *testing.T) {
+	general := newKarpenterObject("karpenter.sh/v1", "NodePool", "general", nil, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{"nodeClassRef": map[string]interface{}{"name": "default"}},
+			},
+			"limits": map[string]interface{}{"cpu": int64(100), "memory": "400Gi"},
+		},
+		"status": map[string]interface{}{
+			"resources": map[string]interface{}{"cpu": "25", "memory": "100Gi"},
+		},
+	})
+	unlimited := newKarpenterObject("karpenter.sh/v1", "NodePool", "unlimited", nil, map[string]interface{}{
+		"spec": map[string]interface{}{},
+	})
+
+	claim := func(name, pool, capacityType string) unstructured.Unstructured {
+		nc := newKarpenterObject("karpenter.sh/v1", "NodeClaim", name, nil, map[string]interface{}{
+			// The EC2NodeClass name must not be taken for the NodePool
+			"spec": map[string]interface{}{"nodeClassRef": map[string]interface{}{"name": "default"}},
+		})
+		nc.SetLabels(map[string]string{nodePoolLabel: pool, capacityTypeLabel: capacityType})
+		return *nc
+	}
+
+	nodePools := parseNodePools("prod", "eu-west-1", "a",
+		[]unstructured.Unstructured{*general, *unlimited},
+		[]unstructured.Unstructured{claim("g-1", "general", "spot"), claim("g-2", "general", "spot"), claim("g-3", "general", "on-demand")},
+	)
+	require.Len(t, nodePools, 2)
+
+	assert.Equal(t, "default", nodePools[0].NodeClassName)
+	assert.Equal(t, "100", nodePools[0].CPULimit)
+	assert.Equal(t, 25.0, nodePools[0].CPUPercent)
+	assert.Equal(t, 25.0, nodePools[0].MemoryPercent)
+	assert.Equal(t, 3, nodePools[0].NodeCount)
+	assert.Equal(t, map[string]int{"spot": 2, "on-demand": 1}, nodePools[0].NodesByCapacityType)
+
+	assert.Equal(t, -1.0, nodePools[1].CPUPercent, "no limit")
+	assert.Equal(t, 0, nodePools[1].NodeCount)
+}
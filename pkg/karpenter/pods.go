@@ -0,0 +1,125 @@
+package karpenter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// podTemplatePaths is where each workload kind keeps its pod template
+var podTemplatePaths = map[string][]string{
+	"Deployment":  {"spec", "template"},
+	"StatefulSet": {"spec", "template"},
+	"DaemonSet":   {"spec", "template"},
+	"ReplicaSet":  {"spec", "template"},
+	"Job":         {"spec", "template"},
+	"CronJob":     {"spec", "jobTemplate", "spec", "template"},
+	"PodTemplate": {"template"},
+}
+
+func GetPod(namespace, name string) (*corev1.Pod, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", clientcmd.RecommendedHomeFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build kubeconfig: %w", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	pod, err := clientset.CoreV1().Pods(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod %s/%s: %w", namespace, name, err)
+	}
+
+	return pod, nil
+}
+
+// LoadPodManifest reads a Pod, or the pod template of a workload such as a
+// Deployment or a Job, from a YAML or JSON file. Multi-document files are
+// read until the first document with a pod spec.
+func LoadPodManifest(path string) (*corev1.Pod, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	return decodePod(file)
+}
+
+func decodePod(reader io.Reader) (*corev1.Pod, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(reader, 4096)
+	for {
+		raw := runtime.RawExtension{}
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("no Pod or workload with a pod template found")
+			}
+			return nil, fmt.Errorf("failed to decode manifest: %w", err)
+		}
+		if len(raw.Raw) == 0 || string(raw.Raw) == "null" {
+			continue
+		}
+
+		// Decoded as the API server would, keeping integers as int64
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(raw.Raw); err != nil {
+			return nil, fmt.Errorf("failed to decode manifest: %w", err)
+		}
+
+		pod, err := podFromObject(obj)
+		if err != nil {
+			return nil, err
+		}
+		if pod != nil {
+			return pod, nil
+		}
+	}
+}
+
+// podFromObject returns the pod an object describes, or nil for kinds
+// without a pod template
+func podFromObject(obj *unstructured.Unstructured) (*corev1.Pod, error) {
+	if obj.GetKind() == "Pod" {
+		pod := &corev1.Pod{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, pod); err != nil {
+			return nil, fmt.Errorf("failed to read Pod %s: %w", obj.GetName(), err)
+		}
+		return pod, nil
+	}
+
+	path, ok := podTemplatePaths[obj.GetKind()]
+	if !ok {
+		return nil, nil
+	}
+	template, found, err := unstructured.NestedMap(obj.Object, path...)
+	if err != nil || !found {
+		return nil, fmt.Errorf("%s %s has no pod template", obj.GetKind(), obj.GetName())
+	}
+
+	podTemplate := &corev1.PodTemplateSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(template, podTemplate); err != nil {
+		return nil, fmt.Errorf("failed to read the pod template of %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+
+	pod := &corev1.Pod{ObjectMeta: podTemplate.ObjectMeta, Spec: podTemplate.Spec}
+	if pod.Name == "" {
+		pod.Name = obj.GetName()
+	}
+	if pod.Namespace == "" {
+		pod.Namespace = obj.GetNamespace()
+	}
+	return pod, nil
+}
@@ -0,0 +1,304 @@
+package karpenter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/jordiprats/kubectl-eks/pkg/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const instanceTypeLabel = "node.kubernetes.io/instance-type"
+
+// lifecycleConditions are the NodeClaim conditions in the order they are
+// expected to happen, used to order conditions that changed at the same time
+var lifecycleConditions = []string{"Launched", "Registered", "Initialized", "Ready", "Drifted", "Disrupted"}
+
+// GetNodeClaimTimeline reconstructs the lifecycle of a NodeClaim from its
+// conditions and the events of the NodeClaim and its node
+func GetNodeClaimTimeline(profile, region, clusterName, name string) ([]data.KarpenterNodeClaimTimelineEntry, error) {
+	dynamicClient, clientset, api, err := newClients()
+	if err != nil {
+		return nil, err
+	}
+
+	timeline, err := analyzeTimeline(context.TODO(), dynamicClient, clientset, api, name)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range timeline {
+		timeline[i].Profile = profile
+		timeline[i].Region = region
+		timeline[i].ClusterName = clusterName
+	}
+
+	return timeline, nil
+}
+
+func analyzeTimeline(ctx context.Context, dynamicClient dynamic.Interface, clientset kubernetes.Interface, api karpenterAPI, name string) ([]data.KarpenterNodeClaimTimelineEntry, error) {
+	nodeClaims, err := listNodeClaims(ctx, dynamicClient, api)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodeClaim *unstructured.Unstructured
+	for i := range nodeClaims {
+		if nodeClaims[i].GetName() == name {
+			nodeClaim = &nodeClaims[i]
+			break
+		}
+	}
+	if nodeClaim == nil {
+		return nil, fmt.Errorf("NodeClaim %s not found", name)
+	}
+
+	// Events of cluster-scoped objects such as NodeClaims and nodes are
+	// recorded in the default namespace
+	events, _, err := k8s.ListEvents(ctx, clientset, metav1.NamespaceDefault)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	return buildTimeline(nodeClaim, events), nil
+}
+
+// buildTimeline sorts the creation, condition transitions, related events and
+// deletion of a NodeClaim by time, with the time elapsed between them
+func buildTimeline(nc *unstructured.Unstructured, events []data.EventInfo) []data.KarpenterNodeClaimTimelineEntry {
+	created := nc.GetCreationTimestamp().Time
+	timeline := []data.KarpenterNodeClaimTimelineEntry{{
+		Time:   created,
+		Source: "Lifecycle",
+		Name:   "Created",
+	}}
+
+	conditions, _, _ := unstructured.NestedSlice(nc.Object, "status", "conditions")
+	conditionEntries := []data.KarpenterNodeClaimTimelineEntry{}
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		transitioned, err := time.Parse(time.RFC3339, fmt.Sprint(condition["lastTransitionTime"]))
+		if err != nil {
+			continue
+		}
+		conditionType, _ := condition["type"].(string)
+		status, _ := condition["status"].(string)
+		message, _ := condition["message"].(string)
+		if message == "" {
+			message, _ = condition["reason"].(string)
+		}
+		conditionEntries = append(conditionEntries, data.KarpenterNodeClaimTimelineEntry{
+			Time:    transitioned,
+			Source:  "Condition",
+			Name:    conditionType,
+			Status:  status,
+			Message: message,
+		})
+	}
+	sort.SliceStable(conditionEntries, func(i, j int) bool {
+		return lifecycleRank(conditionEntries[i].Name) < lifecycleRank(conditionEntries[j].Name)
+	})
+	timeline = append(timeline, conditionEntries...)
+
+	related := map[string]bool{
+		"NodeClaim/" + nc.GetName(): true,
+		// v1alpha5 records its events on the Machine
+		"Machine/" + nc.GetName(): true,
+	}
+	if nodeName, _, _ := unstructured.NestedString(nc.Object, "status", "nodeName"); nodeName != "" {
+		related["Node/"+nodeName] = true
+	}
+	for _, event := range events {
+		// Node names are reused, older events belong to a previous node
+		if !related[event.Object] || event.FirstSeen.Before(created) {
+			continue
+		}
+		message := event.Message
+		if event.Count > 1 {
+			message = fmt.Sprintf("%s (x%d)", message, event.Count)
+		}
+		timeline = append(timeline, data.KarpenterNodeClaimTimelineEntry{
+			Time:    event.FirstSeen,
+			Source:  "Event",
+			Name:    event.Reason,
+			Status:  event.Type,
+			Message: message,
+		})
+	}
+
+	if deleted := nc.GetDeletionTimestamp(); deleted != nil {
+		timeline = append(timeline, data.KarpenterNodeClaimTimelineEntry{
+			Time:   deleted.Time,
+			Source: "Lifecycle",
+			Name:   "Deleting",
+		})
+	}
+
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].Time.Before(timeline[j].Time)
+	})
+
+	for i := range timeline {
+		timeline[i].NodeClaimName = nc.GetName()
+		timeline[i].SinceCreated = timeline[i].Time.Sub(created)
+		if i > 0 {
+			timeline[i].SincePrevious = timeline[i].Time.Sub(timeline[i-1].Time)
+		}
+	}
+
+	return timeline
+}
+
+func lifecycleRank(conditionType string) int {
+	for i, t := range lifecycleConditions {
+		if t == conditionType {
+			return i
+		}
+	}
+	return len(lifecycleConditions)
+}
+
+// GetLaunchStats aggregates the launch-to-ready time of the NodeClaims per
+// NodePool and instance type
+func GetLaunchStats(profile, region, clusterName string) ([]data.KarpenterLaunchStats, error) {
+	dynamicClient, _, api, err := newClients()
+	if err != nil {
+		return nil, err
+	}
+
+	nodeClaims, err := listNodeClaims(context.TODO(), dynamicClient, api)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := launchStats(nodeClaims)
+	for i := range stats {
+		stats[i].Profile = profile
+		stats[i].Region = region
+		stats[i].ClusterName = clusterName
+	}
+
+	return stats, nil
+}
+
+// readyFlapTolerance is how long after Initialized the Ready condition of a
+// NodeClaim may change and still be its first transition to Ready
+const readyFlapTolerance = time.Minute
+
+// launchStats computes the p50, p95 and maximum time from the Launched
+// condition of the NodeClaims, when the instance was created, to their Ready
+// condition. NodeClaims not Ready yet are counted as pending.
+func launchStats(nodeClaims []unstructured.Unstructured) []data.KarpenterLaunchStats {
+	type statsKey struct {
+		nodePool     string
+		instanceType string
+	}
+
+	durations := make(map[statsKey][]time.Duration)
+	pending := make(map[statsKey]int)
+	for i := range nodeClaims {
+		nc := &nodeClaims[i]
+		key := statsKey{nodePoolOf(nc), instanceTypeOf(nc)}
+
+		if condition := findCondition(nc.Object, "Ready"); condition == nil || condition["status"] != "True" {
+			if nc.GetDeletionTimestamp() == nil {
+				pending[key]++
+			}
+			continue
+		}
+		if ready, ok := launchToReady(nc); ok {
+			durations[key] = append(durations[key], ready)
+		}
+	}
+
+	keys := make(map[statsKey]bool)
+	for key := range durations {
+		keys[key] = true
+	}
+	for key := range pending {
+		keys[key] = true
+	}
+
+	result := []data.KarpenterLaunchStats{}
+	for key := range keys {
+		sorted := durations[key]
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		stats := data.KarpenterLaunchStats{
+			NodePoolName: key.nodePool,
+			InstanceType: key.instanceType,
+			Count:        len(sorted),
+			Pending:      pending[key],
+		}
+		if len(sorted) > 0 {
+			stats.P50 = percentile(sorted, 50)
+			stats.P95 = percentile(sorted, 95)
+			stats.Max = sorted[len(sorted)-1]
+		}
+		result = append(result, stats)
+	}
+
+	return result
+}
+
+// launchToReady returns the time from the Launched condition of a NodeClaim
+// to its Ready condition. Ready records its last transition only, so
+// NodeClaims whose Ready condition changed again well after Initialized (a
+// node that went NotReady and recovered) are left out.
+func launchToReady(nc *unstructured.Unstructured) (time.Duration, bool) {
+	launched, ok := conditionTransition(nc, "Launched")
+	if !ok {
+		return 0, false
+	}
+	ready, ok := conditionTransition(nc, "Ready")
+	if !ok {
+		return 0, false
+	}
+	if initialized, ok := conditionTransition(nc, "Initialized"); ok && ready.After(initialized.Add(readyFlapTolerance)) {
+		return 0, false
+	}
+	return ready.Sub(launched), true
+}
+
+// conditionTransition returns when a condition of a NodeClaim last changed,
+// if it is true
+func conditionTransition(nc *unstructured.Unstructured, conditionType string) (time.Time, bool) {
+	condition := findCondition(nc.Object, conditionType)
+	if condition == nil || condition["status"] != "True" {
+		return time.Time{}, false
+	}
+	transitioned, err := time.Parse(time.RFC3339, fmt.Sprint(condition["lastTransitionTime"]))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return transitioned, true
+}
+
+// percentile returns the nearest-rank percentile of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// instanceTypeOf returns the instance type of a NodeClaim from its label,
+// which v1 sets, or from the status older versions filled
+func instanceTypeOf(nc *unstructured.Unstructured) string {
+	if instanceType := nc.GetLabels()[instanceTypeLabel]; instanceType != "" {
+		return instanceType
+	}
+	instanceType, _, _ := unstructured.NestedString(nc.Object, "status", "instanceType")
+	return instanceType
+}
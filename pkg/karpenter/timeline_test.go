@@ -0,0 +1,130 @@
+package karpenter
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var timelineStart = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func newLaunchedNodeClaim(name, nodePool, instanceType string, readyAfter time.Duration) *unstructured.Unstructured {
+	conditions := []interface{}{
+		map[string]interface{}{"type": "Launched", "status": "True", "lastTransitionTime": timelineStart.Add(5 * time.Second).Format(time.RFC3339)},
+	}
+	if readyAfter > 0 {
+		conditions = append(conditions,
+			map[string]interface{}{"type": "Ready", "status": "True", "lastTransitionTime": timelineStart.Add(readyAfter).Format(time.RFC3339)},
+			// Initialized changes together with Ready, it is listed before it
+			map[string]interface{}{"type": "Initialized", "status": "True", "lastTransitionTime": timelineStart.Add(readyAfter).Format(time.RFC3339)},
+		)
+	}
+
+	nc := newKarpenterObject("karpenter.sh/v1", "NodeClaim", name, nil, map[string]interface{}{
+		"status": map[string]interface{}{
+			"nodeName":   "node-" + name,
+			"conditions": conditions,
+		},
+	})
+	nc.SetLabels(map[string]string{nodePoolLabel: nodePool, instanceTypeLabel: instanceType})
+	nc.SetCreationTimestamp(metav1.NewTime(timelineStart))
+	return nc
+}
+
+func newTimelineEvent(kind, name, reason string, at time.Time) *eventsv1.Event {
+	return &eventsv1.Event{
+		ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: fmt.Sprintf("%s.%s", name, reason)},
+		EventTime:  metav1.NewMicroTime(at),
+		Regarding:  corev1.ObjectReference{Kind: kind, Name: name},
+		Reason:     reason,
+		Type:       "Normal",
+		Note:       reason + " " + name,
+	}
+}
+
+func TestAnalyzeTimeline(t *testing.T) {
+	nc := newLaunchedNodeClaim("default-abc", "default", "m5.large", 90*time.Second)
+	dynamicClient := newDriftTestDynamicClient(nc, newLaunchedNodeClaim("default-xyz", "default", "m5.large", time.Minute))
+
+	clientset := fake.NewClientset(
+		newTimelineEvent("NodeClaim", "default-abc", "Launched", timelineStart.Add(6*time.Second)),
+		newTimelineEvent("Node", "node-default-abc", "RegisteredNode", timelineStart.Add(40*time.Second)),
+		// A previous node with the same name
+		newTimelineEvent("Node", "node-default-abc", "RemovingNode", timelineStart.Add(-time.Hour)),
+		newTimelineEvent("NodeClaim", "default-xyz", "Launched", timelineStart.Add(time.Second)),
+	)
+
+	timeline, err := analyzeTimeline(context.Background(), dynamicClient, clientset, karpenterV1, "default-abc")
+	require.NoError(t, err)
+
+	steps := []string{}
+	for _, entry := range timeline {
+		steps = append(steps, entry.Source+"/"+entry.Name)
+		assert.Equal(t, "default-abc", entry.NodeClaimName)
+	}
+	assert.Equal(t, []string{
+		"Lifecycle/Created",
+		"Condition/Launched",
+		"Event/Launched",
+		"Event/RegisteredNode",
+		"Condition/Initialized",
+		"Condition/Ready",
+	}, steps)
+
+	assert.Equal(t, 40*time.Second, timeline[3].SinceCreated)
+	assert.Equal(t, 34*time.Second, timeline[3].SincePrevious)
+	assert.Equal(t, 90*time.Second, timeline[5].SinceCreated)
+	assert.Equal(t, time.Duration(0), timeline[5].SincePrevious)
+
+	_, err = analyzeTimeline(context.Background(), dynamicClient, clientset, karpenterV1, "missing")
+	assert.ErrorContains(t, err, "NodeClaim missing not found")
+}
+
+func TestLaunchStats(t *testing.T) {
+	nodeClaims := []unstructured.Unstructured{}
+	for i := 1; i <= 20; i++ {
+		nodeClaims = append(nodeClaims, *newLaunchedNodeClaim(fmt.Sprintf("default-%d", i), "default", "m5.large", time.Duration(i)*10*time.Second))
+	}
+	// Ready flapped hours after the node was initialized
+	flapped := newLaunchedNodeClaim("default-flapped", "default", "m5.large", 30*time.Second)
+	conditions, _, _ := unstructured.NestedSlice(flapped.Object, "status", "conditions")
+	conditions[1].(map[string]interface{})["lastTransitionTime"] = timelineStart.Add(2 * time.Hour).Format(time.RFC3339)
+	require.NoError(t, unstructured.SetNestedSlice(flapped.Object, conditions, "status", "conditions"))
+
+	nodeClaims = append(nodeClaims,
+		*newLaunchedNodeClaim("default-pending", "default", "m5.large", 0),
+		*flapped,
+		*newLaunchedNodeClaim("gpu-1", "gpu", "g5.xlarge", 4*time.Minute),
+	)
+
+	stats := launchStats(nodeClaims)
+	require.Len(t, stats, 2)
+
+	byPool := map[string]int{}
+	for i, s := range stats {
+		byPool[s.NodePoolName] = i
+	}
+
+	defaultPool := stats[byPool["default"]]
+	assert.Equal(t, "m5.large", defaultPool.InstanceType)
+	assert.Equal(t, 20, defaultPool.Count, "NodeClaims whose Ready condition flapped are left out")
+	assert.Equal(t, 1, defaultPool.Pending)
+	// Measured from Launched, 5s after creation
+	assert.Equal(t, 95*time.Second, defaultPool.P50)
+	assert.Equal(t, 185*time.Second, defaultPool.P95)
+	assert.Equal(t, 195*time.Second, defaultPool.Max)
+
+	gpu := stats[byPool["gpu"]]
+	assert.Equal(t, 1, gpu.Count)
+	assert.Equal(t, 235*time.Second, gpu.P50)
+	assert.Equal(t, 235*time.Second, gpu.P95)
+}
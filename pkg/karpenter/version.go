@@ -0,0 +1,77 @@
+package karpenter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const karpenterDeploymentSelector = "app.kubernetes.io/name=karpenter"
+
+func GetVersion(profile, region, clusterName string) (data.KarpenterVersionInfo, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", clientcmd.RecommendedHomeFile)
+	if err != nil {
+		return data.KarpenterVersionInfo{}, fmt.Errorf("failed to build kubeconfig: %w", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return data.KarpenterVersionInfo{}, fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	info, err := analyzeVersion(context.TODO(), clientset)
+	if err != nil {
+		return data.KarpenterVersionInfo{}, err
+	}
+
+	info.Profile = profile
+	info.Region = region
+	info.ClusterName = clusterName
+
+	return info, nil
+}
+
+// analyzeVersion finds the Karpenter APIs the cluster serves and the version
+// of the controller running in it
+func analyzeVersion(ctx context.Context, clientset kubernetes.Interface) (data.KarpenterVersionInfo, error) {
+	served, err := discoverAPIs(clientset.Discovery())
+	if err != nil {
+		return data.KarpenterVersionInfo{}, err
+	}
+
+	info := data.KarpenterVersionInfo{}
+	for _, api := range served {
+		info.ServedAPIs = append(info.ServedAPIs, api.Version)
+	}
+	if len(served) > 0 {
+		info.APIVersion = served[0].Version
+		info.Resources = strings.Join([]string{served[0].NodePools.Resource, served[0].NodeClaims.Resource, served[0].NodeClasses.Resource}, "/")
+	}
+
+	// The controller may run in any namespace; it is fine not to find it,
+	// e.g. when it runs on Fargate in a namespace we cannot list
+	deployments, err := clientset.AppsV1().Deployments("").List(ctx, metav1.ListOptions{LabelSelector: karpenterDeploymentSelector})
+	if err == nil && len(deployments.Items) > 0 {
+		for i, container := range deployments.Items[0].Spec.Template.Spec.Containers {
+			if i == 0 || container.Name == "controller" {
+				info.ControllerVersion = imageTag(container.Image)
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// imageTag returns the tag of a container image, without its digest
+func imageTag(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[i+1:]
+	}
+	return ""
+}
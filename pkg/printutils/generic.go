@@ -1,9 +1,11 @@
 package printutils
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -68,8 +70,22 @@ func PrintMultiGetPods(noHeaders bool, podList ...k8s.K8SClusterPodList) {
 	}
 }
 
-// print k8s stats in a kubectl-style table format
-func PrintK8SStats(noHeaders bool, statsList ...k8s.K8Sstats) {
+// PrintK8SStats prints the stats of each cluster and, when there is more than
+// one, a TOTAL row. -o wide adds workloads, QoS classes, pods without
+// requests/limits, Fargate/EC2 pods, PVCs and services by type; -o json prints
+// the raw stats.
+func PrintK8SStats(noHeaders bool, output string, statsList ...k8s.K8Sstats) {
+	if output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(statsList); err != nil {
+			fmt.Fprintf(os.Stderr, "Error printing JSON: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	wide := output == "wide"
+
 	// Create a table printer
 	printer := printers.NewTablePrinter(printers.PrintOptions{NoHeaders: noHeaders})
 
@@ -89,24 +105,79 @@ func PrintK8SStats(noHeaders bool, statsList ...k8s.K8Sstats) {
 			{Name: "PODS WITH RESTARTS", Type: "number"},
 		},
 	}
+	if wide {
+		table.ColumnDefinitions = append(table.ColumnDefinitions,
+			v1.TableColumnDefinition{Name: "DEPLOYMENTS", Type: "number"},
+			v1.TableColumnDefinition{Name: "STATEFULSETS", Type: "number"},
+			v1.TableColumnDefinition{Name: "DAEMONSETS", Type: "number"},
+			v1.TableColumnDefinition{Name: "JOBS", Type: "number"},
+			v1.TableColumnDefinition{Name: "CRONJOBS", Type: "number"},
+			v1.TableColumnDefinition{Name: "GUARANTEED", Type: "number"},
+			v1.TableColumnDefinition{Name: "BURSTABLE", Type: "number"},
+			v1.TableColumnDefinition{Name: "BESTEFFORT", Type: "number"},
+			v1.TableColumnDefinition{Name: "NO REQUESTS", Type: "number"},
+			v1.TableColumnDefinition{Name: "NO LIMITS", Type: "number"},
+			v1.TableColumnDefinition{Name: "FARGATE PODS", Type: "number"},
+			v1.TableColumnDefinition{Name: "EC2 PODS", Type: "number"},
+			v1.TableColumnDefinition{Name: "PVCS", Type: "number"},
+			v1.TableColumnDefinition{Name: "PVC CAPACITY", Type: "string"},
+			v1.TableColumnDefinition{Name: "CLUSTERIP", Type: "number"},
+			v1.TableColumnDefinition{Name: "NODEPORT", Type: "number"},
+			v1.TableColumnDefinition{Name: "LOADBALANCER", Type: "number"},
+			v1.TableColumnDefinition{Name: "EXTERNALNAME", Type: "number"},
+		)
+	}
+	table.ColumnDefinitions = append(table.ColumnDefinitions, v1.TableColumnDefinition{Name: "ERRORS", Type: "string"})
+
+	rows := statsList
+	if len(statsList) > 1 {
+		rows = append(append([]k8s.K8Sstats{}, statsList...), k8s.SumK8sStats(statsList))
+	}
 
 	// Populate rows with data from the variadic K8Sstats
-	for _, stats := range statsList {
-		table.Rows = append(table.Rows, v1.TableRow{
-			Cells: []interface{}{
-				stats.AWSProfile,
-				stats.Region,
-				stats.ClusterName,
-				stats.Arn,
-				stats.Version,
-				stats.NamespaceCount,
-				stats.PodCount,
-				stats.NodeCount,
-				stats.NodesNotReady,
-				stats.PodsNotRunning,
-				stats.PodsWithRestartsCount,
-			},
-		})
+	for _, stats := range rows {
+		cells := []interface{}{
+			stats.AWSProfile,
+			stats.Region,
+			stats.ClusterName,
+			stats.Arn,
+			stats.Version,
+			stats.NamespaceCount,
+			stats.PodCount,
+			stats.NodeCount,
+			stats.NodesNotReady,
+			stats.PodsNotRunning,
+			stats.PodsWithRestartsCount,
+		}
+		if wide {
+			cells = append(cells,
+				stats.Deployments,
+				stats.StatefulSets,
+				stats.DaemonSets,
+				stats.Jobs,
+				stats.CronJobs,
+				stats.PodsGuaranteed,
+				stats.PodsBurstable,
+				stats.PodsBestEffort,
+				stats.PodsWithoutRequests,
+				stats.PodsWithoutLimits,
+				stats.FargatePods,
+				stats.EC2Pods,
+				stats.PVCCount,
+				resource.NewQuantity(stats.PVCCapacityBytes, resource.BinarySI).String(),
+				stats.ServicesClusterIP,
+				stats.ServicesNodePort,
+				stats.ServicesLoadBalancer,
+				stats.ServicesExternalName,
+			)
+		}
+		errors := "-"
+		if len(stats.Errors) > 0 {
+			errors = strings.Join(stats.Errors, "; ")
+		}
+		cells = append(cells, errors)
+
+		table.Rows = append(table.Rows, v1.TableRow{Cells: cells})
 	}
 
 	// Print the table
@@ -481,7 +552,7 @@ func PrintAMIs(noHeaders bool, amiInfos ...data.AMIInfo) {
 	}
 }
 
-func PrintNodeGroup(noHeaders bool, ngInfo ...eks.EKSNodeGroupInfo) {
+func PrintNodeGroup(noHeaders bool, wide bool, ngInfo ...eks.EKSNodeGroupInfo) {
 	// Sort the clusterInfos by ClusterName (you can customize the field for sorting)
 	sort.Slice(ngInfo, func(i, j int) bool {
 		return ngInfo[i].Name < ngInfo[j].Name
@@ -490,44 +561,225 @@ func PrintNodeGroup(noHeaders bool, ngInfo ...eks.EKSNodeGroupInfo) {
 	// Create a table printer
 	printer := printers.NewTablePrinter(printers.PrintOptions{NoHeaders: noHeaders})
 
+	columns := []v1.TableColumnDefinition{
+		{Name: "NAME", Type: "string"},
+		{Name: "CAPACITY TYPE", Type: "string"},
+		{Name: "RELEASE VERSION", Type: "string"},
+		{Name: "LAUNCH TEMPLATE", Type: "string"},
+		{Name: "INSTANCE TYPE", Type: "string"},
+		{Name: "DESIRED CAPACITY", Type: "string"},
+		{Name: "MAX CAPACITY", Type: "string"},
+		{Name: "MIN CAPACITY", Type: "string"},
+		{Name: "VERSION", Type: "string"},
+		{Name: "STATUS", Type: "string"},
+		{Name: "HEALTH", Type: "string"},
+	}
+	if wide {
+		columns = append(columns,
+			v1.TableColumnDefinition{Name: "AMI TYPE", Type: "string"},
+			v1.TableColumnDefinition{Name: "LATEST RELEASE", Type: "string"},
+			v1.TableColumnDefinition{Name: "RELEASES BEHIND", Type: "string"},
+			v1.TableColumnDefinition{Name: "CONTROL PLANE", Type: "string"},
+		)
+	}
+
 	// Create a Table object
+	table := &v1.Table{ColumnDefinitions: columns}
+
+	// Populate rows with data from the variadic ClusterInfo
+	for _, eachNG := range ngInfo {
+		cells := []interface{}{
+			eachNG.Name,
+			eachNG.CapacityType,
+			eachNG.ReleaseVersion,
+			eachNG.LaunchTemplate,
+			eachNG.InstanceType,
+			eachNG.DesiredCapacity,
+			eachNG.MaxCapacity,
+			eachNG.MinCapacity,
+			eachNG.Version,
+			eachNG.Status,
+			formatNodeGroupHealth(eachNG),
+		}
+		if wide {
+			latest := eachNG.LatestReleaseVersion
+			if latest == "" {
+				latest = "-"
+			}
+			behind := "Unknown"
+			if eachNG.ReleasesBehind >= 0 {
+				behind = strconv.Itoa(eachNG.ReleasesBehind)
+			}
+			cells = append(cells, eachNG.AMIType, latest, behind, formatControlPlaneLag(eachNG))
+		}
+
+		table.Rows = append(table.Rows, v1.TableRow{Cells: cells})
+	}
+
+	// Print the table
+	err := printer.PrintObj(table, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error printing table: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// formatNodeGroupHealth returns Healthy, or the distinct codes of the health
+// issues of the node group
+func formatNodeGroupHealth(ng eks.EKSNodeGroupInfo) string {
+	if len(ng.HealthIssues) == 0 {
+		return "Healthy"
+	}
+
+	seen := make(map[string]bool)
+	codes := []string{}
+	for _, issue := range ng.HealthIssues {
+		if !seen[issue.Code] {
+			seen[issue.Code] = true
+			codes = append(codes, issue.Code)
+		}
+	}
+	sort.Strings(codes)
+
+	return strings.Join(codes, ",")
+}
+
+// PrintNodeGroupDetails describes a node group, one attribute per row. Health
+// issues, labels and taints get a row each.
+func PrintNodeGroupDetails(noHeaders bool, ng eks.EKSNodeGroupInfo) {
+	printer := printers.NewTablePrinter(printers.PrintOptions{NoHeaders: noHeaders})
+
 	table := &v1.Table{
 		ColumnDefinitions: []v1.TableColumnDefinition{
-			{Name: "NAME", Type: "string"},
-			{Name: "CAPACITY TYPE", Type: "string"},
-			{Name: "RELEASE VERSION", Type: "string"},
-			{Name: "LAUNCH TEMPLATE", Type: "string"},
-			{Name: "INSTANCE TYPE", Type: "string"},
-			{Name: "DESIRED CAPACITY", Type: "string"},
-			{Name: "MAX CAPACITY", Type: "string"},
-			{Name: "MIN CAPACITY", Type: "string"},
-			{Name: "VERSION", Type: "string"},
-			{Name: "STATUS", Type: "string"},
+			{Name: "ATTRIBUTE", Type: "string"},
+			{Name: "VALUE", Type: "string"},
 		},
 	}
 
-	// Populate rows with data from the variadic ClusterInfo
-	for _, eachNG := range ngInfo {
-		table.Rows = append(table.Rows, v1.TableRow{
-			Cells: []interface{}{
-				eachNG.Name,
-				eachNG.CapacityType,
-				eachNG.ReleaseVersion,
-				eachNG.LaunchTemplate,
-				eachNG.InstanceType,
-				eachNG.DesiredCapacity,
-				eachNG.MaxCapacity,
-				eachNG.MinCapacity,
-				eachNG.Version,
-				eachNG.Status,
-			},
-		})
+	orDash := func(value string) string {
+		if value == "" {
+			return "-"
+		}
+		return value
+	}
+	joinOrDash := func(values []string) string {
+		return orDash(strings.Join(values, ", "))
+	}
+	addRow := func(attribute, value string) {
+		table.Rows = append(table.Rows, v1.TableRow{Cells: []interface{}{attribute, value}})
 	}
 
-	// Print the table
+	addRow("Name", ng.Name)
+	addRow("ARN", orDash(ng.Arn))
+	addRow("Status", ng.Status)
+	addRow("Health", formatNodeGroupHealth(ng))
+	for _, issue := range ng.HealthIssues {
+		value := issue.Code + ": " + issue.Message
+		if len(issue.ResourceIDs) > 0 {
+			value += " (" + strings.Join(issue.ResourceIDs, ", ") + ")"
+		}
+		addRow("Health issue", value)
+	}
+	addRow("Version", ng.Version)
+	addRow("Release version", orDash(ng.ReleaseVersion))
+	addRow("AMI type", orDash(ng.AMIType))
+	addRow("Capacity type", orDash(ng.CapacityType))
+	addRow("Instance types", joinOrDash(ng.InstanceTypes))
+	diskSize := "-"
+	if ng.DiskSize > 0 {
+		diskSize = fmt.Sprintf("%d GiB", ng.DiskSize)
+	}
+	addRow("Disk size", diskSize)
+
+	addRow("Desired size", strconv.FormatInt(ng.DesiredCapacity, 10))
+	addRow("Min size", strconv.FormatInt(ng.MinCapacity, 10))
+	addRow("Max size", strconv.FormatInt(ng.MaxCapacity, 10))
+	nodes := "Unknown"
+	if ng.Nodes >= 0 {
+		nodes = fmt.Sprintf("%d (%d ready)", ng.Nodes, ng.ReadyNodes)
+	}
+	addRow("Nodes", nodes)
+	addRow("Max unavailable", orDash(ng.MaxUnavailable))
+
+	launchTemplate := "-"
+	if ng.LaunchTemplate != "" {
+		launchTemplate = fmt.Sprintf("%s (version %s)", ng.LaunchTemplate, ng.LaunchTemplateVersion)
+	}
+	addRow("Launch template", launchTemplate)
+	addRow("Auto Scaling groups", joinOrDash(ng.AutoScalingGroups))
+	addRow("Subnets", joinOrDash(ng.Subnets))
+	addRow("Node role", orDash(ng.NodeRole))
+	remoteAccess := "-"
+	if ng.RemoteAccessKey != "" {
+		remoteAccess = ng.RemoteAccessKey
+		if len(ng.RemoteAccessGroups) > 0 {
+			remoteAccess += " from " + strings.Join(ng.RemoteAccessGroups, ", ")
+		}
+	}
+	addRow("Remote access", remoteAccess)
+
+	labelKeys := make([]string, 0, len(ng.Labels))
+	for key := range ng.Labels {
+		labelKeys = append(labelKeys, key)
+	}
+	sort.Strings(labelKeys)
+	if len(labelKeys) == 0 {
+		addRow("Labels", "-")
+	}
+	for _, key := range labelKeys {
+		addRow("Label", key+"="+ng.Labels[key])
+	}
+
+	if len(ng.Taints) == 0 {
+		addRow("Taints", "-")
+	}
+	for _, taint := range ng.Taints {
+		addRow("Taint", formatNodeGroupTaint(taint))
+	}
+
+	created := "-"
+	if !ng.CreatedAt.IsZero() {
+		created = ng.CreatedAt.Format(time.RFC3339)
+	}
+	addRow("Created", created)
+
 	err := printer.PrintObj(table, os.Stdout)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error printing table: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// formatNodeGroupTaint prints a node group taint as Kubernetes does,
+// turning effects like NO_SCHEDULE into NoSchedule
+func formatNodeGroupTaint(taint eks.NodeGroupTaint) string {
+	effect := ""
+	for _, word := range strings.Split(strings.ToLower(taint.Effect), "_") {
+		if word != "" {
+			effect += strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+
+	value := taint.Key
+	if taint.Value != "" {
+		value += "=" + taint.Value
+	}
+	return value + ":" + effect
+}
+
+// formatControlPlaneLag flags nodegroups running an older Kubernetes minor
+// version than the control plane
+func formatControlPlaneLag(ng eks.EKSNodeGroupInfo) string {
+	switch {
+	case ng.ControlPlaneVersion == "":
+		return "-"
+	case ng.MinorVersionsBehind < 0:
+		return "Unknown (" + ng.ControlPlaneVersion + ")"
+	case ng.AheadOfControlPlane:
+		return "Ahead (" + ng.ControlPlaneVersion + ")"
+	case ng.MinorVersionsBehind == 0:
+		return "Same (" + ng.ControlPlaneVersion + ")"
+	default:
+		return fmt.Sprintf("%d minor behind (%s)", ng.MinorVersionsBehind, ng.ControlPlaneVersion)
+	}
+}
@@ -1,6 +1,10 @@
 package printutils
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/jordiprats/kubectl-eks/pkg/eks"
+)
 
 func TestFormatClusterNodeHealth(t *testing.T) {
 	if got := formatClusterNodeHealth(0, 0, 0, 0); got != "-" {
@@ -11,3 +15,48 @@ func TestFormatClusterNodeHealth(t *testing.T) {
 		t.Fatalf("formatClusterNodeHealth() = %q, want %q", got, "9/10 Ready (NR:1 SD:0)")
 	}
 }
+
+func TestFormatNodeGroupHealth(t *testing.T) {
+	if got := formatNodeGroupHealth(eks.EKSNodeGroupInfo{}); got != "Healthy" {
+		t.Fatalf("formatNodeGroupHealth() = %q, want %q", got, "Healthy")
+	}
+
+	ng := eks.EKSNodeGroupInfo{HealthIssues: []eks.NodeGroupHealthIssue{
+		{Code: "NodeCreationFailure"},
+		{Code: "AsgInstanceLaunchFailures"},
+		{Code: "NodeCreationFailure"},
+	}}
+	if got := formatNodeGroupHealth(ng); got != "AsgInstanceLaunchFailures,NodeCreationFailure" {
+		t.Fatalf("formatNodeGroupHealth() = %q, want %q", got, "AsgInstanceLaunchFailures,NodeCreationFailure")
+	}
+}
+
+func TestFormatNodeGroupTaint(t *testing.T) {
+	taint := eks.NodeGroupTaint{Key: "dedicated", Value: "gpu", Effect: "PREFER_NO_SCHEDULE"}
+	if got := formatNodeGroupTaint(taint); got != "dedicated=gpu:PreferNoSchedule" {
+		t.Fatalf("formatNodeGroupTaint() = %q, want %q", got, "dedicated=gpu:PreferNoSchedule")
+	}
+
+	taint = eks.NodeGroupTaint{Key: "critical", Effect: "NO_EXECUTE"}
+	if got := formatNodeGroupTaint(taint); got != "critical:NoExecute" {
+		t.Fatalf("formatNodeGroupTaint() = %q, want %q", got, "critical:NoExecute")
+	}
+}
+
+func TestFormatControlPlaneLag(t *testing.T) {
+	tests := []struct {
+		ng   eks.EKSNodeGroupInfo
+		want string
+	}{
+		{eks.EKSNodeGroupInfo{}, "-"},
+		{eks.EKSNodeGroupInfo{ControlPlaneVersion: "1.29"}, "Same (1.29)"},
+		{eks.EKSNodeGroupInfo{ControlPlaneVersion: "1.31", MinorVersionsBehind: 2}, "2 minor behind (1.31)"},
+		{eks.EKSNodeGroupInfo{ControlPlaneVersion: "1.29", AheadOfControlPlane: true}, "Ahead (1.29)"},
+		{eks.EKSNodeGroupInfo{ControlPlaneVersion: "1.29", MinorVersionsBehind: -1}, "Unknown (1.29)"},
+	}
+	for _, tt := range tests {
+		if got := formatControlPlaneLag(tt.ng); got != tt.want {
+			t.Fatalf("formatControlPlaneLag() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -2,12 +2,15 @@ package printutils
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/duration"
 	"k8s.io/cli-runtime/pkg/printers"
@@ -40,8 +43,10 @@ func PrintKarpenterNodePools(noHeaders bool, wide bool, nodePools ...data.Karpen
 			{Name: "INSTANCE TYPES", Type: "string"},
 			{Name: "CAPACITY TYPES", Type: "string"},
 			{Name: "ZONES", Type: "string"},
-			{Name: "CPU LIMIT", Type: "string"},
-			{Name: "MEMORY LIMIT", Type: "string"},
+			{Name: "CPU USED/LIMIT", Type: "string"},
+			{Name: "MEMORY USED/LIMIT", Type: "string"},
+			{Name: "NODES", Type: "number"},
+			{Name: "CAPACITY TYPES IN USE", Type: "string"},
 			{Name: "CONSOLIDATION", Type: "string"},
 			{Name: "EXPIRE AFTER", Type: "string"},
 			{Name: "WEIGHT", Type: "number"},
@@ -71,13 +76,17 @@ func PrintKarpenterNodePools(noHeaders bool, wide bool, nodePools ...data.Karpen
 		var cells []interface{}
 		if wide {
 			zones := strings.Join(np.Zones, ",")
-			cpuLimit := np.CPULimit
-			if cpuLimit == "" {
-				cpuLimit = "-"
+			cpu := formatLimitUsage(np.CPUUsage, np.CPULimit, np.CPUPercent, false)
+			memory := formatLimitUsage(np.MemoryUsage, np.MemoryLimit, np.MemoryPercent, true)
+
+			nodesByCapacityType := []string{}
+			for capacityType, count := range np.NodesByCapacityType {
+				nodesByCapacityType = append(nodesByCapacityType, fmt.Sprintf("%s=%d", capacityType, count))
 			}
-			memLimit := np.MemoryLimit
-			if memLimit == "" {
-				memLimit = "-"
+			sort.Strings(nodesByCapacityType)
+			capacityInUse := strings.Join(nodesByCapacityType, ",")
+			if capacityInUse == "" {
+				capacityInUse = "-"
 			}
 			consolidation := np.ConsolidationMode
 			if consolidation == "" {
@@ -97,8 +106,10 @@ func PrintKarpenterNodePools(noHeaders bool, wide bool, nodePools ...data.Karpen
 				instanceTypes,
 				capacityTypes,
 				zones,
-				cpuLimit,
-				memLimit,
+				cpu,
+				memory,
+				np.NodeCount,
+				capacityInUse,
 				consolidation,
 				expireAfter,
 				np.Weight,
@@ -125,6 +136,36 @@ func PrintKarpenterNodePools(noHeaders bool, wide bool, nodePools ...data.Karpen
 	}
 }
 
+// formatLimitUsage renders usage against a limit as "used/limit (pct%)".
+// Memory is shown in Gi so both sides use the same unit.
+func formatLimitUsage(usage, limit string, percent float64, memory bool) string {
+	if usage == "" {
+		usage = "0"
+	}
+	if memory {
+		usage = formatGi(usage)
+		limit = formatGi(limit)
+	}
+	if limit == "" {
+		return usage + "/-"
+	}
+	if percent < 0 {
+		return usage + "/" + limit
+	}
+	return fmt.Sprintf("%s/%s (%.0f%%)", usage, limit, percent)
+}
+
+func formatGi(value string) string {
+	if value == "" {
+		return ""
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return value
+	}
+	return strconv.FormatFloat(math.Round(quantity.AsApproximateFloat64()/(1<<30)*10)/10, 'f', -1, 64) + "Gi"
+}
+
 func PrintKarpenterNodeClaims(noHeaders bool, wide bool, nodeClaims ...data.KarpenterNodeClaimInfo) {
 	sort.Slice(nodeClaims, func(i, j int) bool {
 		if nodeClaims[i].Profile != nodeClaims[j].Profile {
@@ -223,7 +264,7 @@ func PrintKarpenterNodeClaims(noHeaders bool, wide bool, nodeClaims ...data.Karp
 	}
 }
 
-func PrintKarpenterAMIUsage(noHeaders bool, amiUsage ...data.KarpenterAMIUsageInfo) {
+func PrintKarpenterAMIUsage(noHeaders bool, wide bool, amiUsage ...data.KarpenterAMIUsageInfo) {
 	sort.Slice(amiUsage, func(i, j int) bool {
 		if amiUsage[i].Profile != amiUsage[j].Profile {
 			return amiUsage[i].Profile < amiUsage[j].Profile
@@ -234,33 +275,97 @@ func PrintKarpenterAMIUsage(noHeaders bool, amiUsage ...data.KarpenterAMIUsageIn
 		if amiUsage[i].ClusterName != amiUsage[j].ClusterName {
 			return amiUsage[i].ClusterName < amiUsage[j].ClusterName
 		}
-		return amiUsage[i].NodePoolName < amiUsage[j].NodePoolName
+		if amiUsage[i].NodePoolName != amiUsage[j].NodePoolName {
+			return amiUsage[i].NodePoolName < amiUsage[j].NodePoolName
+		}
+		return amiUsage[i].CurrentAMI < amiUsage[j].CurrentAMI
 	})
 
 	printer := printers.NewTablePrinter(printers.PrintOptions{NoHeaders: noHeaders})
 
-	table := &v1.Table{
-		ColumnDefinitions: []v1.TableColumnDefinition{
+	var columns []v1.TableColumnDefinition
+	if wide {
+		columns = []v1.TableColumnDefinition{
 			{Name: "AWS PROFILE", Type: "string"},
 			{Name: "AWS REGION", Type: "string"},
 			{Name: "CLUSTER NAME", Type: "string"},
 			{Name: "NODEPOOL", Type: "string"},
+			{Name: "NODECLASS", Type: "string"},
 			{Name: "CURRENT AMI", Type: "string"},
+			{Name: "AMI NAME", Type: "string"},
+			{Name: "ARCH", Type: "string"},
+			{Name: "CREATED", Type: "string"},
+			{Name: "DEPRECATION", Type: "string"},
+			{Name: "AGE (DAYS)", Type: "string"},
+			{Name: "LATEST", Type: "string"},
 			{Name: "NODE COUNT", Type: "number"},
-		},
+			{Name: "OUTDATED NODES", Type: "string"},
+		}
+	} else {
+		columns = []v1.TableColumnDefinition{
+			{Name: "AWS PROFILE", Type: "string"},
+			{Name: "AWS REGION", Type: "string"},
+			{Name: "CLUSTER NAME", Type: "string"},
+			{Name: "NODEPOOL", Type: "string"},
+			{Name: "CURRENT AMI", Type: "string"},
+			{Name: "NODE COUNT", Type: "number"},
+		}
+	}
+
+	table := &v1.Table{ColumnDefinitions: columns}
+
+	orDash := func(value string) string {
+		if value == "" {
+			return "-"
+		}
+		return value
 	}
 
 	for _, ami := range amiUsage {
-		table.Rows = append(table.Rows, v1.TableRow{
-			Cells: []interface{}{
+		var cells []interface{}
+		if wide {
+			created := "-"
+			age := "-"
+			if !ami.CreationDate.IsZero() {
+				created = ami.CreationDate.Format("2006-01-02")
+				age = fmt.Sprintf("%d", ami.AgeDays)
+			}
+
+			outdatedNodes := "-"
+			if ami.Outdated {
+				outdatedNodes = strings.Join(ami.NodeNames, ", ")
+				if outdatedNodes == "" {
+					outdatedNodes = fmt.Sprintf("%d pending", ami.NodeCount)
+				}
+			}
+
+			cells = []interface{}{
 				ami.Profile,
 				ami.Region,
 				ami.ClusterName,
 				ami.NodePoolName,
+				orDash(ami.NodeClassName),
 				ami.CurrentAMI,
+				orDash(ami.AMIName),
+				orDash(ami.Architecture),
+				created,
+				orDash(ami.DeprecationTime),
+				age,
+				formatLatestAMI(ami.CurrentAMI, ami.LatestAMI),
 				ami.NodeCount,
-			},
-		})
+				outdatedNodes,
+			}
+		} else {
+			cells = []interface{}{
+				ami.Profile,
+				ami.Region,
+				ami.ClusterName,
+				ami.NodePoolName,
+				ami.CurrentAMI,
+				ami.NodeCount,
+			}
+		}
+		table.Rows = append(table.Rows, v1.TableRow{Cells: cells})
 	}
 
 	err := printer.PrintObj(table, os.Stdout)
@@ -270,7 +375,20 @@ func PrintKarpenterAMIUsage(noHeaders bool, amiUsage ...data.KarpenterAMIUsageIn
 	}
 }
 
-func PrintKarpenterDrift(noHeaders bool, driftInfo ...data.KarpenterDriftInfo) {
+// formatLatestAMI tells whether the AMI in use is the newest one the
+// EC2NodeClass selects, naming the newer AMI when it is not
+func formatLatestAMI(current, latest string) string {
+	switch latest {
+	case "":
+		return "Unknown"
+	case current:
+		return "Yes"
+	default:
+		return "No (" + latest + ")"
+	}
+}
+
+func PrintKarpenterDrift(noHeaders bool, wide bool, driftInfo ...data.KarpenterDriftInfo) {
 	sort.Slice(driftInfo, func(i, j int) bool {
 		if driftInfo[i].Profile != driftInfo[j].Profile {
 			return driftInfo[i].Profile < driftInfo[j].Profile
@@ -286,34 +404,701 @@ func PrintKarpenterDrift(noHeaders bool, driftInfo ...data.KarpenterDriftInfo) {
 
 	printer := printers.NewTablePrinter(printers.PrintOptions{NoHeaders: noHeaders})
 
+	columns := []v1.TableColumnDefinition{
+		{Name: "AWS PROFILE", Type: "string"},
+		{Name: "AWS REGION", Type: "string"},
+		{Name: "CLUSTER NAME", Type: "string"},
+		{Name: "TYPE", Type: "string"},
+		{Name: "NAME", Type: "string"},
+		{Name: "NODE", Type: "string"},
+		{Name: "NODEPOOL", Type: "string"},
+	}
+	if wide {
+		columns = append(columns, v1.TableColumnDefinition{Name: "NODECLASS", Type: "string"})
+	}
+	columns = append(columns,
+		v1.TableColumnDefinition{Name: "DRIFTED SINCE", Type: "string"},
+		v1.TableColumnDefinition{Name: "REASON", Type: "string"},
+		v1.TableColumnDefinition{Name: "CAUSE", Type: "string"},
+		v1.TableColumnDefinition{Name: "BLOCKED BY", Type: "string"},
+	)
+	if wide {
+		columns = append(columns, v1.TableColumnDefinition{Name: "MESSAGE", Type: "string"})
+	}
+
+	table := &v1.Table{ColumnDefinitions: columns}
+
+	for _, drift := range driftInfo {
+		humanAge := "-"
+		if !drift.DriftedSince.IsZero() {
+			humanAge = duration.ShortHumanDuration(time.Since(drift.DriftedSince))
+		}
+
+		nodeName := drift.NodeName
+		if nodeName == "" {
+			nodeName = "-"
+		}
+		reason := drift.Reason
+		if reason == "" {
+			reason = "-"
+		}
+		blockedBy := strings.Join(drift.BlockedBy, ", ")
+		if blockedBy == "" {
+			blockedBy = "-"
+		}
+
+		cells := []interface{}{
+			drift.Profile,
+			drift.Region,
+			drift.ClusterName,
+			drift.ResourceType,
+			drift.Name,
+			nodeName,
+			drift.NodePoolName,
+		}
+		if wide {
+			cells = append(cells, drift.NodeClassName)
+		}
+		cells = append(cells, humanAge, reason, drift.Cause, blockedBy)
+		if wide {
+			message := drift.Message
+			if message == "" {
+				message = "-"
+			}
+			cells = append(cells, message)
+		}
+
+		table.Rows = append(table.Rows, v1.TableRow{Cells: cells})
+	}
+
+	err := printer.PrintObj(table, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error printing table: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func PrintKarpenterDriftSummary(noHeaders bool, summaries ...data.KarpenterDriftSummary) {
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Profile != summaries[j].Profile {
+			return summaries[i].Profile < summaries[j].Profile
+		}
+		if summaries[i].Region != summaries[j].Region {
+			return summaries[i].Region < summaries[j].Region
+		}
+		if summaries[i].ClusterName != summaries[j].ClusterName {
+			return summaries[i].ClusterName < summaries[j].ClusterName
+		}
+		return summaries[i].NodePoolName < summaries[j].NodePoolName
+	})
+
+	printer := printers.NewTablePrinter(printers.PrintOptions{NoHeaders: noHeaders})
+
 	table := &v1.Table{
 		ColumnDefinitions: []v1.TableColumnDefinition{
 			{Name: "AWS PROFILE", Type: "string"},
 			{Name: "AWS REGION", Type: "string"},
 			{Name: "CLUSTER NAME", Type: "string"},
-			{Name: "TYPE", Type: "string"},
-			{Name: "NAME", Type: "string"},
-			{Name: "NODE", Type: "string"},
 			{Name: "NODEPOOL", Type: "string"},
-			{Name: "DRIFTED SINCE", Type: "string"},
-			{Name: "REASON", Type: "string"},
+			{Name: "NODECLAIMS", Type: "number"},
+			{Name: "DRIFTED", Type: "number"},
+			{Name: "BLOCKED", Type: "number"},
+			{Name: "REASONS", Type: "string"},
 		},
 	}
 
-	for _, drift := range driftInfo {
-		humanAge := duration.ShortHumanDuration(time.Since(drift.DriftedSince))
+	for _, summary := range summaries {
+		nodePool := summary.NodePoolName
+		if nodePool == "" {
+			nodePool = "-"
+		}
+
+		reasons := []string{}
+		for reason, count := range summary.Reasons {
+			if reason == "" {
+				reason = "Unknown"
+			}
+			reasons = append(reasons, fmt.Sprintf("%s=%d", reason, count))
+		}
+		sort.Strings(reasons)
+		reasonList := strings.Join(reasons, ",")
+		if reasonList == "" {
+			reasonList = "-"
+		}
 
 		table.Rows = append(table.Rows, v1.TableRow{
 			Cells: []interface{}{
-				drift.Profile,
-				drift.Region,
-				drift.ClusterName,
-				drift.ResourceType,
-				drift.Name,
-				drift.NodeName,
-				drift.NodePoolName,
+				summary.Profile,
+				summary.Region,
+				summary.ClusterName,
+				nodePool,
+				summary.NodeClaims,
+				summary.Drifted,
+				summary.Blocked,
+				reasonList,
+			},
+		})
+	}
+
+	err := printer.PrintObj(table, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error printing table: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func PrintKarpenterNodeClasses(noHeaders bool, wide bool, nodeClasses ...data.KarpenterNodeClassInfo) {
+	sort.Slice(nodeClasses, func(i, j int) bool {
+		if nodeClasses[i].Profile != nodeClasses[j].Profile {
+			return nodeClasses[i].Profile < nodeClasses[j].Profile
+		}
+		if nodeClasses[i].Region != nodeClasses[j].Region {
+			return nodeClasses[i].Region < nodeClasses[j].Region
+		}
+		if nodeClasses[i].ClusterName != nodeClasses[j].ClusterName {
+			return nodeClasses[i].ClusterName < nodeClasses[j].ClusterName
+		}
+		return nodeClasses[i].Name < nodeClasses[j].Name
+	})
+
+	printer := printers.NewTablePrinter(printers.PrintOptions{NoHeaders: noHeaders})
+
+	var columns []v1.TableColumnDefinition
+	if wide {
+		columns = []v1.TableColumnDefinition{
+			{Name: "AWS PROFILE", Type: "string"},
+			{Name: "AWS REGION", Type: "string"},
+			{Name: "CLUSTER NAME", Type: "string"},
+			{Name: "NODECLASS", Type: "string"},
+			{Name: "AMI SELECTOR", Type: "string"},
+			{Name: "AMIS", Type: "string"},
+			{Name: "SUBNET SELECTOR", Type: "string"},
+			{Name: "SUBNETS", Type: "string"},
+			{Name: "SG SELECTOR", Type: "string"},
+			{Name: "SECURITY GROUPS", Type: "string"},
+			{Name: "ROLE", Type: "string"},
+			{Name: "INSTANCE PROFILE", Type: "string"},
+			{Name: "BLOCK DEVICES", Type: "string"},
+			{Name: "READY", Type: "string"},
+			{Name: "FAILING CONDITIONS", Type: "string"},
+			{Name: "AGE", Type: "string"},
+		}
+	} else {
+		columns = []v1.TableColumnDefinition{
+			{Name: "AWS PROFILE", Type: "string"},
+			{Name: "AWS REGION", Type: "string"},
+			{Name: "CLUSTER NAME", Type: "string"},
+			{Name: "NODECLASS", Type: "string"},
+			{Name: "AMI SELECTOR", Type: "string"},
+			{Name: "AMIS", Type: "number"},
+			{Name: "SUBNETS", Type: "number"},
+			{Name: "SECURITY GROUPS", Type: "number"},
+			{Name: "ROLE", Type: "string"},
+			{Name: "READY", Type: "string"},
+			{Name: "AGE", Type: "string"},
+		}
+	}
+
+	table := &v1.Table{ColumnDefinitions: columns}
+
+	joinOrDash := func(values []string) string {
+		if len(values) == 0 {
+			return "-"
+		}
+		return strings.Join(values, ", ")
+	}
+
+	for _, nc := range nodeClasses {
+		humanAge := duration.ShortHumanDuration(time.Since(nc.Age))
+
+		role := nc.Role
+		if role == "" {
+			role = "-"
+		}
+
+		var cells []interface{}
+		if wide {
+			instanceProfile := nc.InstanceProfile
+			if instanceProfile == "" {
+				instanceProfile = "-"
+			}
+
+			cells = []interface{}{
+				nc.Profile,
+				nc.Region,
+				nc.ClusterName,
+				nc.Name,
+				joinOrDash(nc.AMISelectorTerms),
+				joinOrDash(nc.AMIs),
+				joinOrDash(nc.SubnetSelectorTerms),
+				joinOrDash(nc.Subnets),
+				joinOrDash(nc.SecurityGroupSelectorTerms),
+				joinOrDash(nc.SecurityGroups),
+				role,
+				instanceProfile,
+				joinOrDash(nc.BlockDeviceMappings),
+				nc.Ready,
+				joinOrDash(nc.FailingConditions),
+				humanAge,
+			}
+		} else {
+			amiSelector := joinOrDash(nc.AMISelectorTerms)
+			if len(amiSelector) > 40 {
+				amiSelector = amiSelector[:37] + "..."
+			}
+
+			cells = []interface{}{
+				nc.Profile,
+				nc.Region,
+				nc.ClusterName,
+				nc.Name,
+				amiSelector,
+				len(nc.AMIs),
+				len(nc.Subnets),
+				len(nc.SecurityGroups),
+				role,
+				nc.Ready,
 				humanAge,
-				drift.Reason,
+			}
+		}
+
+		table.Rows = append(table.Rows, v1.TableRow{Cells: cells})
+	}
+
+	err := printer.PrintObj(table, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error printing table: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func PrintKarpenterDisruption(noHeaders bool, pools ...data.KarpenterDisruptionInfo) {
+	sort.Slice(pools, func(i, j int) bool {
+		if pools[i].Profile != pools[j].Profile {
+			return pools[i].Profile < pools[j].Profile
+		}
+		if pools[i].Region != pools[j].Region {
+			return pools[i].Region < pools[j].Region
+		}
+		if pools[i].ClusterName != pools[j].ClusterName {
+			return pools[i].ClusterName < pools[j].ClusterName
+		}
+		return pools[i].NodePoolName < pools[j].NodePoolName
+	})
+
+	printer := printers.NewTablePrinter(printers.PrintOptions{NoHeaders: noHeaders})
+
+	table := &v1.Table{
+		ColumnDefinitions: []v1.TableColumnDefinition{
+			{Name: "AWS PROFILE", Type: "string"},
+			{Name: "AWS REGION", Type: "string"},
+			{Name: "CLUSTER NAME", Type: "string"},
+			{Name: "NODEPOOL", Type: "string"},
+			{Name: "CONSOLIDATION", Type: "string"},
+			{Name: "CONSOLIDATE AFTER", Type: "string"},
+			{Name: "BUDGETS", Type: "string"},
+			{Name: "ALLOWED NOW", Type: "string"},
+			{Name: "NODECLAIMS", Type: "number"},
+			{Name: "DISRUPTING", Type: "number"},
+			{Name: "CANDIDATES", Type: "number"},
+			{Name: "BLOCKED", Type: "number"},
+		},
+	}
+
+	for _, pool := range pools {
+		consolidation := pool.ConsolidationPolicy
+		if consolidation == "" {
+			consolidation = "-"
+		}
+		consolidateAfter := pool.ConsolidateAfter
+		if consolidateAfter == "" {
+			consolidateAfter = "-"
+		}
+
+		budgets := []string{}
+		for _, budget := range pool.Budgets {
+			budgets = append(budgets, formatBudget(budget))
+		}
+
+		table.Rows = append(table.Rows, v1.TableRow{
+			Cells: []interface{}{
+				pool.Profile,
+				pool.Region,
+				pool.ClusterName,
+				pool.NodePoolName,
+				consolidation,
+				consolidateAfter,
+				strings.Join(budgets, "; "),
+				formatAllowedDisruptions(pool.AllowedDisruptions),
+				pool.NodeClaims,
+				pool.Disrupting,
+				pool.Candidates,
+				pool.Blocked,
+			},
+		})
+	}
+
+	err := printer.PrintObj(table, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error printing table: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// formatBudget renders a budget as its node count followed by the reasons it
+// is limited to and, for scheduled budgets, when it applies and whether it
+// is active now, e.g. 0 for Drifted at "0 9 * * 1-5" for 8h (active)
+func formatBudget(budget data.KarpenterBudgetInfo) string {
+	formatted := budget.Nodes
+	if len(budget.Reasons) > 0 {
+		formatted += " for " + strings.Join(budget.Reasons, ",")
+	}
+	if budget.Schedule != "" {
+		formatted += fmt.Sprintf(" at %q for %s", budget.Schedule, budget.Duration)
+		if budget.Active {
+			formatted += " (active)"
+		} else {
+			formatted += " (inactive)"
+		}
+	}
+	return formatted
+}
+
+// formatAllowedDisruptions shows a single number when every reason allows
+// the same, otherwise the number allowed for each reason
+func formatAllowedDisruptions(allowed map[string]int) string {
+	if len(allowed) == 0 {
+		return "-"
+	}
+
+	reasons := make([]string, 0, len(allowed))
+	for reason := range allowed {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+
+	same := true
+	for _, reason := range reasons {
+		if allowed[reason] != allowed[reasons[0]] {
+			same = false
+			break
+		}
+	}
+	if same {
+		return fmt.Sprintf("%d", allowed[reasons[0]])
+	}
+
+	parts := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		parts = append(parts, fmt.Sprintf("%s=%d", reason, allowed[reason]))
+	}
+	return strings.Join(parts, ",")
+}
+
+func PrintKarpenterDisruptionNodes(noHeaders bool, nodes ...data.KarpenterDisruptionNodeInfo) {
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Profile != nodes[j].Profile {
+			return nodes[i].Profile < nodes[j].Profile
+		}
+		if nodes[i].Region != nodes[j].Region {
+			return nodes[i].Region < nodes[j].Region
+		}
+		if nodes[i].ClusterName != nodes[j].ClusterName {
+			return nodes[i].ClusterName < nodes[j].ClusterName
+		}
+		if nodes[i].NodePoolName != nodes[j].NodePoolName {
+			return nodes[i].NodePoolName < nodes[j].NodePoolName
+		}
+		return nodes[i].NodeClaimName < nodes[j].NodeClaimName
+	})
+
+	printer := printers.NewTablePrinter(printers.PrintOptions{NoHeaders: noHeaders})
+
+	table := &v1.Table{
+		ColumnDefinitions: []v1.TableColumnDefinition{
+			{Name: "AWS PROFILE", Type: "string"},
+			{Name: "AWS REGION", Type: "string"},
+			{Name: "CLUSTER NAME", Type: "string"},
+			{Name: "NODEPOOL", Type: "string"},
+			{Name: "NODECLAIM", Type: "string"},
+			{Name: "NODE", Type: "string"},
+			{Name: "STATE", Type: "string"},
+			{Name: "BLOCKED BY", Type: "string"},
+		},
+	}
+
+	for _, node := range nodes {
+		nodeName := node.NodeName
+		if nodeName == "" {
+			nodeName = "-"
+		}
+		blockedBy := strings.Join(node.BlockedBy, ", ")
+		if blockedBy == "" {
+			blockedBy = "-"
+		}
+
+		table.Rows = append(table.Rows, v1.TableRow{
+			Cells: []interface{}{
+				node.Profile,
+				node.Region,
+				node.ClusterName,
+				node.NodePoolName,
+				node.NodeClaimName,
+				nodeName,
+				node.State,
+				blockedBy,
+			},
+		})
+	}
+
+	err := printer.PrintObj(table, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error printing table: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func PrintKarpenterVersions(noHeaders bool, versions ...data.KarpenterVersionInfo) {
+	sort.Slice(versions, func(i, j int) bool {
+		if versions[i].Profile != versions[j].Profile {
+			return versions[i].Profile < versions[j].Profile
+		}
+		if versions[i].Region != versions[j].Region {
+			return versions[i].Region < versions[j].Region
+		}
+		return versions[i].ClusterName < versions[j].ClusterName
+	})
+
+	printer := printers.NewTablePrinter(printers.PrintOptions{NoHeaders: noHeaders})
+
+	table := &v1.Table{
+		ColumnDefinitions: []v1.TableColumnDefinition{
+			{Name: "AWS PROFILE", Type: "string"},
+			{Name: "AWS REGION", Type: "string"},
+			{Name: "CLUSTER NAME", Type: "string"},
+			{Name: "KARPENTER API", Type: "string"},
+			{Name: "SERVED APIS", Type: "string"},
+			{Name: "RESOURCES", Type: "string"},
+			{Name: "CONTROLLER VERSION", Type: "string"},
+		},
+	}
+
+	for _, version := range versions {
+		apiVersion := version.APIVersion
+		if apiVersion == "" {
+			apiVersion = "Not installed"
+		}
+		servedAPIs := strings.Join(version.ServedAPIs, ",")
+		if servedAPIs == "" {
+			servedAPIs = "-"
+		}
+		resources := version.Resources
+		if resources == "" {
+			resources = "-"
+		}
+		controllerVersion := version.ControllerVersion
+		if controllerVersion == "" {
+			controllerVersion = "-"
+		}
+
+		table.Rows = append(table.Rows, v1.TableRow{
+			Cells: []interface{}{
+				version.Profile,
+				version.Region,
+				version.ClusterName,
+				apiVersion,
+				servedAPIs,
+				resources,
+				controllerVersion,
+			},
+		})
+	}
+
+	err := printer.PrintObj(table, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error printing table: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func PrintKarpenterPodPlacement(noHeaders bool, placements ...data.KarpenterPodPlacementInfo) {
+	// Within a cluster NodePools keep the order they were evaluated in
+	sort.SliceStable(placements, func(i, j int) bool {
+		if placements[i].Profile != placements[j].Profile {
+			return placements[i].Profile < placements[j].Profile
+		}
+		if placements[i].Region != placements[j].Region {
+			return placements[i].Region < placements[j].Region
+		}
+		return placements[i].ClusterName < placements[j].ClusterName
+	})
+
+	printer := printers.NewTablePrinter(printers.PrintOptions{NoHeaders: noHeaders})
+
+	table := &v1.Table{
+		ColumnDefinitions: []v1.TableColumnDefinition{
+			{Name: "AWS PROFILE", Type: "string"},
+			{Name: "AWS REGION", Type: "string"},
+			{Name: "CLUSTER NAME", Type: "string"},
+			{Name: "NODEPOOL", Type: "string"},
+			{Name: "WEIGHT", Type: "number"},
+			{Name: "RESULT", Type: "string"},
+			{Name: "REASONS", Type: "string"},
+		},
+	}
+
+	for _, placement := range placements {
+		reasons := strings.Join(placement.Reasons, "; ")
+		if reasons == "" {
+			reasons = "-"
+		}
+
+		table.Rows = append(table.Rows, v1.TableRow{
+			Cells: []interface{}{
+				placement.Profile,
+				placement.Region,
+				placement.ClusterName,
+				placement.NodePoolName,
+				placement.Weight,
+				placement.Result,
+				reasons,
+			},
+		})
+	}
+
+	err := printer.PrintObj(table, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error printing table: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func PrintKarpenterNodeClaimTimeline(noHeaders bool, timeline ...data.KarpenterNodeClaimTimelineEntry) {
+	// Entries are already in time order within a NodeClaim
+	sort.SliceStable(timeline, func(i, j int) bool {
+		if timeline[i].Profile != timeline[j].Profile {
+			return timeline[i].Profile < timeline[j].Profile
+		}
+		if timeline[i].Region != timeline[j].Region {
+			return timeline[i].Region < timeline[j].Region
+		}
+		return timeline[i].ClusterName < timeline[j].ClusterName
+	})
+
+	printer := printers.NewTablePrinter(printers.PrintOptions{NoHeaders: noHeaders})
+
+	table := &v1.Table{
+		ColumnDefinitions: []v1.TableColumnDefinition{
+			{Name: "AWS PROFILE", Type: "string"},
+			{Name: "AWS REGION", Type: "string"},
+			{Name: "CLUSTER NAME", Type: "string"},
+			{Name: "NODECLAIM", Type: "string"},
+			{Name: "TIME", Type: "string"},
+			{Name: "ELAPSED", Type: "string"},
+			{Name: "DELTA", Type: "string"},
+			{Name: "SOURCE", Type: "string"},
+			{Name: "TYPE", Type: "string"},
+			{Name: "STATUS", Type: "string"},
+			{Name: "MESSAGE", Type: "string"},
+		},
+	}
+
+	for _, entry := range timeline {
+		status := entry.Status
+		if status == "" {
+			status = "-"
+		}
+		message := entry.Message
+		if message == "" {
+			message = "-"
+		}
+
+		table.Rows = append(table.Rows, v1.TableRow{
+			Cells: []interface{}{
+				entry.Profile,
+				entry.Region,
+				entry.ClusterName,
+				entry.NodeClaimName,
+				entry.Time.Format(time.RFC3339),
+				duration.HumanDuration(entry.SinceCreated),
+				duration.HumanDuration(entry.SincePrevious),
+				entry.Source,
+				entry.Name,
+				status,
+				message,
+			},
+		})
+	}
+
+	err := printer.PrintObj(table, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error printing table: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func PrintKarpenterLaunchStats(noHeaders bool, stats ...data.KarpenterLaunchStats) {
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Profile != stats[j].Profile {
+			return stats[i].Profile < stats[j].Profile
+		}
+		if stats[i].Region != stats[j].Region {
+			return stats[i].Region < stats[j].Region
+		}
+		if stats[i].ClusterName != stats[j].ClusterName {
+			return stats[i].ClusterName < stats[j].ClusterName
+		}
+		if stats[i].NodePoolName != stats[j].NodePoolName {
+			return stats[i].NodePoolName < stats[j].NodePoolName
+		}
+		return stats[i].InstanceType < stats[j].InstanceType
+	})
+
+	printer := printers.NewTablePrinter(printers.PrintOptions{NoHeaders: noHeaders})
+
+	table := &v1.Table{
+		ColumnDefinitions: []v1.TableColumnDefinition{
+			{Name: "AWS PROFILE", Type: "string"},
+			{Name: "AWS REGION", Type: "string"},
+			{Name: "CLUSTER NAME", Type: "string"},
+			{Name: "NODEPOOL", Type: "string"},
+			{Name: "INSTANCE TYPE", Type: "string"},
+			{Name: "READY", Type: "number"},
+			{Name: "PENDING", Type: "number"},
+			{Name: "P50", Type: "string"},
+			{Name: "P95", Type: "string"},
+			{Name: "MAX", Type: "string"},
+		},
+	}
+
+	for _, s := range stats {
+		p50, p95, maxReady := "-", "-", "-"
+		if s.Count > 0 {
+			p50 = duration.HumanDuration(s.P50)
+			p95 = duration.HumanDuration(s.P95)
+			maxReady = duration.HumanDuration(s.Max)
+		}
+		nodePool := s.NodePoolName
+		if nodePool == "" {
+			nodePool = "-"
+		}
+		instanceType := s.InstanceType
+		if instanceType == "" {
+			instanceType = "-"
+		}
+
+		table.Rows = append(table.Rows, v1.TableRow{
+			Cells: []interface{}{
+				s.Profile,
+				s.Region,
+				s.ClusterName,
+				nodePool,
+				instanceType,
+				s.Count,
+				s.Pending,
+				p50,
+				p95,
+				maxReady,
 			},
 		})
 	}
@@ -0,0 +1,20 @@
+package printutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatLimitUsage(t *testing.T) {
+	assert.Equal(t, "24/1000 (2%)", formatLimitUsage("24", "1000", 2.4, false))
+	assert.Equal(t, "0/100 (0%)", formatLimitUsage("", "100", 0, false))
+	assert.Equal(t, "24/-", formatLimitUsage("24", "", -1, false))
+	assert.Equal(t, "96.5Gi/1000Gi (10%)", formatLimitUsage("101187584Ki", "1000Gi", 9.65, true))
+}
+
+func TestFormatLatestAMI(t *testing.T) {
+	assert.Equal(t, "Yes", formatLatestAMI("ami-1", "ami-1"))
+	assert.Equal(t, "No (ami-2)", formatLatestAMI("ami-1", "ami-2"))
+	assert.Equal(t, "Unknown", formatLatestAMI("ami-1", ""))
+}
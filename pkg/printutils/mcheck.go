@@ -3,6 +3,8 @@ package printutils
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/jordiprats/kubectl-eks/pkg/data"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -78,22 +80,99 @@ func PrintHealthSummary(noHeaders bool, summaries []data.ClusterHealthSummary) {
 			{Name: "STATEFULSETS", Type: "string"},
 			{Name: "DAEMONSETS", Type: "string"},
 			{Name: "REPLICASETS", Type: "string"},
-			{Name: "STATUS", Type: "string"},
+			{Name: "JOBS", Type: "string"},
+			{Name: "CRONJOBS", Type: "string"},
+			{Name: "PVCS", Type: "string"},
+			{Name: "PDBS", Type: "string"},
+			{Name: "HPAS", Type: "string"},
+			{Name: "SERVICES", Type: "string"},
 		},
 	}
 
+	// --kinds resources get a column per kind
+	customKinds := []string{}
+	seen := make(map[string]bool)
 	for _, s := range summaries {
+		for kind := range s.CustomTotals {
+			if !seen[kind] {
+				seen[kind] = true
+				customKinds = append(customKinds, kind)
+			}
+		}
+	}
+	sort.Strings(customKinds)
+	for _, kind := range customKinds {
+		table.ColumnDefinitions = append(table.ColumnDefinitions, v1.TableColumnDefinition{Name: strings.ToUpper(kind), Type: "string"})
+	}
+	table.ColumnDefinitions = append(table.ColumnDefinitions, v1.TableColumnDefinition{Name: "STATUS", Type: "string"})
+
+	for _, s := range summaries {
+		cells := []interface{}{
+			s.Profile,
+			s.Region,
+			s.ClusterName,
+			fmt.Sprintf("%d/%d", s.HealthyPods, s.TotalPods),
+			fmt.Sprintf("%d/%d", s.HealthyDeployments, s.TotalDeployments),
+			fmt.Sprintf("%d/%d", s.HealthyStatefulSets, s.TotalStatefulSets),
+			fmt.Sprintf("%d/%d", s.HealthyDaemonSets, s.TotalDaemonSets),
+			fmt.Sprintf("%d/%d", s.HealthyReplicaSets, s.TotalReplicaSets),
+			fmt.Sprintf("%d/%d", s.HealthyJobs, s.TotalJobs),
+			fmt.Sprintf("%d/%d", s.HealthyCronJobs, s.TotalCronJobs),
+			fmt.Sprintf("%d/%d", s.HealthyPVCs, s.TotalPVCs),
+			fmt.Sprintf("%d/%d", s.HealthyPDBs, s.TotalPDBs),
+			fmt.Sprintf("%d/%d", s.HealthyHPAs, s.TotalHPAs),
+			fmt.Sprintf("%d/%d", s.HealthyServices, s.TotalServices),
+		}
+		for _, kind := range customKinds {
+			cells = append(cells, fmt.Sprintf("%d/%d", s.CustomHealthy[kind], s.CustomTotals[kind]))
+		}
+		cells = append(cells, s.OverallStatus)
+		table.Rows = append(table.Rows, v1.TableRow{Cells: cells})
+	}
+
+	err := printer.PrintObj(table, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error printing table: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// PrintHealthChanges prints the resources that broke or recovered between
+// recorded mcheck runs
+func PrintHealthChanges(noHeaders bool, changes []data.HealthChange) {
+	printer := printers.NewTablePrinter(printers.PrintOptions{NoHeaders: noHeaders})
+
+	table := &v1.Table{
+		ColumnDefinitions: []v1.TableColumnDefinition{
+			{Name: "TIME", Type: "string"},
+			{Name: "AWS PROFILE", Type: "string"},
+			{Name: "AWS REGION", Type: "string"},
+			{Name: "CLUSTER NAME", Type: "string"},
+			{Name: "CHANGE", Type: "string"},
+			{Name: "KIND", Type: "string"},
+			{Name: "NAMESPACE", Type: "string"},
+			{Name: "NAME", Type: "string"},
+			{Name: "MESSAGE", Type: "string"},
+		},
+	}
+
+	for _, c := range changes {
+		namespace := c.Namespace
+		if namespace == "" {
+			namespace = "-"
+		}
+
 		table.Rows = append(table.Rows, v1.TableRow{
 			Cells: []interface{}{
-				s.Profile,
-				s.Region,
-				s.ClusterName,
-				fmt.Sprintf("%d/%d", s.HealthyPods, s.TotalPods),
-				fmt.Sprintf("%d/%d", s.HealthyDeployments, s.TotalDeployments),
-				fmt.Sprintf("%d/%d", s.HealthyStatefulSets, s.TotalStatefulSets),
-				fmt.Sprintf("%d/%d", s.HealthyDaemonSets, s.TotalDaemonSets),
-				fmt.Sprintf("%d/%d", s.HealthyReplicaSets, s.TotalReplicaSets),
-				s.OverallStatus,
+				c.Time.Local().Format("2006-01-02 15:04:05"),
+				c.Profile,
+				c.Region,
+				c.ClusterName,
+				c.Change,
+				c.Kind,
+				namespace,
+				c.Name,
+				c.Message,
 			},
 		})
 	}
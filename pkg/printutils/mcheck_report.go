@@ -0,0 +1,259 @@
+package printutils
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// PrintHealthJUnit writes the health check results as JUnit XML: one
+// testsuite per cluster and one testcase per checked resource. Checks that
+// could not run are reported as errors.
+func PrintHealthJUnit(w io.Writer, results []data.HealthCheckResult, summaries []data.ClusterHealthSummary) error {
+	report := junitTestSuites{Name: "kubectl-eks mcheck"}
+
+	for _, summary := range summaries {
+		suite := junitTestSuite{
+			Name: summary.ClusterName,
+			Properties: []junitProperty{
+				{Name: "aws.profile", Value: summary.Profile},
+				{Name: "aws.region", Value: summary.Region},
+			},
+		}
+
+		for _, r := range results {
+			if r.Profile != summary.Profile || r.Region != summary.Region || r.ClusterName != summary.ClusterName {
+				continue
+			}
+
+			testCase := junitTestCase{
+				Name:      fmt.Sprintf("%s %s", r.Kind, resourceRef(r.Namespace, r.Name)),
+				ClassName: fmt.Sprintf("%s.%s", summary.ClusterName, r.Kind),
+			}
+			if !r.IsHealthy {
+				testCase.Failure = &junitFailure{
+					Message: r.Message,
+					Type:    r.Status,
+					Text:    fmt.Sprintf("Ready: %s\nStatus: %s\n%s", r.Ready, r.Status, r.Message),
+				}
+				suite.Failures++
+			}
+			suite.TestCases = append(suite.TestCases, testCase)
+		}
+
+		names := make(map[string]bool)
+		for i, checkErr := range summary.Errors {
+			name := checkErrorName(i, checkErr)
+			if names[name] {
+				name = fmt.Sprintf("check %d", i+1)
+			}
+			names[name] = true
+			suite.TestCases = append(suite.TestCases, junitTestCase{
+				Name:      name,
+				ClassName: summary.ClusterName + ".CheckError",
+				Error:     &junitFailure{Message: checkErr, Type: "CheckError"},
+			})
+			suite.Errors++
+		}
+
+		suite.Tests = len(suite.TestCases)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		report.Suites = append(report.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// checkErrorName names the testcase of a check error after the kind that
+// failed, e.g. "check pods" for "pods: connection refused", numbering the
+// errors without one
+func checkErrorName(i int, checkErr string) string {
+	kind, _, found := strings.Cut(checkErr, ": ")
+	if !found || kind == "" || strings.ContainsAny(kind, " \t") {
+		return fmt.Sprintf("check %d", i+1)
+	}
+	return "check " + kind
+}
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string            `json:"ruleId"`
+	Level      string            `json:"level"`
+	Message    sarifMessage      `json:"message"`
+	Locations  []sarifLocation   `json:"locations"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// PrintHealthSARIF writes the unhealthy resources, and the checks that could
+// not run, as a SARIF 2.1.0 log. Each resource kind gets its own rule.
+func PrintHealthSARIF(w io.Writer, results []data.HealthCheckResult, summaries []data.ClusterHealthSummary) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "kubectl-eks mcheck",
+			InformationURI: "https://github.com/jordiprats/kubectl-eks",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	rules := make(map[string]bool)
+	addRule := func(id, description string) {
+		if !rules[id] {
+			rules[id] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: id, ShortDescription: sarifMessage{Text: description}})
+		}
+	}
+
+	for _, r := range results {
+		if r.IsHealthy {
+			continue
+		}
+
+		ruleID := fmt.Sprintf("unhealthy-%s", strings.ToLower(r.Kind))
+		addRule(ruleID, fmt.Sprintf("%s is not healthy", r.Kind))
+
+		fullName := strings.Join([]string{r.ClusterName, r.Kind, resourceRef(r.Namespace, r.Name)}, "/")
+		run.Results = append(run.Results, sarifResult{
+			RuleID:  ruleID,
+			Level:   "error",
+			Message: sarifMessage{Text: fmt.Sprintf("%s %s in cluster %s: %s", r.Kind, resourceRef(r.Namespace, r.Name), r.ClusterName, r.Message)},
+			Locations: []sarifLocation{{LogicalLocations: []sarifLogicalLocation{{
+				Name:               r.Name,
+				FullyQualifiedName: fullName,
+				Kind:               "resource",
+			}}}},
+			Properties: map[string]string{
+				"profile": r.Profile,
+				"region":  r.Region,
+				"ready":   r.Ready,
+				"status":  r.Status,
+			},
+		})
+	}
+
+	for _, summary := range summaries {
+		for _, checkErr := range summary.Errors {
+			addRule("check-error", "Resources could not be checked")
+			run.Results = append(run.Results, sarifResult{
+				RuleID:  "check-error",
+				Level:   "warning",
+				Message: sarifMessage{Text: fmt.Sprintf("Cluster %s: %s", summary.ClusterName, checkErr)},
+				Locations: []sarifLocation{{LogicalLocations: []sarifLogicalLocation{{
+					Name:               summary.ClusterName,
+					FullyQualifiedName: summary.ClusterName,
+					Kind:               "cluster",
+				}}}},
+				Properties: map[string]string{
+					"profile": summary.Profile,
+					"region":  summary.Region,
+				},
+			})
+		}
+	}
+
+	report := sarifLog{Schema: sarifSchema, Version: "2.1.0", Runs: []sarifRun{run}}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// resourceRef returns namespace/name, or just name for cluster-scoped resources
+func resourceRef(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
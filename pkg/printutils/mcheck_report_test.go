@@ -0,0 +1,98 @@
+package printutils
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var reportTestSummaries = []data.ClusterHealthSummary{
+	{Profile: "prod", Region: "eu-west-1", ClusterName: "a"},
+	{Profile: "prod", Region: "eu-west-1", ClusterName: "b", Errors: []string{"pods: connection refused"}},
+}
+
+var reportTestResults = []data.HealthCheckResult{
+	{Profile: "prod", Region: "eu-west-1", ClusterName: "a", Kind: "Pod", Namespace: "shop", Name: "api", Ready: "1/1", Status: "Running", Message: "All containers ready", IsHealthy: true},
+	{Profile: "prod", Region: "eu-west-1", ClusterName: "a", Kind: "Deployment", Namespace: "shop", Name: "checkout", Ready: "0/2", Status: "Available:0 UpToDate:2", Message: "Deployment does not have minimum availability."},
+	{Profile: "prod", Region: "eu-west-1", ClusterName: "b", Kind: "DaemonSet", Namespace: "kube-system", Name: "aws-node", Ready: "3/3", Message: "All pods ready", IsHealthy: true},
+}
+
+func TestPrintHealthJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, PrintHealthJUnit(&buf, reportTestResults, reportTestSummaries))
+
+	var report junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &report))
+
+	assert.Equal(t, 4, report.Tests)
+	assert.Equal(t, 1, report.Failures)
+	assert.Equal(t, 1, report.Errors)
+	require.Len(t, report.Suites, 2)
+
+	suiteA := report.Suites[0]
+	assert.Equal(t, "a", suiteA.Name)
+	require.Len(t, suiteA.TestCases, 2)
+	assert.Equal(t, "Pod shop/api", suiteA.TestCases[0].Name)
+	assert.Nil(t, suiteA.TestCases[0].Failure)
+	require.NotNil(t, suiteA.TestCases[1].Failure)
+	assert.Equal(t, "Deployment does not have minimum availability.", suiteA.TestCases[1].Failure.Message)
+
+	suiteB := report.Suites[1]
+	require.Len(t, suiteB.TestCases, 2)
+	require.NotNil(t, suiteB.TestCases[1].Error)
+	assert.Equal(t, "pods: connection refused", suiteB.TestCases[1].Error.Message)
+}
+
+func TestPrintHealthJUnit_CheckErrors(t *testing.T) {
+	summaries := []data.ClusterHealthSummary{{
+		Profile:     "prod",
+		Region:      "eu-west-1",
+		ClusterName: "a",
+		Errors:      []string{"pods: connection refused", "deployments: forbidden", "cluster unreachable", "deployments: timeout"},
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, PrintHealthJUnit(&buf, nil, summaries))
+
+	var report junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &report))
+
+	require.Len(t, report.Suites, 1)
+	names := []string{}
+	for _, tc := range report.Suites[0].TestCases {
+		names = append(names, tc.Name)
+		assert.Equal(t, "a.CheckError", tc.ClassName)
+	}
+	assert.Equal(t, []string{"check pods", "check deployments", "check 3", "check 4"}, names, "every testcase is unique")
+	assert.Equal(t, 4, report.Errors)
+}
+
+func TestPrintHealthSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, PrintHealthSARIF(&buf, reportTestResults, reportTestSummaries))
+
+	var report sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+
+	assert.Equal(t, "2.1.0", report.Version)
+	require.Len(t, report.Runs, 1)
+
+	run := report.Runs[0]
+	require.Len(t, run.Results, 2, "only unhealthy resources and check errors are reported")
+	assert.Equal(t, "unhealthy-deployment", run.Results[0].RuleID)
+	assert.Equal(t, "error", run.Results[0].Level)
+	assert.Equal(t, "a/Deployment/shop/checkout", run.Results[0].Locations[0].LogicalLocations[0].FullyQualifiedName)
+	assert.Equal(t, "check-error", run.Results[1].RuleID)
+	assert.Equal(t, "warning", run.Results[1].Level)
+
+	ruleIDs := []string{}
+	for _, rule := range run.Tool.Driver.Rules {
+		ruleIDs = append(ruleIDs, rule.ID)
+	}
+	assert.Equal(t, []string{"unhealthy-deployment", "check-error"}, ruleIDs)
+}
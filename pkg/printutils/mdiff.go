@@ -0,0 +1,58 @@
+package printutils
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// PrintDiffSummary prints how the resource in each cluster compares with the
+// baseline cluster
+func PrintDiffSummary(noHeaders bool, results []data.ResourceDiffResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	printer := printers.NewTablePrinter(printers.PrintOptions{NoHeaders: noHeaders})
+
+	table := &v1.Table{
+		ColumnDefinitions: []v1.TableColumnDefinition{
+			{Name: "AWS PROFILE", Type: "string"},
+			{Name: "AWS REGION", Type: "string"},
+			{Name: "CLUSTER NAME", Type: "string"},
+			{Name: "STATUS", Type: "string"},
+			{Name: "CHANGES", Type: "string"},
+		},
+	}
+
+	for _, r := range results {
+		status := r.Status
+		if r.Error != "" {
+			status = fmt.Sprintf("%s: %s", r.Status, r.Error)
+		}
+
+		changes := "-"
+		if r.Added > 0 || r.Removed > 0 {
+			changes = fmt.Sprintf("+%d -%d", r.Added, r.Removed)
+		}
+
+		table.Rows = append(table.Rows, v1.TableRow{
+			Cells: []interface{}{
+				r.Profile,
+				r.Region,
+				r.ClusterName,
+				status,
+				changes,
+			},
+		})
+	}
+
+	err := printer.PrintObj(table, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error printing table: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -546,3 +546,47 @@ func formatAge(t time.Time) string {
 	}
 	return fmt.Sprintf("%dd", int(duration.Hours()/24))
 }
+
+// PrintAggregation prints a row per key with a column per cluster and the
+// total, followed by a TOTAL row
+func PrintAggregation(aggregation data.ResourceAggregation, output string, noHeaders bool) {
+	if output == "json" {
+		jsonBytes, _ := json.MarshalIndent(aggregation, "", "  ")
+		fmt.Println(string(jsonBytes))
+		return
+	}
+
+	printer := printers.NewTablePrinter(printers.PrintOptions{NoHeaders: noHeaders})
+
+	table := &v1.Table{
+		ColumnDefinitions: []v1.TableColumnDefinition{
+			{Name: "KEY", Type: "string"},
+		},
+	}
+	for _, cluster := range aggregation.Clusters {
+		table.ColumnDefinitions = append(table.ColumnDefinitions, v1.TableColumnDefinition{Name: strings.ToUpper(cluster), Type: "integer"})
+	}
+	table.ColumnDefinitions = append(table.ColumnDefinitions, v1.TableColumnDefinition{Name: "TOTAL", Type: "integer"})
+
+	for _, row := range aggregation.Rows {
+		cells := []interface{}{row.Key}
+		for _, cluster := range aggregation.Clusters {
+			cells = append(cells, row.Counts[cluster])
+		}
+		cells = append(cells, row.Total)
+		table.Rows = append(table.Rows, v1.TableRow{Cells: cells})
+	}
+
+	cells := []interface{}{"TOTAL"}
+	for _, cluster := range aggregation.Clusters {
+		cells = append(cells, aggregation.Totals[cluster])
+	}
+	cells = append(cells, aggregation.Total)
+	table.Rows = append(table.Rows, v1.TableRow{Cells: cells})
+
+	err := printer.PrintObj(table, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error printing table: %v\n", err)
+		os.Exit(1)
+	}
+}
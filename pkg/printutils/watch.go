@@ -0,0 +1,119 @@
+package printutils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+type flushWriter interface {
+	io.Writer
+	Flush() error
+}
+
+// ResourceEventPrinter prints watch events one row at a time, flushing after
+// each event so they show up as soon as they are received
+type ResourceEventPrinter struct {
+	out     io.Writer
+	writer  flushWriter
+	printer printers.ResourcePrinter
+	output  string
+}
+
+// NewResourceEventPrinter creates a streaming printer for the given output
+// format (default, wide or json)
+func NewResourceEventPrinter(out io.Writer, output string, noHeaders bool) *ResourceEventPrinter {
+	return &ResourceEventPrinter{
+		out:     out,
+		writer:  printers.GetNewTabWriter(out),
+		printer: printers.NewTablePrinter(printers.PrintOptions{NoHeaders: noHeaders}),
+		output:  output,
+	}
+}
+
+// Print writes a single event. Headers are only printed for the first row.
+func (p *ResourceEventPrinter) Print(event data.ResourceEvent) {
+	if p.output == "json" {
+		jsonBytes, err := json.Marshal(event)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding event: %v\n", err)
+			return
+		}
+		fmt.Fprintln(p.out, string(jsonBytes))
+		return
+	}
+
+	wide := p.output == "wide"
+
+	table := &v1.Table{
+		ColumnDefinitions: []v1.TableColumnDefinition{
+			{Name: "AWS PROFILE", Type: "string"},
+			{Name: "AWS REGION", Type: "string"},
+			{Name: "CLUSTER NAME", Type: "string"},
+			{Name: "EVENT", Type: "string"},
+			{Name: "NAMESPACE", Type: "string"},
+			{Name: "KIND", Type: "string"},
+			{Name: "NAME", Type: "string"},
+			{Name: "STATUS", Type: "string"},
+			{Name: "AGE", Type: "string"},
+		},
+	}
+	if wide {
+		table.ColumnDefinitions = append(table.ColumnDefinitions, v1.TableColumnDefinition{Name: "ADDITIONAL INFO", Type: "string"})
+	}
+
+	result := event.Result
+	namespace := result.Namespace
+	if namespace == "" {
+		namespace = "-"
+	}
+	name := result.Name
+	if name == "" {
+		name = "-"
+	}
+	kind := result.Kind
+	if kind == "" {
+		kind = "-"
+	}
+	status := result.Status
+	if status == "" {
+		status = "-"
+	}
+	age := extractAge(result.Data)
+	additionalInfo := "-"
+
+	if result.Error != "" {
+		status = fmt.Sprintf("ERROR: %s", result.Error)
+		age = "-"
+	} else if wide {
+		additionalInfo = extractAdditionalInfo(result.Data, result.Kind)
+	}
+
+	cells := []interface{}{
+		result.Profile,
+		result.Region,
+		result.ClusterName,
+		event.Type,
+		namespace,
+		kind,
+		name,
+		status,
+		age,
+	}
+	if wide {
+		cells = append(cells, additionalInfo)
+	}
+	table.Rows = append(table.Rows, v1.TableRow{Cells: cells})
+
+	err := p.printer.PrintObj(table, p.writer)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error printing table: %v\n", err)
+		os.Exit(1)
+	}
+	p.writer.Flush()
+}
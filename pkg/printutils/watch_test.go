@@ -0,0 +1,45 @@
+package printutils
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jordiprats/kubectl-eks/pkg/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceEventPrinter_Table(t *testing.T) {
+	var out bytes.Buffer
+	printer := NewResourceEventPrinter(&out, "", false)
+
+	printer.Print(data.ResourceEvent{
+		Type:   "ADDED",
+		Result: data.ResourceResult{Profile: "p", Region: "r", ClusterName: "c1", Namespace: "ns", Kind: "Pod", Name: "api-1", Status: "Running"},
+	})
+	printer.Print(data.ResourceEvent{
+		Type:   "ERROR",
+		Result: data.ResourceResult{Profile: "p", Region: "r", ClusterName: "c2", Error: "watch failed"},
+	})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3, "headers are printed once")
+	assert.True(t, strings.HasPrefix(lines[0], "AWS PROFILE"))
+	assert.Contains(t, lines[1], "ADDED")
+	assert.Contains(t, lines[1], "api-1")
+	assert.Contains(t, lines[2], "ERROR: watch failed")
+}
+
+func TestResourceEventPrinter_JSON(t *testing.T) {
+	var out bytes.Buffer
+	printer := NewResourceEventPrinter(&out, "json", false)
+
+	printer.Print(data.ResourceEvent{Type: "DELETED", Result: data.ResourceResult{ClusterName: "c1", Name: "api-1"}})
+	printer.Print(data.ResourceEvent{Type: "ADDED", Result: data.ResourceResult{ClusterName: "c1", Name: "api-2"}})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"Type":"DELETED"`)
+	assert.Contains(t, lines[1], `"Name":"api-2"`)
+}
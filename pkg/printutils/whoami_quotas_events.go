@@ -2,6 +2,7 @@ package printutils
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"sort"
 	"strings"
@@ -133,8 +134,25 @@ func PrintEvents(noHeaders bool, events ...data.EventInfo) {
 
 	printer := printers.NewTablePrinter(printers.PrintOptions{NoHeaders: noHeaders})
 
-	table := &v1.Table{
+	table := newEventsTable()
+	for _, event := range events {
+		table.Rows = append(table.Rows, eventRow(event))
+	}
+
+	err := printer.PrintObj(table, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error printing table: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func newEventsTable() *v1.Table {
+	return &v1.Table{
 		ColumnDefinitions: []v1.TableColumnDefinition{
+			{Name: "AWS PROFILE", Type: "string"},
+			{Name: "AWS REGION", Type: "string"},
+			{Name: "CLUSTER NAME", Type: "string"},
+			{Name: "NAMESPACE", Type: "string"},
 			{Name: "LAST SEEN", Type: "string"},
 			{Name: "TYPE", Type: "string"},
 			{Name: "REASON", Type: "string"},
@@ -143,31 +161,62 @@ func PrintEvents(noHeaders bool, events ...data.EventInfo) {
 			{Name: "COUNT", Type: "number"},
 		},
 	}
+}
 
-	for _, event := range events {
-		humanAge := duration.ShortHumanDuration(time.Since(event.LastSeen))
+func eventRow(event data.EventInfo) v1.TableRow {
+	humanAge := duration.ShortHumanDuration(time.Since(event.LastSeen))
 
-		// Truncate message if too long
-		message := event.Message
-		if len(message) > 80 {
-			message = message[:77] + "..."
-		}
+	namespace := event.Namespace
+	if namespace == "" {
+		namespace = "-"
+	}
 
-		table.Rows = append(table.Rows, v1.TableRow{
-			Cells: []interface{}{
-				humanAge,
-				event.Type,
-				event.Reason,
-				event.Object,
-				message,
-				event.Count,
-			},
-		})
+	// Truncate message if too long
+	message := event.Message
+	if len(message) > 80 {
+		message = message[:77] + "..."
 	}
 
-	err := printer.PrintObj(table, os.Stdout)
+	return v1.TableRow{
+		Cells: []interface{}{
+			event.Profile,
+			event.Region,
+			event.ClusterName,
+			namespace,
+			humanAge,
+			event.Type,
+			event.Reason,
+			event.Object,
+			message,
+			event.Count,
+		},
+	}
+}
+
+// EventStreamPrinter prints Kubernetes events one row at a time as they are
+// received. Headers are only printed for the first row.
+type EventStreamPrinter struct {
+	writer  flushWriter
+	printer printers.ResourcePrinter
+}
+
+// NewEventStreamPrinter creates a streaming printer for Kubernetes events
+func NewEventStreamPrinter(out io.Writer, noHeaders bool) *EventStreamPrinter {
+	return &EventStreamPrinter{
+		writer:  printers.GetNewTabWriter(out),
+		printer: printers.NewTablePrinter(printers.PrintOptions{NoHeaders: noHeaders}),
+	}
+}
+
+// Print writes a single event
+func (p *EventStreamPrinter) Print(event data.EventInfo) {
+	table := newEventsTable()
+	table.Rows = append(table.Rows, eventRow(event))
+
+	err := p.printer.PrintObj(table, p.writer)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error printing table: %v\n", err)
 		os.Exit(1)
 	}
+	p.writer.Flush()
 }
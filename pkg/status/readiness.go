@@ -0,0 +1,107 @@
+package status
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// readyConditionTypes are the positive conditions checked, in order, to
+// decide whether a resource is ready
+var readyConditionTypes = []string{"Ready", "Available", "Healthy", "Established"}
+
+// unhealthyPhases are status.phase / status.state values that mean the
+// resource is broken or not there yet
+var unhealthyPhases = map[string]bool{
+	"failed":      true,
+	"error":       true,
+	"degraded":    true,
+	"pending":     true,
+	"progressing": true,
+	"unknown":     true,
+	"lost":        true,
+}
+
+// EvaluateReadiness decides whether an arbitrary resource is ready using the
+// conventions most controllers follow:
+//   - resources being deleted are not ready
+//   - status.observedGeneration must have caught up with metadata.generation
+//   - a Stalled=True or Reconciling=True condition means not ready
+//   - the first of the Ready, Available, Healthy or Established conditions
+//     decides, and it must have observed the current generation too
+//   - otherwise status.phase / status.state is used, and resources without a
+//     known failing phase are considered ready
+//
+// The message explains the outcome.
+func EvaluateReadiness(obj map[string]interface{}) (bool, string) {
+	if _, found, _ := unstructured.NestedString(obj, "metadata", "deletionTimestamp"); found {
+		return false, "Terminating"
+	}
+
+	generation, hasGeneration, _ := unstructured.NestedInt64(obj, "metadata", "generation")
+	if observed, found, _ := unstructured.NestedInt64(obj, "status", "observedGeneration"); found && hasGeneration && observed < generation {
+		return false, fmt.Sprintf("Generation %d not observed yet (observed %d)", generation, observed)
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj, "status", "conditions")
+	byType := make(map[string]map[string]interface{})
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condType, ok := condition["type"].(string); ok {
+			byType[condType] = condition
+		}
+	}
+
+	for _, negative := range []string{"Stalled", "Reconciling"} {
+		if condition, ok := byType[negative]; ok && condition["status"] == "True" {
+			return false, conditionMessage(negative, condition)
+		}
+	}
+
+	for _, condType := range readyConditionTypes {
+		condition, ok := byType[condType]
+		if !ok {
+			continue
+		}
+
+		if observed, ok := condition["observedGeneration"].(int64); ok && hasGeneration && observed < generation {
+			return false, fmt.Sprintf("%s condition not updated for generation %d yet", condType, generation)
+		}
+
+		switch condition["status"] {
+		case "True":
+			return true, condType
+		case "False":
+			return false, conditionMessage("Not"+condType, condition)
+		default:
+			return false, conditionMessage(condType+" unknown", condition)
+		}
+	}
+
+	for _, field := range []string{"phase", "state"} {
+		if value, found, _ := unstructured.NestedString(obj, "status", field); found && value != "" {
+			if unhealthyPhases[strings.ToLower(value)] {
+				return false, value
+			}
+			return true, value
+		}
+	}
+
+	return true, extractGenericStatus(obj)
+}
+
+// conditionMessage formats a condition as "<prefix>: <reason>: <message>"
+func conditionMessage(prefix string, condition map[string]interface{}) string {
+	parts := []string{prefix}
+	if reason, ok := condition["reason"].(string); ok && reason != "" {
+		parts = append(parts, reason)
+	}
+	if message, ok := condition["message"].(string); ok && message != "" {
+		parts = append(parts, message)
+	}
+	return strings.Join(parts, ": ")
+}
@@ -420,3 +420,110 @@ func TestExtractStatus_PDB(t *testing.T) {
 		})
 	}
 }
+
+func TestEvaluateReadiness(t *testing.T) {
+	condition := func(condType, status, reason string) map[string]interface{} {
+		return map[string]interface{}{"type": condType, "status": status, "reason": reason}
+	}
+
+	tests := []struct {
+		name            string
+		obj             map[string]interface{}
+		expectedHealthy bool
+		expectedMessage string
+	}{
+		{
+			name: "ready condition true",
+			obj: map[string]interface{}{
+				"metadata": map[string]interface{}{"generation": int64(2)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(2),
+					"conditions":         []interface{}{condition("Ready", "True", "Ready")},
+				},
+			},
+			expectedHealthy: true,
+			expectedMessage: "Ready",
+		},
+		{
+			name: "ready condition false",
+			obj: map[string]interface{}{"status": map[string]interface{}{
+				"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "False", "reason": "SecretSyncedError", "message": "could not get secret"}},
+			}},
+			expectedHealthy: false,
+			expectedMessage: "NotReady: SecretSyncedError: could not get secret",
+		},
+		{
+			name: "generation not observed",
+			obj: map[string]interface{}{
+				"metadata": map[string]interface{}{"generation": int64(3)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(2),
+					"conditions":         []interface{}{condition("Ready", "True", "Ready")},
+				},
+			},
+			expectedHealthy: false,
+			expectedMessage: "Generation 3 not observed yet (observed 2)",
+		},
+		{
+			name: "stale ready condition",
+			obj: map[string]interface{}{
+				"metadata": map[string]interface{}{"generation": int64(3)},
+				"status": map[string]interface{}{
+					"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "True", "observedGeneration": int64(2)}},
+				},
+			},
+			expectedHealthy: false,
+			expectedMessage: "Ready condition not updated for generation 3 yet",
+		},
+		{
+			name: "stalled wins over ready",
+			obj: map[string]interface{}{"status": map[string]interface{}{
+				"conditions": []interface{}{condition("Ready", "True", ""), condition("Stalled", "True", "InvalidSpec")},
+			}},
+			expectedHealthy: false,
+			expectedMessage: "Stalled: InvalidSpec",
+		},
+		{
+			name: "available condition unknown",
+			obj: map[string]interface{}{"status": map[string]interface{}{
+				"conditions": []interface{}{condition("Available", "Unknown", "")},
+			}},
+			expectedHealthy: false,
+			expectedMessage: "Available unknown",
+		},
+		{
+			name: "terminating",
+			obj: map[string]interface{}{
+				"metadata": map[string]interface{}{"deletionTimestamp": "2024-01-01T00:00:00Z"},
+			},
+			expectedHealthy: false,
+			expectedMessage: "Terminating",
+		},
+		{
+			name:            "degraded phase",
+			obj:             map[string]interface{}{"status": map[string]interface{}{"phase": "Degraded"}},
+			expectedHealthy: false,
+			expectedMessage: "Degraded",
+		},
+		{
+			name:            "healthy phase",
+			obj:             map[string]interface{}{"status": map[string]interface{}{"phase": "Healthy"}},
+			expectedHealthy: true,
+			expectedMessage: "Healthy",
+		},
+		{
+			name:            "no status",
+			obj:             map[string]interface{}{},
+			expectedHealthy: true,
+			expectedMessage: "-",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			healthy, message := EvaluateReadiness(tt.obj)
+			assert.Equal(t, tt.expectedHealthy, healthy)
+			assert.Equal(t, tt.expectedMessage, message)
+		})
+	}
+}